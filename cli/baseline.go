@@ -0,0 +1,52 @@
+// cli/baseline.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oferchen/hclalign/internal/baseline"
+	"github.com/oferchen/hclalign/internal/diag"
+	"github.com/oferchen/hclalign/internal/engine"
+)
+
+func NewBaselineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Manage the baseline of known misordered blocks",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "generate [target file or directory]",
+		Short: "Record every currently misordered block in the baseline file",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+				return &ExitCodeError{Err: err, Code: 2}
+			}
+			return nil
+		},
+		RunE:         GenerateBaselineE,
+		SilenceUsage: true,
+	})
+	return cmd
+}
+
+func GenerateBaselineE(cmd *cobra.Command, args []string) error {
+	cfg, err := parseConfig(cmd, args)
+	if err != nil {
+		return err
+	}
+	if cfg.Stdin {
+		return &ExitCodeError{Err: fmt.Errorf("baseline generate cannot read from --stdin"), Code: 2}
+	}
+	path := cfg.Baseline
+	if path == "" {
+		path = baseline.DefaultPath
+	}
+	b, err := engine.GenerateBaseline(diag.WithColor(cmd.Context(), colorEnabled(cmd.OutOrStdout())), cfg, path)
+	if err != nil {
+		return &ExitCodeError{Err: err, Code: 3}
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "recorded %d misordered blocks in %s\n", len(b.Entries), path)
+	return err
+}
@@ -2,11 +2,15 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/diag"
 	"github.com/oferchen/hclalign/internal/engine"
 )
 
@@ -23,7 +27,27 @@ func RunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	changed, err := engine.Process(cmd.Context(), cfg)
+	ctx, prof, err := startProfiling(diag.WithColor(cmd.Context(), colorEnabled(cmd.OutOrStdout())), cfg)
+	if err != nil {
+		return &ExitCodeError{Err: err, Code: 3}
+	}
+	var report *diag.Report
+	if cfg.Report != "" {
+		report = diag.NewReport()
+		ctx = diag.WithReport(ctx, report)
+	}
+	changed, err := engine.Process(ctx, cfg)
+	if report != nil {
+		if reportErr := report.WriteFile(cfg.Report, cfg.ReportFormat); reportErr != nil && err == nil {
+			err = reportErr
+		}
+	}
+	if stopErr := prof.stop(); stopErr != nil && err == nil {
+		err = stopErr
+	}
+	if errors.Is(err, engine.ErrInterrupted) {
+		return &ExitCodeError{Err: err, Code: 130}
+	}
 	if err != nil {
 		return &ExitCodeError{Err: err, Code: 3}
 	}
@@ -34,3 +58,15 @@ func RunE(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func colorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
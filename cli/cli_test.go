@@ -3,6 +3,8 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -11,10 +13,24 @@ import (
 	"testing"
 
 	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/diag"
+	"github.com/oferchen/hclalign/internal/engine"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
+const commentMovingTerraform = `#!/bin/sh
+move() { awk '/^  # networking$/ { held = $0; next } { print } held != "" { print held; held = "" }'; }
+for a; do last=$a; done
+if [ "$last" = "-" ]; then
+  move
+  exit 0
+fi
+for f in "$last"/*.tf; do
+  move < "$f" > "$f.tmp" && mv "$f.tmp" "$f"
+done
+`
+
 func newRootCmd(exclusive bool) *cobra.Command { return newTestRootCmd(exclusive) }
 
 func newTestRootCmd(exclusive bool) *cobra.Command {
@@ -24,26 +40,61 @@ func newTestRootCmd(exclusive bool) *cobra.Command {
 		RunE:         RunE,
 		SilenceUsage: true,
 	}
-	cmd.Flags().Bool("write", false, "write result to files")
-	cmd.Flags().Bool("check", false, "check if files are formatted")
-	cmd.Flags().Bool("diff", false, "print the diff of required changes")
-	cmd.Flags().Bool("stdin", false, "read from STDIN")
-	cmd.Flags().Bool("stdout", false, "write result to STDOUT")
-	cmd.Flags().StringSlice("include", config.DefaultInclude, "glob patterns to include")
-	cmd.Flags().StringSlice("exclude", config.DefaultExclude, "glob patterns to exclude")
-	cmd.Flags().StringSlice("order", config.CanonicalOrder, "order of variable block fields")
-	cmd.Flags().Bool("follow-symlinks", false, "follow symbolic links when traversing directories")
-	cmd.Flags().String("providers-schema", "", "path to providers schema file")
-	cmd.Flags().Bool("use-terraform-schema", false, "use terraform schema for providers")
-	cmd.Flags().String("schema-cache", "", "directory for provider schema cache")
-	cmd.Flags().Bool("no-schema-cache", false, "disable provider schema caching")
-	cmd.Flags().Int("concurrency", runtime.GOMAXPROCS(0), "maximum concurrency")
-	cmd.Flags().StringSlice("types", []string{"variable"}, "comma-separated list of block types to align")
-	cmd.Flags().Bool("all", false, "align all block types")
+	cmd.PersistentFlags().Bool("write", false, "write result to files")
+	cmd.PersistentFlags().String("config", "", "config file; by default .hclalign.hcl is searched from the target directory upward")
+	cmd.PersistentFlags().Bool("check", false, "check if files are formatted")
+	cmd.PersistentFlags().Bool("diff", false, "print the diff of required changes")
+	cmd.PersistentFlags().Bool("stdin", false, "read from STDIN")
+	cmd.PersistentFlags().Bool("stdout", false, "write result to STDOUT")
+	cmd.PersistentFlags().StringSlice("include", config.DefaultInclude, "glob patterns to include")
+	cmd.PersistentFlags().StringSlice("exclude", config.DefaultExclude, "glob patterns to exclude")
+	cmd.PersistentFlags().StringSlice("order", config.CanonicalOrder, "order of variable block fields")
+	cmd.PersistentFlags().Bool("follow-symlinks", false, "follow symbolic links when traversing directories")
+	cmd.PersistentFlags().String("providers-schema", "", "path to providers schema file")
+	cmd.PersistentFlags().Bool("use-terraform-schema", false, "use terraform schema for providers")
+	cmd.PersistentFlags().String("schema-cache", "", "directory for provider schema cache")
+	cmd.PersistentFlags().Bool("no-schema-cache", false, "disable provider schema caching")
+	cmd.PersistentFlags().Int("concurrency", runtime.GOMAXPROCS(0), "maximum concurrency")
+	cmd.PersistentFlags().Int("fmt-workers", runtime.NumCPU(), "maximum concurrent terraform fmt subprocesses")
+	cmd.PersistentFlags().String("fmt-strategy", "auto", "formatter run before and after alignment: auto, binary, go or none")
+	cmd.PersistentFlags().Bool("no-fmt", false, "align only: move reordered attribute and block spans and leave every other byte untouched")
+	cmd.PersistentFlags().String("tool", "auto", "binary used for fmt, version and providers schema: terraform, tofu or auto")
+	cmd.PersistentFlags().String("terraform-bin", "", "path or name of the terraform or tofu binary to run")
+	cmd.PersistentFlags().Bool("heredoc-indent", false, "rewrite heredocs to the indented <<- form, re-indented to the enclosing block")
+	cmd.PersistentFlags().String("heredoc-marker", "", "rename every heredoc marker to this identifier (e.g. EOT)")
+	cmd.PersistentFlags().Bool("heredoc-inline", false, "convert short single-line heredocs to quoted strings")
+	cmd.PersistentFlags().Int("max-blank-lines", 0, "collapse runs of blank lines to at most this many (0 keeps them)")
+	cmd.PersistentFlags().Bool("separate-blocks", false, "put exactly one blank line between top-level blocks")
+	cmd.PersistentFlags().Bool("sort-blocks", false, "sort top-level blocks by kind, then by label within each kind")
+	cmd.PersistentFlags().StringSlice("block-order", config.DefaultBlockOrder, "top-level block kind order used by --sort-blocks; unlisted kinds go last")
+	cmd.PersistentFlags().StringSlice("block-sort", nil, "per-kind sort used by --sort-blocks as kind=mode, mode one of natural, required-first (variable only) or as-is")
+	cmd.PersistentFlags().Bool("restructure", false, "move blocks between files of the same directory following --restructure-rule and merge duplicate terraform blocks")
+	cmd.PersistentFlags().StringSlice("restructure-rule", config.DefaultRestructure, "restructure rule as kind=file, such as variable=variables.tf")
+	cmd.PersistentFlags().Bool("sort-object-keys", false, "sort the keys of object constructors in attribute values")
+	cmd.PersistentFlags().StringSlice("sort-object-keys-in", nil, "limit --sort-object-keys to attributes matching these names or globs, such as tags or *_tags")
+	cmd.PersistentFlags().Bool("sort-lists", false, "sort and de-duplicate list literals of plain values in the attributes named by --sort-lists-in")
+	cmd.PersistentFlags().StringSlice("sort-lists-in", config.DefaultListAttrs, "attribute names or globs whose lists --sort-lists normalizes")
+	cmd.PersistentFlags().Bool("topo-sort", false, "order top-level blocks so that blocks come after the var, local, data, resource and module blocks they reference")
+	cmd.PersistentFlags().Bool("group-separators", false, "separate meta-argument, required, optional, computed, unknown and nested-block groups with one blank line")
+	cmd.PersistentFlags().Bool("trim-braces", false, "remove blank lines right after an opening brace and right before a closing brace")
+	cmd.PersistentFlags().StringSlice("types", []string{"variable"}, "comma-separated list of block types to align")
+	cmd.PersistentFlags().Bool("all", false, "align all block types")
+	cmd.PersistentFlags().StringSlice("only", nil, "only align blocks whose address matches one of these globs (e.g. resource.aws_iam_policy.*)")
+	cmd.PersistentFlags().StringSlice("skip", nil, "skip blocks whose address matches one of these globs (e.g. module.legacy_*)")
+	cmd.PersistentFlags().Bool("no-verify", false, "skip the semantic equivalence check before writing files")
+	cmd.PersistentFlags().Bool("verify-idempotent", false, "run the pipeline twice and report files whose second pass differs")
+	cmd.PersistentFlags().Bool("strict-comments", false, "fail when a comment changes its owning block or neighbouring attribute")
+	cmd.PersistentFlags().String("baseline", "", "baseline file of known misordered blocks; check and diff fail only on new ones")
+	cmd.PersistentFlags().String("report", "", "write parse diagnostics and comment audit findings to file")
+	cmd.PersistentFlags().String("report-format", "json", "format of the --report file: json or sarif")
+	cmd.PersistentFlags().String("trace", "", "write a Chrome trace-event JSON file covering every processing phase")
+	cmd.PersistentFlags().String("cpuprofile", "", "write a CPU profile to file")
+	cmd.PersistentFlags().String("memprofile", "", "write a heap profile to file")
 	cmd.MarkFlagsMutuallyExclusive("types", "all")
 	if exclusive {
 		cmd.MarkFlagsMutuallyExclusive("write", "check", "diff")
 	}
+	cmd.AddCommand(NewBaselineCmd())
 	return cmd
 }
 
@@ -95,6 +146,55 @@ func TestRunEMutuallyExclusiveFlags(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestRunEInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.tf")
+	require.NoError(t, os.WriteFile(path, []byte("variable \"a\" {\n  type = string\n}\n"), 0o644))
+
+	stop := make(chan struct{})
+	close(stop)
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{path})
+	_, err := cmd.ExecuteContextC(engine.WithInterrupt(context.Background(), stop))
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 130, exitErr.Code)
+	require.ErrorContains(t, err, "not started:\n  "+path)
+}
+
+func TestBaselineGenerateCommand(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.tf")
+	require.NoError(t, os.WriteFile(path, []byte("variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n"), 0o644))
+	basePath := filepath.Join(dir, "baseline.json")
+
+	cmd := newRootCmd(true)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"baseline", "generate", dir, "--baseline", basePath})
+	_, err := cmd.ExecuteC()
+	require.NoError(t, err)
+	require.Equal(t, "recorded 1 misordered blocks in "+basePath+"\n", out.String())
+
+	cmd = newRootCmd(true)
+	cmd.SetArgs([]string{dir, "--check", "--baseline", basePath})
+	_, err = cmd.ExecuteC()
+	require.NoError(t, err)
+
+	cmd = newRootCmd(true)
+	cmd.SetArgs([]string{"baseline", "generate", "--stdin", "--stdout"})
+	_, err = cmd.ExecuteC()
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+
+	cmd = newRootCmd(true)
+	cmd.SetArgs([]string{"baseline", "generate", "a", "b"})
+	_, err = cmd.ExecuteC()
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
 func TestRunEFormattingNeeded(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.tf")
@@ -125,6 +225,18 @@ func TestRunERuntimeError(t *testing.T) {
 	require.Equal(t, 3, exitErr.Code)
 }
 
+func TestColorEnabled(t *testing.T) {
+	require.False(t, colorEnabled(&bytes.Buffer{}))
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+	require.False(t, colorEnabled(f))
+
+	t.Setenv("NO_COLOR", "1")
+	require.False(t, colorEnabled(os.Stdout))
+}
+
 func TestRunEStdinRuntimeError(t *testing.T) {
 	cmd := newRootCmd(true)
 	cmd.SetArgs([]string{"--stdin", "--stdout"})
@@ -146,6 +258,47 @@ func TestRunEStdinRuntimeError(t *testing.T) {
 	require.Equal(t, 3, exitErr.Code)
 }
 
+func TestRunEReport(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.tf"), []byte("variable \"a\" {\n  # networking\n  type        = string\n  description = \"d\"\n}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.tf"), []byte("variable \"b\" {\n"), 0o644))
+	report := filepath.Join(t.TempDir(), "report.json")
+	terraform := filepath.Join(t.TempDir(), "terraform")
+	require.NoError(t, os.WriteFile(terraform, []byte(commentMovingTerraform), 0o755))
+
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{dir, "--check", "--report", report, "--fmt-strategy", "binary", "--terraform-bin", terraform})
+	_, err := cmd.ExecuteC()
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 3, exitErr.Code)
+
+	data, err := os.ReadFile(report)
+	require.NoError(t, err)
+	var doc struct {
+		Diagnostics []diag.Diagnostic `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Len(t, doc.Diagnostics, 2)
+	require.Equal(t, "comment-moved", doc.Diagnostics[0].Code)
+	require.Equal(t, "warning", doc.Diagnostics[0].Severity)
+	require.Equal(t, filepath.Join(dir, "a.tf"), doc.Diagnostics[0].Range.Filename)
+	require.Equal(t, diag.CodeParse, doc.Diagnostics[1].Code)
+	require.Equal(t, "error", doc.Diagnostics[1].Severity)
+	require.Equal(t, "Unclosed configuration block", doc.Diagnostics[1].Summary)
+	require.Equal(t, diag.Pos{Line: 1, Column: 14, Byte: 13}, doc.Diagnostics[1].Range.Start)
+}
+
+func TestRunEInvalidReportFormat(t *testing.T) {
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{"--stdin", "--stdout", "--report", "out.xml", "--report-format", "xml"})
+	_, err := cmd.ExecuteC()
+	require.ErrorContains(t, err, `unknown report format "xml"`)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
 func TestRunEInvalidConcurrency(t *testing.T) {
 	cmd := newRootCmd(true)
 	cmd.SetArgs([]string{"--stdin", "--stdout", "--concurrency", "0"})
@@ -156,6 +309,66 @@ func TestRunEInvalidConcurrency(t *testing.T) {
 	require.Equal(t, 2, exitErr.Code)
 }
 
+func TestRunEInvalidFmtStrategy(t *testing.T) {
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{"--stdin", "--stdout", "--fmt-strategy", "gofmt"})
+	_, err := cmd.ExecuteC()
+	require.ErrorContains(t, err, `unknown fmt strategy "gofmt"`)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
+func TestRunENoFmtWithFmtStrategy(t *testing.T) {
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{"--stdin", "--stdout", "--no-fmt", "--fmt-strategy", "go"})
+	_, err := cmd.ExecuteC()
+	require.ErrorContains(t, err, `no-fmt cannot be combined with fmt strategy "go"`)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
+func TestRunEInvalidHeredocMarker(t *testing.T) {
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{"--stdin", "--stdout", "--heredoc-marker", "<<EOT"})
+	_, err := cmd.ExecuteC()
+	require.ErrorContains(t, err, `invalid heredoc marker "<<EOT"`)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
+func TestRunEInvalidBlockSort(t *testing.T) {
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{"--stdin", "--stdout", "--sort-blocks", "--block-order", "variable,variable"})
+	_, err := cmd.ExecuteC()
+	require.ErrorContains(t, err, `duplicate block kind "variable" in order`)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
+func TestRunEInvalidRestructureRule(t *testing.T) {
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{".", "--restructure", "--restructure-rule", "output=outputs.hcl"})
+	_, err := cmd.ExecuteC()
+	require.ErrorContains(t, err, `invalid restructure rule "output=outputs.hcl"`)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
+func TestRunEInvalidTool(t *testing.T) {
+	cmd := newRootCmd(true)
+	cmd.SetArgs([]string{"--stdin", "--stdout", "--tool", "opentofu"})
+	_, err := cmd.ExecuteC()
+	require.ErrorContains(t, err, `unknown tool "opentofu"`)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
 func TestRunEInvalidGlob(t *testing.T) {
 	tests := []struct {
 		name    string
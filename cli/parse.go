@@ -3,6 +3,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -15,6 +16,10 @@ func parseConfig(cmd *cobra.Command, args []string) (*config.Config, error) {
 		target = args[0]
 	}
 
+	if err := applyConfigFile(cmd, target); err != nil {
+		return nil, &ExitCodeError{Err: err, Code: 2}
+	}
+
 	var err error
 	writeMode := getBool(cmd, "write", &err)
 	checkMode := getBool(cmd, "check", &err)
@@ -30,8 +35,41 @@ func parseConfig(cmd *cobra.Command, args []string) (*config.Config, error) {
 	schemaCache := getString(cmd, "schema-cache", &err)
 	noSchemaCache := getBool(cmd, "no-schema-cache", &err)
 	concurrency := getInt(cmd, "concurrency", &err)
+	fmtWorkers := getInt(cmd, "fmt-workers", &err)
+	fmtStrategy := getString(cmd, "fmt-strategy", &err)
+	noFmt := getBool(cmd, "no-fmt", &err)
+	toolKind := getString(cmd, "tool", &err)
+	terraformBin := getString(cmd, "terraform-bin", &err)
+	heredocIndent := getBool(cmd, "heredoc-indent", &err)
+	heredocMarker := getString(cmd, "heredoc-marker", &err)
+	heredocInline := getBool(cmd, "heredoc-inline", &err)
+	maxBlankLines := getInt(cmd, "max-blank-lines", &err)
+	separateBlocks := getBool(cmd, "separate-blocks", &err)
+	trimBraces := getBool(cmd, "trim-braces", &err)
+	groupSeparators := getBool(cmd, "group-separators", &err)
+	sortBlocks := getBool(cmd, "sort-blocks", &err)
+	blockOrder := getStringSlice(cmd, "block-order", &err)
+	blockSortModes := getStringSlice(cmd, "block-sort", &err)
+	topoSort := getBool(cmd, "topo-sort", &err)
+	sortObjectKeys := getBool(cmd, "sort-object-keys", &err)
+	objectKeyAttrs := getStringSlice(cmd, "sort-object-keys-in", &err)
+	sortLists := getBool(cmd, "sort-lists", &err)
+	listAttrs := getStringSlice(cmd, "sort-lists-in", &err)
+	restructure := getBool(cmd, "restructure", &err)
+	restructureRules := getStringSlice(cmd, "restructure-rule", &err)
 	types := getStringSlice(cmd, "types", &err)
 	all := getBool(cmd, "all", &err)
+	only := getStringSlice(cmd, "only", &err)
+	skip := getStringSlice(cmd, "skip", &err)
+	noVerify := getBool(cmd, "no-verify", &err)
+	verifyIdempotent := getBool(cmd, "verify-idempotent", &err)
+	strictComments := getBool(cmd, "strict-comments", &err)
+	baselinePath := getString(cmd, "baseline", &err)
+	reportPath := getString(cmd, "report", &err)
+	reportFormat := getString(cmd, "report-format", &err)
+	tracePath := getString(cmd, "trace", &err)
+	cpuProfile := getString(cmd, "cpuprofile", &err)
+	memProfile := getString(cmd, "memprofile", &err)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +90,9 @@ func parseConfig(cmd *cobra.Command, args []string) (*config.Config, error) {
 	if stdin && target != "" {
 		return nil, &ExitCodeError{Err: fmt.Errorf("cannot specify target when --stdin is used"), Code: 2}
 	}
+	if stdin && baselinePath != "" {
+		return nil, &ExitCodeError{Err: fmt.Errorf("--baseline cannot be used with --stdin"), Code: 2}
+	}
 	if stdin && !stdout {
 		return nil, &ExitCodeError{Err: fmt.Errorf("--stdout is required when --stdin is used"), Code: 2}
 	}
@@ -86,8 +127,41 @@ func parseConfig(cmd *cobra.Command, args []string) (*config.Config, error) {
 		SchemaCache:        schemaCache,
 		NoSchemaCache:      noSchemaCache,
 		Concurrency:        concurrency,
+		FmtWorkers:         fmtWorkers,
+		FmtStrategy:        fmtStrategy,
+		NoFmt:              noFmt,
+		Tool:               toolKind,
+		TerraformBin:       terraformBin,
+		HeredocIndent:      heredocIndent,
+		HeredocMarker:      heredocMarker,
+		HeredocInline:      heredocInline,
+		MaxBlankLines:      maxBlankLines,
+		SeparateBlocks:     separateBlocks,
+		TrimBraces:         trimBraces,
+		GroupSeparators:    groupSeparators,
+		SortBlocks:         sortBlocks,
+		BlockOrder:         blockOrder,
+		BlockSort:          blockSortModes,
+		TopoSort:           topoSort,
+		SortObjectKeys:     sortObjectKeys,
+		ObjectKeyAttrs:     objectKeyAttrs,
+		SortLists:          sortLists,
+		ListAttrs:          listAttrs,
+		Restructure:        restructure,
+		RestructureRules:   restructureRules,
 		Types:              cfgTypes,
+		Only:               only,
+		Skip:               skip,
 		FollowSymlinks:     followSymlinks,
+		NoVerify:           noVerify,
+		VerifyIdempotent:   verifyIdempotent,
+		StrictComments:     strictComments,
+		Baseline:           baselinePath,
+		Report:             reportPath,
+		ReportFormat:       reportFormat,
+		Trace:              tracePath,
+		CPUProfile:         cpuProfile,
+		MemProfile:         memProfile,
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -97,6 +171,50 @@ func parseConfig(cmd *cobra.Command, args []string) (*config.Config, error) {
 	return cfg, nil
 }
 
+var fileOverrides = map[string]string{"types": "all", "all": "types"}
+
+func applyConfigFile(cmd *cobra.Command, target string) error {
+	var err error
+	path := getString(cmd, "config", &err)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		if getBool(cmd, "stdin", &err) {
+			target = "."
+		}
+		if err != nil {
+			return err
+		}
+		if path, err = config.FindFile(target); err != nil || path == "" {
+			return err
+		}
+	}
+	file, err := config.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	flags := cmd.Flags()
+	for _, s := range file.Settings {
+		if flags.Changed(s.Flag) || flags.Changed(fileOverrides[s.Flag]) {
+			continue
+		}
+		f := flags.Lookup(s.Flag)
+		if f == nil {
+			return fmt.Errorf("%s: unsupported setting %q", file.Path, s.Flag)
+		}
+		if slice, ok := f.Value.(interface{ Replace([]string) error }); ok {
+			err = slice.Replace(s.Values)
+		} else {
+			err = f.Value.Set(s.Values[0])
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", file.Path, strings.ReplaceAll(s.Flag, "-", "_"), err)
+		}
+	}
+	return nil
+}
+
 func getBool(cmd *cobra.Command, name string, err *error) bool {
 	if *err != nil {
 		return false
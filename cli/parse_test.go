@@ -2,6 +2,8 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"testing"
@@ -37,6 +39,87 @@ func TestParseConfigSchemaCache(t *testing.T) {
 	require.True(t, cfg.NoSchemaCache)
 }
 
+func TestParseConfigFmtWorkers(t *testing.T) {
+	cmd := newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--fmt-workers", "3"}))
+	cfg, err := parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.Equal(t, 3, cfg.FmtWorkers)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--fmt-workers", "-1"}))
+	_, err = parseConfig(cmd, []string{"target"})
+	require.Error(t, err)
+}
+
+func TestParseConfigNoVerify(t *testing.T) {
+	cmd := newRootCmd(true)
+	cfg, err := parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.False(t, cfg.NoVerify)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--no-verify"}))
+	cfg, err = parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.True(t, cfg.NoVerify)
+	require.False(t, cfg.VerifyIdempotent)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--verify-idempotent"}))
+	cfg, err = parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.True(t, cfg.VerifyIdempotent)
+	require.False(t, cfg.StrictComments)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--strict-comments"}))
+	cfg, err = parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.True(t, cfg.StrictComments)
+}
+
+func TestParseConfigProfiling(t *testing.T) {
+	cmd := newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--trace", "t.json", "--cpuprofile", "cpu.out", "--memprofile", "mem.out"}))
+	cfg, err := parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.Equal(t, "t.json", cfg.Trace)
+	require.Equal(t, "cpu.out", cfg.CPUProfile)
+	require.Equal(t, "mem.out", cfg.MemProfile)
+}
+
+func TestParseConfigAddressFilters(t *testing.T) {
+	cmd := newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--only", "resource.aws_iam_policy.*", "--skip", "module.legacy_*,module.old"}))
+	cfg, err := parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"resource.aws_iam_policy.*"}, cfg.Only)
+	require.Equal(t, []string{"module.legacy_*", "module.old"}, cfg.Skip)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--only", "resource..x"}))
+	_, err = parseConfig(cmd, []string{"target"})
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
+func TestParseConfigBaseline(t *testing.T) {
+	cmd := newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--check", "--baseline", "base.json"}))
+	cfg, err := parseConfig(cmd, []string{"target"})
+	require.NoError(t, err)
+	require.Equal(t, "base.json", cfg.Baseline)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--stdin", "--stdout", "--baseline", "base.json"}))
+	_, err = parseConfig(cmd, nil)
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
 func TestParseConfigTargetWithStdin(t *testing.T) {
 	cmd := newRootCmd(true)
 	require.NoError(t, cmd.ParseFlags([]string{"--stdin", "--stdout"}))
@@ -115,3 +198,103 @@ func TestGetIntError(t *testing.T) {
 	_ = getInt(cmd, "missing", &err)
 	require.EqualError(t, err, "get flag missing: flag accessed but not defined: missing")
 }
+
+func TestParseConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte(`only         = ["resource.aws_iam_policy.*"]
+skip         = ["module.legacy_*"]
+all          = true
+sort_blocks  = true
+block_order  = ["variable", "output"]
+heredoc_marker = "EOT"
+`), 0o644))
+	target := filepath.Join(dir, "main.tf")
+
+	cmd := newRootCmd(true)
+	cfg, err := parseConfig(cmd, []string{target})
+	require.NoError(t, err)
+	require.Equal(t, []string{"resource.aws_iam_policy.*"}, cfg.Only)
+	require.Equal(t, []string{"module.legacy_*"}, cfg.Skip)
+	require.Nil(t, cfg.Types)
+	require.True(t, cfg.SortBlocks)
+	require.Equal(t, []string{"variable", "output"}, cfg.BlockOrder)
+	require.Equal(t, "EOT", cfg.HeredocMarker)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--only", "module.*", "--types", "module", "--heredoc-marker", "END"}))
+	cfg, err = parseConfig(cmd, []string{target})
+	require.NoError(t, err)
+	require.Equal(t, []string{"module.*"}, cfg.Only)
+	require.Equal(t, []string{"module.legacy_*"}, cfg.Skip)
+	require.Equal(t, []string{"module"}, cfg.Types)
+	require.Equal(t, "END", cfg.HeredocMarker)
+}
+
+func TestParseConfigExplicitFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.hcl")
+	require.NoError(t, os.WriteFile(path, []byte("skip = [\"data.*\"]\n"), 0o644))
+
+	cmd := newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--config", path}))
+	cfg, err := parseConfig(cmd, []string{t.TempDir()})
+	require.NoError(t, err)
+	require.Equal(t, []string{"data.*"}, cfg.Skip)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--config", filepath.Join(t.TempDir(), "missing.hcl")}))
+	_, err = parseConfig(cmd, []string{"target"})
+	var exitErr *ExitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 2, exitErr.Code)
+}
+
+func TestParseConfigFileInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{name: "unknown key", src: "stdin = true\n", msg: `unknown setting "stdin"`},
+		{name: "bad value", src: "concurrency = \"many\"\n", msg: "concurrency: "},
+		{name: "validation", src: "concurrency = 0\n", msg: "concurrency must be at least 1"},
+		{name: "bad filter", src: "only = [\"resource.[\"]\n", msg: "resource.["},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte(tc.src), 0o644))
+			cmd := newRootCmd(true)
+			_, err := parseConfig(cmd, []string{dir})
+			var exitErr *ExitCodeError
+			require.ErrorAs(t, err, &exitErr)
+			require.Equal(t, 2, exitErr.Code)
+			require.ErrorContains(t, err, tc.msg)
+		})
+	}
+}
+
+func TestParseConfigFileFmtStrategy(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte("fmt_strategy = \"go\"\n"), 0o644))
+
+	cmd := newRootCmd(true)
+	cfg, err := parseConfig(cmd, []string{dir})
+	require.NoError(t, err)
+	require.Equal(t, "go", cfg.FmtStrategy)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--fmt-strategy", "none"}))
+	cfg, err = parseConfig(cmd, []string{dir})
+	require.NoError(t, err)
+	require.Equal(t, "none", cfg.FmtStrategy)
+
+	cmd = newRootCmd(true)
+	require.NoError(t, cmd.ParseFlags([]string{"--no-fmt"}))
+	_, err = parseConfig(cmd, []string{dir})
+	require.ErrorContains(t, err, `no-fmt cannot be combined with fmt strategy "go"`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte("fmt_strategy = \"gofmt\"\n"), 0o644))
+	cmd = newRootCmd(true)
+	_, err = parseConfig(cmd, []string{dir})
+	require.ErrorContains(t, err, `unknown fmt strategy "gofmt"`)
+}
@@ -0,0 +1,74 @@
+// cli/profile.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/trace"
+)
+
+type profiler struct {
+	cfg    *config.Config
+	tracer *trace.Tracer
+	cpu    *os.File
+}
+
+func startProfiling(ctx context.Context, cfg *config.Config) (context.Context, *profiler, error) {
+	p := &profiler{cfg: cfg}
+	if cfg.Trace != "" {
+		p.tracer = trace.New()
+		ctx = trace.WithTracer(ctx, p.tracer)
+	}
+	if cfg.CPUProfile != "" {
+		f, err := os.Create(cfg.CPUProfile)
+		if err != nil {
+			return ctx, nil, fmt.Errorf("create cpu profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return ctx, nil, fmt.Errorf("start cpu profile: %w", err)
+		}
+		p.cpu = f
+	}
+	return ctx, p, nil
+}
+
+func (p *profiler) stop() error {
+	var errs []error
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		if err := p.cpu.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("write cpu profile: %w", err))
+		}
+	}
+	if p.cfg.MemProfile != "" {
+		if err := writeHeapProfile(p.cfg.MemProfile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if p.tracer != nil {
+		if err := p.tracer.WriteFile(p.cfg.Trace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create memory profile: %w", err)
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write memory profile: %w", err)
+	}
+	return f.Close()
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"os"
 	"runtime"
@@ -54,29 +55,67 @@ func run(args []string) int {
 		SilenceUsage: true,
 	}
 
-	rootCmd.Flags().Bool("write", false, "write result to files")
-	rootCmd.Flags().Bool("check", false, "check if files are formatted")
-	rootCmd.Flags().Bool("diff", false, "print the diff of required changes")
+	rootCmd.PersistentFlags().Bool("write", false, "write result to files")
+	rootCmd.PersistentFlags().String("config", "", "config file; by default .hclalign.hcl is searched from the target directory upward")
+	rootCmd.PersistentFlags().Bool("check", false, "check if files are formatted")
+	rootCmd.PersistentFlags().Bool("diff", false, "print the diff of required changes")
 	rootCmd.MarkFlagsMutuallyExclusive("write", "check", "diff")
-	rootCmd.Flags().Bool("stdin", false, "read from STDIN")
-	rootCmd.Flags().Bool("stdout", false, "write result to STDOUT")
-	rootCmd.Flags().StringSlice("include", config.DefaultInclude, "glob patterns to include")
-	rootCmd.Flags().StringSlice("exclude", config.DefaultExclude, "glob patterns to exclude")
-	rootCmd.Flags().StringSlice("order", config.CanonicalOrder, "order of variable block fields")
-	rootCmd.Flags().Bool("follow-symlinks", false, "follow symbolic links when traversing directories")
-	rootCmd.Flags().String("providers-schema", "", "path to providers schema file")
-	rootCmd.Flags().Bool("use-terraform-schema", false, "use terraform schema for providers")
-	rootCmd.Flags().String("schema-cache", "", "directory for provider schema cache")
-	rootCmd.Flags().Bool("no-schema-cache", false, "disable provider schema caching")
-	rootCmd.Flags().Int("concurrency", runtime.GOMAXPROCS(0), "maximum concurrency")
-	rootCmd.Flags().StringSlice("types", []string{"variable"}, "comma-separated list of block types to align")
-	rootCmd.Flags().Bool("all", false, "align all block types")
+	rootCmd.PersistentFlags().Bool("stdin", false, "read from STDIN")
+	rootCmd.PersistentFlags().Bool("stdout", false, "write result to STDOUT")
+	rootCmd.PersistentFlags().StringSlice("include", config.DefaultInclude, "glob patterns to include")
+	rootCmd.PersistentFlags().StringSlice("exclude", config.DefaultExclude, "glob patterns to exclude")
+	rootCmd.PersistentFlags().StringSlice("order", config.CanonicalOrder, "order of variable block fields")
+	rootCmd.PersistentFlags().Bool("follow-symlinks", false, "follow symbolic links when traversing directories")
+	rootCmd.PersistentFlags().String("providers-schema", "", "path to providers schema file")
+	rootCmd.PersistentFlags().Bool("use-terraform-schema", false, "use terraform schema for providers")
+	rootCmd.PersistentFlags().String("schema-cache", "", "directory for provider schema cache")
+	rootCmd.PersistentFlags().Bool("no-schema-cache", false, "disable provider schema caching")
+	rootCmd.PersistentFlags().Int("concurrency", runtime.GOMAXPROCS(0), "maximum concurrency")
+	rootCmd.PersistentFlags().Int("fmt-workers", runtime.NumCPU(), "maximum concurrent terraform fmt subprocesses")
+	rootCmd.PersistentFlags().String("fmt-strategy", "auto", "formatter run before and after alignment: auto, binary, go or none")
+	rootCmd.PersistentFlags().Bool("no-fmt", false, "align only: move reordered attribute and block spans and leave every other byte untouched")
+	rootCmd.PersistentFlags().String("tool", "auto", "binary used for fmt, version and providers schema: terraform, tofu or auto")
+	rootCmd.PersistentFlags().String("terraform-bin", "", "path or name of the terraform or tofu binary to run")
+	rootCmd.PersistentFlags().Bool("heredoc-indent", false, "rewrite heredocs to the indented <<- form, re-indented to the enclosing block")
+	rootCmd.PersistentFlags().String("heredoc-marker", "", "rename every heredoc marker to this identifier (e.g. EOT)")
+	rootCmd.PersistentFlags().Bool("heredoc-inline", false, "convert short single-line heredocs to quoted strings")
+	rootCmd.PersistentFlags().Int("max-blank-lines", 0, "collapse runs of blank lines to at most this many (0 keeps them)")
+	rootCmd.PersistentFlags().Bool("separate-blocks", false, "put exactly one blank line between top-level blocks")
+	rootCmd.PersistentFlags().Bool("sort-blocks", false, "sort top-level blocks by kind, then by label within each kind")
+	rootCmd.PersistentFlags().StringSlice("block-order", config.DefaultBlockOrder, "top-level block kind order used by --sort-blocks; unlisted kinds go last")
+	rootCmd.PersistentFlags().StringSlice("block-sort", nil, "per-kind sort used by --sort-blocks as kind=mode, mode one of natural, required-first (variable only) or as-is")
+	rootCmd.PersistentFlags().Bool("restructure", false, "move blocks between files of the same directory following --restructure-rule and merge duplicate terraform blocks")
+	rootCmd.PersistentFlags().StringSlice("restructure-rule", config.DefaultRestructure, "restructure rule as kind=file, such as variable=variables.tf")
+	rootCmd.PersistentFlags().Bool("sort-object-keys", false, "sort the keys of object constructors in attribute values")
+	rootCmd.PersistentFlags().StringSlice("sort-object-keys-in", nil, "limit --sort-object-keys to attributes matching these names or globs, such as tags or *_tags")
+	rootCmd.PersistentFlags().Bool("sort-lists", false, "sort and de-duplicate list literals of plain values in the attributes named by --sort-lists-in")
+	rootCmd.PersistentFlags().StringSlice("sort-lists-in", config.DefaultListAttrs, "attribute names or globs whose lists --sort-lists normalizes")
+	rootCmd.PersistentFlags().Bool("topo-sort", false, "order top-level blocks so that blocks come after the var, local, data, resource and module blocks they reference")
+	rootCmd.PersistentFlags().Bool("group-separators", false, "separate meta-argument, required, optional, computed, unknown and nested-block groups with one blank line")
+	rootCmd.PersistentFlags().Bool("trim-braces", false, "remove blank lines right after an opening brace and right before a closing brace")
+	rootCmd.PersistentFlags().StringSlice("types", []string{"variable"}, "comma-separated list of block types to align")
+	rootCmd.PersistentFlags().Bool("all", false, "align all block types")
+	rootCmd.PersistentFlags().StringSlice("only", nil, "only align blocks whose address matches one of these globs (e.g. resource.aws_iam_policy.*)")
+	rootCmd.PersistentFlags().StringSlice("skip", nil, "skip blocks whose address matches one of these globs (e.g. module.legacy_*)")
+	rootCmd.PersistentFlags().Bool("no-verify", false, "skip the semantic equivalence check before writing files")
+	rootCmd.PersistentFlags().Bool("verify-idempotent", false, "run the pipeline twice and report files whose second pass differs")
+	rootCmd.PersistentFlags().Bool("strict-comments", false, "fail when a comment changes its owning block or neighbouring attribute")
+	rootCmd.PersistentFlags().String("baseline", "", "baseline file of known misordered blocks; check and diff fail only on new ones")
+	rootCmd.PersistentFlags().String("report", "", "write parse diagnostics and comment audit findings to file")
+	rootCmd.PersistentFlags().String("report-format", "json", "format of the --report file: json or sarif")
+	rootCmd.PersistentFlags().String("trace", "", "write a Chrome trace-event JSON file covering every processing phase")
+	rootCmd.PersistentFlags().String("cpuprofile", "", "write a CPU profile to file")
+	rootCmd.PersistentFlags().String("memprofile", "", "write a heap profile to file")
+	rootCmd.AddCommand(cli.NewBaselineCmd())
 	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
 		return &cli.ExitCodeError{Err: err, Code: 2}
 	})
 
+	ctx, stop := withSignals(context.Background(), os.Stderr)
+	defer stop()
+
 	rootCmd.SetArgs(args)
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		var ec *cli.ExitCodeError
 		if errors.As(err, &ec) {
 			return ec.Code
@@ -0,0 +1,46 @@
+// cmd/hclalign/signals.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/oferchen/hclalign/internal/engine"
+)
+
+var notifySignals = func(c chan<- os.Signal) func() {
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	return func() { signal.Stop(c) }
+}
+
+func withSignals(ctx context.Context, stderr io.Writer) (context.Context, func()) {
+	sigs := make(chan os.Signal, 2)
+	stopNotify := notifySignals(sigs)
+	drain := make(chan struct{})
+	done := make(chan struct{})
+	go watchSignals(sigs, drain, done, stderr)
+	return engine.WithInterrupt(ctx, drain), func() {
+		stopNotify()
+		close(done)
+	}
+}
+
+func watchSignals(sigs <-chan os.Signal, drain chan<- struct{}, done <-chan struct{}, stderr io.Writer) {
+	select {
+	case sig := <-sigs:
+		close(drain)
+		fmt.Fprintf(stderr, "received %v: finishing files in progress; send it again to abort\n", sig)
+	case <-done:
+		return
+	}
+	select {
+	case sig := <-sigs:
+		fmt.Fprintf(stderr, "received %v again: aborting\n", sig)
+		osExit(130)
+	case <-done:
+	}
+}
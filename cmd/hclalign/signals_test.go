@@ -0,0 +1,74 @@
+// cmd/hclalign/signals_test.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWatchSignalsDrainThenAbort(t *testing.T) {
+	oldOsExit := osExit
+	t.Cleanup(func() { osExit = oldOsExit })
+	exited := make(chan int, 1)
+	osExit = func(code int) { exited <- code }
+
+	sigs := make(chan os.Signal, 2)
+	drain := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	var stderr bytes.Buffer
+	finished := make(chan struct{})
+	go func() {
+		watchSignals(sigs, drain, done, &stderr)
+		close(finished)
+	}()
+
+	sigs <- os.Interrupt
+	select {
+	case <-drain:
+	case <-time.After(time.Second):
+		t.Fatal("first signal did not start draining")
+	}
+
+	sigs <- syscall.SIGTERM
+	select {
+	case code := <-exited:
+		if code != 130 {
+			t.Fatalf("expected exit code 130, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not abort")
+	}
+	<-finished
+	if !bytes.Contains(stderr.Bytes(), []byte("send it again to abort")) || !bytes.Contains(stderr.Bytes(), []byte("aborting")) {
+		t.Fatalf("unexpected stderr: %q", stderr.String())
+	}
+}
+
+func TestWithSignalsStop(t *testing.T) {
+	oldNotify := notifySignals
+	t.Cleanup(func() { notifySignals = oldNotify })
+	var registered chan<- os.Signal
+	stopped := false
+	notifySignals = func(c chan<- os.Signal) func() {
+		registered = c
+		return func() { stopped = true }
+	}
+
+	var stderr bytes.Buffer
+	ctx, stop := withSignals(context.Background(), &stderr)
+	if ctx == nil || registered == nil {
+		t.Fatal("signals were not registered")
+	}
+	stop()
+	if !stopped {
+		t.Fatal("signal notification was not stopped")
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("unexpected stderr: %q", stderr.String())
+	}
+}
@@ -4,7 +4,15 @@ package config
 import (
 	"fmt"
 	"runtime"
+	"strings"
 
+	"github.com/oferchen/hclalign/internal/align"
+	"github.com/oferchen/hclalign/internal/diag"
+	terraformfmt "github.com/oferchen/hclalign/internal/fmt"
+	internalhcl "github.com/oferchen/hclalign/internal/hcl"
+	"github.com/oferchen/hclalign/internal/heredoc"
+	"github.com/oferchen/hclalign/internal/restructure"
+	"github.com/oferchen/hclalign/internal/tool"
 	"github.com/oferchen/hclalign/patternmatching"
 )
 
@@ -27,18 +35,55 @@ type Config struct {
 	Exclude            []string
 	Order              []string
 	Concurrency        int
+	FmtWorkers         int
+	FmtStrategy        string
+	NoFmt              bool
+	Tool               string
+	TerraformBin       string
+	HeredocIndent      bool
+	HeredocMarker      string
+	HeredocInline      bool
+	MaxBlankLines      int
+	SeparateBlocks     bool
+	TrimBraces         bool
+	GroupSeparators    bool
+	SortBlocks         bool
+	BlockOrder         []string
+	BlockSort          []string
+	TopoSort           bool
+	Restructure        bool
+	RestructureRules   []string
+	SortObjectKeys     bool
+	ObjectKeyAttrs     []string
+	SortLists          bool
+	ListAttrs          []string
 	ProvidersSchema    string
 	UseTerraformSchema bool
 	SchemaCache        string
 	NoSchemaCache      bool
 	Types              []string
+	Only               []string
+	Skip               []string
 	FollowSymlinks     bool
+	NoVerify           bool
+	VerifyIdempotent   bool
+	StrictComments     bool
+	Baseline           string
+	Report             string
+	ReportFormat       string
+	Trace              string
+	CPUProfile         string
+	MemProfile         string
+	FS                 WritableFS
 }
 
 var (
-	DefaultInclude = []string{"**/*.tf"}
-	DefaultExclude = []string{".terraform/**", "vendor/**"}
-	CanonicalOrder = []string{"description", "type", "default", "sensitive", "nullable"}
+	DefaultInclude     = []string{"**/*.tf", "**/*.tofu"}
+	DefaultExclude     = []string{".terraform/**", "vendor/**"}
+	CanonicalOrder     = []string{"description", "type", "default", "sensitive", "nullable"}
+	DefaultBlockOrder  = align.DefaultBlockOrder
+	DefaultRestructure = restructure.DefaultRules
+	DefaultListAttrs   = align.DefaultListAttrs
 )
 
 const (
@@ -52,12 +97,84 @@ func (c *Config) Validate() error {
 	if c.Concurrency > runtime.GOMAXPROCS(0) {
 		return fmt.Errorf("concurrency cannot exceed GOMAXPROCS (%d)", runtime.GOMAXPROCS(0))
 	}
+	if c.FmtWorkers < 0 {
+		return fmt.Errorf("fmt workers cannot be negative")
+	}
+	if err := terraformfmt.ValidateStrategy(c.FmtStrategy); err != nil {
+		return err
+	}
+	if err := tool.Validate(c.Tool); err != nil {
+		return err
+	}
+	if err := diag.ValidateFormat(c.ReportFormat); err != nil {
+		return err
+	}
+	if c.MaxBlankLines < 0 {
+		return fmt.Errorf("max blank lines cannot be negative")
+	}
+	if err := heredoc.ValidateMarker(c.HeredocMarker); err != nil {
+		return err
+	}
+	if c.NoFmt {
+		switch terraformfmt.Strategy(c.FmtStrategy) {
+		case "", terraformfmt.StrategyAuto, terraformfmt.StrategyNone:
+		default:
+			return fmt.Errorf("no-fmt cannot be combined with fmt strategy %q", c.FmtStrategy)
+		}
+		if c.Heredocs().Enabled() {
+			return fmt.Errorf("no-fmt cannot be combined with heredoc normalization")
+		}
+		if c.Spacing().Enabled() {
+			return fmt.Errorf("no-fmt cannot be combined with spacing normalization")
+		}
+		if c.GroupSeparators {
+			return fmt.Errorf("no-fmt cannot be combined with group separators")
+		}
+		if c.SortObjectKeys {
+			return fmt.Errorf("no-fmt cannot be combined with object key sorting")
+		}
+		if c.SortLists {
+			return fmt.Errorf("no-fmt cannot be combined with list sorting")
+		}
+	}
 	if err := patternmatching.ValidatePatterns(c.Include); err != nil {
 		return fmt.Errorf("invalid include: %w", err)
 	}
 	if err := patternmatching.ValidatePatterns(c.Exclude); err != nil {
 		return fmt.Errorf("invalid exclude: %w", err)
 	}
+	if c.SortBlocks {
+		if _, err := align.NewBlockSort(c.BlockOrder, c.BlockSort); err != nil {
+			return err
+		}
+	}
+	if c.Restructure {
+		if _, err := restructure.NewRules(c.RestructureRules); err != nil {
+			return err
+		}
+		switch {
+		case c.Stdin:
+			return fmt.Errorf("restructure cannot be combined with stdin")
+		case c.Stdout:
+			return fmt.Errorf("restructure cannot be combined with stdout")
+		case c.NoFmt:
+			return fmt.Errorf("restructure cannot be combined with no-fmt")
+		case c.Baseline != "":
+			return fmt.Errorf("restructure cannot be combined with baseline")
+		}
+	}
+	if err := align.ValidateAttrPatterns(c.ObjectKeyAttrs); err != nil {
+		return fmt.Errorf("invalid sort-object-keys-in: %w", err)
+	}
+	if err := align.ValidateAttrPatterns(c.ListAttrs); err != nil {
+		return fmt.Errorf("invalid sort-lists-in: %w", err)
+	}
+	if err := align.ValidateAddressPatterns(c.Only); err != nil {
+		return fmt.Errorf("invalid only: %w", err)
+	}
+	if err := align.ValidateAddressPatterns(c.Skip); err != nil {
+		return fmt.Errorf("invalid skip: %w", err)
+	}
 	if c.Types != nil {
 		if len(c.Types) == 0 {
 			c.Types = []string{"variable"}
@@ -72,6 +189,19 @@ func (c *Config) Validate() error {
 			}
 			seen[t] = struct{}{}
 		}
+		for _, t := range c.Types {
+			if _, ok := align.Lookup(t); !ok {
+				return fmt.Errorf("unknown type '%s' (registered: %s)", t, strings.Join(align.Registered(), ", "))
+			}
+		}
 	}
 	return nil
 }
+
+func (c *Config) Heredocs() heredoc.Options {
+	return heredoc.Options{Indent: c.HeredocIndent, Marker: c.HeredocMarker, Inline: c.HeredocInline}
+}
+
+func (c *Config) Spacing() internalhcl.Spacing {
+	return internalhcl.Spacing{MaxBlankLines: c.MaxBlankLines, SeparateBlocks: c.SeparateBlocks, TrimBraces: c.TrimBraces}
+}
@@ -15,7 +15,7 @@ func TestCanonicalOrderMatchesBuiltInAttributes(t *testing.T) {
 }
 
 func TestDefaultIncludeMatchesExpected(t *testing.T) {
-	expected := []string{"**/*.tf"}
+	expected := []string{"**/*.tf", "**/*.tofu"}
 	if !reflect.DeepEqual(DefaultInclude, expected) {
 		t.Fatalf("expected DefaultInclude to be %v, got %v", expected, DefaultInclude)
 	}
@@ -76,6 +76,10 @@ func TestValidateTypes(t *testing.T) {
 	if err := c.Validate(); err == nil {
 		t.Fatalf("expected error for duplicate type")
 	}
+	c = Config{Concurrency: 1, Include: DefaultInclude, Exclude: DefaultExclude, Types: []string{"variable", "varaible"}}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for unknown type")
+	}
 	c = Config{Concurrency: 1, Include: DefaultInclude, Exclude: DefaultExclude, Types: []string{}}
 	if err := c.Validate(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -84,3 +88,114 @@ func TestValidateTypes(t *testing.T) {
 		t.Fatalf("unexpected default type: %v", c.Types)
 	}
 }
+
+func TestValidateAddressFilters(t *testing.T) {
+	c := Config{Concurrency: 1, Include: DefaultInclude, Exclude: DefaultExclude, Only: []string{"resource.aws_iam_policy.*"}, Skip: []string{"module.legacy_*"}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c = Config{Concurrency: 1, Include: DefaultInclude, Exclude: DefaultExclude, Only: []string{"resource..x"}}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for invalid only pattern")
+	}
+	c = Config{Concurrency: 1, Include: DefaultInclude, Exclude: DefaultExclude, Skip: []string{"module.[x"}}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for invalid skip pattern")
+	}
+}
+
+func TestValidateHeredocs(t *testing.T) {
+	c := Config{Concurrency: 1, HeredocIndent: true, HeredocMarker: "EOT", HeredocInline: true}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c = Config{Concurrency: 1, HeredocMarker: "not a marker"}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for invalid heredoc marker")
+	}
+	c = Config{Concurrency: 1, NoFmt: true, HeredocIndent: true}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for no-fmt with heredoc normalization")
+	}
+}
+
+func TestValidateSpacing(t *testing.T) {
+	c := Config{Concurrency: 1, MaxBlankLines: 1, SeparateBlocks: true, TrimBraces: true}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c = Config{Concurrency: 1, MaxBlankLines: -1}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for negative max blank lines")
+	}
+	c = Config{Concurrency: 1, NoFmt: true, SeparateBlocks: true}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for no-fmt with spacing normalization")
+	}
+	c = Config{Concurrency: 1, NoFmt: true, GroupSeparators: true}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for no-fmt with group separators")
+	}
+}
+
+func TestValidateBlockSort(t *testing.T) {
+	c := Config{Concurrency: 1, SortBlocks: true, BlockOrder: DefaultBlockOrder, BlockSort: []string{"variable=required-first"}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c = Config{Concurrency: 1, SortBlocks: true, BlockSort: []string{"output=sideways"}}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for invalid block sort")
+	}
+	c = Config{Concurrency: 1, BlockSort: []string{"output=sideways"}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("block sort should only be validated with sort-blocks: %v", err)
+	}
+}
+
+func TestValidateRestructure(t *testing.T) {
+	c := Config{Concurrency: 1, Restructure: true, RestructureRules: DefaultRestructure}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range []Config{
+		{Concurrency: 1, Restructure: true, RestructureRules: []string{"variable=vars/variables.tf"}},
+		{Concurrency: 1, Restructure: true, RestructureRules: []string{"variable=variables.tf", "variable=inputs.tf"}},
+		{Concurrency: 1, Restructure: true, Stdout: true},
+		{Concurrency: 1, Restructure: true, NoFmt: true},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", c)
+		}
+	}
+}
+
+func TestValidateObjectKeys(t *testing.T) {
+	c := Config{Concurrency: 1, SortObjectKeys: true, ObjectKeyAttrs: []string{"tags", "*_tags"}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c = Config{Concurrency: 1, SortObjectKeys: true, ObjectKeyAttrs: []string{"tags["}}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for invalid attribute pattern")
+	}
+	c = Config{Concurrency: 1, SortObjectKeys: true, NoFmt: true}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for no-fmt with object key sorting")
+	}
+}
+
+func TestValidateLists(t *testing.T) {
+	c := Config{Concurrency: 1, SortLists: true, ListAttrs: DefaultListAttrs}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c = Config{Concurrency: 1, SortLists: true, ListAttrs: []string{"[depends_on"}}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for invalid attribute pattern")
+	}
+	c = Config{Concurrency: 1, SortLists: true, NoFmt: true}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected error for no-fmt with list sorting")
+	}
+}
@@ -0,0 +1,162 @@
+// config/file.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+const FileName = ".hclalign.hcl"
+
+var fileKeys = map[string]bool{
+	"include":              true,
+	"exclude":              true,
+	"order":                true,
+	"follow_symlinks":      false,
+	"providers_schema":     false,
+	"use_terraform_schema": false,
+	"schema_cache":         false,
+	"no_schema_cache":      false,
+	"concurrency":          false,
+	"fmt_workers":          false,
+	"fmt_strategy":         false,
+	"no_fmt":               false,
+	"tool":                 false,
+	"terraform_bin":        false,
+	"heredoc_indent":       false,
+	"heredoc_marker":       false,
+	"heredoc_inline":       false,
+	"max_blank_lines":      false,
+	"separate_blocks":      false,
+	"trim_braces":          false,
+	"group_separators":     false,
+	"sort_blocks":          false,
+	"block_order":          true,
+	"block_sort":           true,
+	"topo_sort":            false,
+	"sort_object_keys":     false,
+	"sort_object_keys_in":  true,
+	"sort_lists":           false,
+	"sort_lists_in":        true,
+	"restructure_rule":     true,
+	"types":                true,
+	"all":                  false,
+	"only":                 true,
+	"skip":                 true,
+	"no_verify":            false,
+	"verify_idempotent":    false,
+	"strict_comments":      false,
+}
+
+type FileSetting struct {
+	Flag   string
+	Values []string
+}
+
+type File struct {
+	Path     string
+	Settings []FileSetting
+}
+
+func FindFile(target string) (string, error) {
+	dir, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		path := filepath.Join(dir, FileName)
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			return path, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func LoadFile(path string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if _, ok := attrs["types"]; ok {
+		if _, ok := attrs["all"]; ok {
+			return nil, fmt.Errorf("%s: types and all are mutually exclusive", path)
+		}
+	}
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := &File{Path: path}
+	for _, name := range names {
+		attr := attrs[name]
+		list, ok := fileKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown setting %q", attr.NameRange, name)
+		}
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		values, err := settingValues(value, list)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", attr.NameRange, name, err)
+		}
+		out.Settings = append(out.Settings, FileSetting{Flag: strings.ReplaceAll(name, "_", "-"), Values: values})
+	}
+	return out, nil
+}
+
+func settingValues(value cty.Value, list bool) ([]string, error) {
+	if value.IsNull() {
+		return nil, errors.New("value cannot be null")
+	}
+	if !list {
+		str, err := convert.Convert(value, cty.String)
+		if err != nil {
+			return nil, errors.New("expected a string, number or bool")
+		}
+		return []string{str.AsString()}, nil
+	}
+	strs, err := convert.Convert(value, cty.List(cty.String))
+	if err != nil {
+		return nil, errors.New("expected a list of strings")
+	}
+	values := []string{}
+	for _, v := range strs.AsValueSlice() {
+		if v.IsNull() {
+			return nil, errors.New("list elements cannot be null")
+		}
+		values = append(values, v.AsString())
+	}
+	return values, nil
+}
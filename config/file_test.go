@@ -0,0 +1,83 @@
+// config/file_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	main := filepath.Join(nested, "main.tf")
+	require.NoError(t, os.WriteFile(main, nil, 0o644))
+
+	want := filepath.Join(root, FileName)
+	require.NoError(t, os.WriteFile(want, nil, 0o644))
+	for _, start := range []string{nested, main, filepath.Join(nested, "missing.tf"), root} {
+		path, err := FindFile(start)
+		require.NoError(t, err)
+		require.Equal(t, want, path, start)
+	}
+
+	closer := filepath.Join(root, "a", FileName)
+	require.NoError(t, os.WriteFile(closer, nil, 0o644))
+	path, err := FindFile(main)
+	require.NoError(t, err)
+	require.Equal(t, closer, path)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte(`only        = ["resource.aws_iam_policy.*"]
+skip        = ["module.legacy_*"]
+concurrency = 2
+fmt_strategy = "binary"
+sort_blocks = true
+types       = []
+`), 0o644))
+
+	file, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, path, file.Path)
+	require.Equal(t, []FileSetting{
+		{Flag: "concurrency", Values: []string{"2"}},
+		{Flag: "fmt-strategy", Values: []string{"binary"}},
+		{Flag: "only", Values: []string{"resource.aws_iam_policy.*"}},
+		{Flag: "skip", Values: []string{"module.legacy_*"}},
+		{Flag: "sort-blocks", Values: []string{"true"}},
+		{Flag: "types", Values: []string{}},
+	}, file.Settings)
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{name: "syntax", src: "only = [\n", msg: "Missing expression"},
+		{name: "block", src: "filters {}\n", msg: "Unexpected \"filters\" block"},
+		{name: "unknown", src: "write = true\n", msg: `unknown setting "write"`},
+		{name: "list", src: "only = \"resource.*\"\n", msg: "only: expected a list of strings"},
+		{name: "scalar", src: "concurrency = [1]\n", msg: "concurrency: expected a string, number or bool"},
+		{name: "null", src: "tool = null\n", msg: "tool: value cannot be null"},
+		{name: "variables", src: "only = [var.x]\n", msg: "Variables not allowed"},
+		{name: "types and all", src: "types = [\"module\"]\nall = true\n", msg: "types and all are mutually exclusive"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			require.NoError(t, os.WriteFile(path, []byte(tc.src), 0o644))
+			_, err := LoadFile(path)
+			require.ErrorContains(t, err, tc.msg)
+		})
+	}
+
+	_, err := LoadFile(filepath.Join(t.TempDir(), FileName))
+	require.ErrorContains(t, err, "reading config file")
+}
@@ -0,0 +1,13 @@
+// config/fs.go
+package config
+
+import internalfs "github.com/oferchen/hclalign/internal/fs"
+
+type (
+	WritableFS = internalfs.WritableFS
+	MemFS      = internalfs.MemFS
+)
+
+func OSFS(root string) WritableFS { return internalfs.NewOSFS(root) }
+
+func NewMemFS(files map[string][]byte) *MemFS { return internalfs.NewMemFS(files) }
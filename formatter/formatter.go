@@ -7,6 +7,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 
 	internalfs "github.com/oferchen/hclalign/internal/fs"
@@ -27,6 +28,7 @@ func Format(src []byte, filename string) (formatted []byte, hints internalfs.Hin
 		err = diags
 		return
 	}
+	formatBody(f.Body(), nil)
 	formatted = f.Bytes()
 	formatted = bytes.ReplaceAll(formatted, []byte("\r\n"), []byte("\n"))
 	if len(formatted) > 0 {
@@ -35,3 +37,108 @@ func Format(src []byte, filename string) (formatted []byte, hints internalfs.Hin
 	}
 	return
 }
+
+func formatBody(body *hclwrite.Body, inBlocks []string) {
+	for name, attr := range body.Attributes() {
+		tokens := attr.Expr().BuildTokens(nil)
+		if len(inBlocks) == 1 && inBlocks[0] == "variable" && name == "type" {
+			body.SetAttributeRaw(name, formatTypeExpr(tokens))
+			continue
+		}
+		body.SetAttributeRaw(name, formatValueExpr(tokens))
+	}
+	for _, block := range body.Blocks() {
+		block.SetLabels(block.Labels())
+		formatBody(block.Body(), append(inBlocks[:len(inBlocks):len(inBlocks)], block.Type()))
+	}
+}
+
+func formatValueExpr(tokens hclwrite.Tokens) hclwrite.Tokens {
+	if len(tokens) < 5 {
+		return tokens
+	}
+	if tokens[0].Type != hclsyntax.TokenOQuote ||
+		tokens[1].Type != hclsyntax.TokenTemplateInterp ||
+		tokens[len(tokens)-2].Type != hclsyntax.TokenTemplateSeqEnd ||
+		tokens[len(tokens)-1].Type != hclsyntax.TokenCQuote {
+		return tokens
+	}
+	inside := tokens[2 : len(tokens)-2]
+	quotes := 0
+	for _, tok := range inside {
+		switch {
+		case tok.Type == hclsyntax.TokenOQuote:
+			quotes++
+		case tok.Type == hclsyntax.TokenCQuote:
+			quotes--
+		case quotes > 0:
+		case tok.Type == hclsyntax.TokenTemplateInterp, tok.Type == hclsyntax.TokenTemplateSeqEnd, tok.Type == hclsyntax.TokenQuotedLit:
+			return tokens
+		}
+	}
+	trimmed := trimNewlines(inside)
+	if len(trimmed) == 0 {
+		return tokens
+	}
+	multiLine := false
+	for _, tok := range trimmed {
+		if tok.Type == hclsyntax.TokenNewline {
+			multiLine = true
+			break
+		}
+	}
+	parens := trimmed[0].Type == hclsyntax.TokenOParen && trimmed[len(trimmed)-1].Type == hclsyntax.TokenCParen
+	if !multiLine || parens {
+		return trimmed
+	}
+	wrapped := make(hclwrite.Tokens, 0, len(trimmed)+2)
+	wrapped = append(wrapped, &hclwrite.Token{Type: hclsyntax.TokenOParen, Bytes: []byte("(")})
+	wrapped = append(wrapped, trimmed...)
+	return append(wrapped, &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
+}
+
+func formatTypeExpr(tokens hclwrite.Tokens) hclwrite.Tokens {
+	switch len(tokens) {
+	case 1:
+		if tokens[0].Type != hclsyntax.TokenIdent {
+			return tokens
+		}
+		switch name := string(tokens[0].Bytes); name {
+		case "list", "map":
+			return collectionOf(name, "any")
+		}
+	case 3:
+		if tokens[0].Type != hclsyntax.TokenOQuote ||
+			tokens[1].Type != hclsyntax.TokenQuotedLit ||
+			tokens[2].Type != hclsyntax.TokenCQuote {
+			return tokens
+		}
+		switch name := string(tokens[1].Bytes); name {
+		case "string":
+			return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte(name)}}
+		case "list", "map":
+			return collectionOf(name, "string")
+		}
+	}
+	return tokens
+}
+
+func collectionOf(kind, elem string) hclwrite.Tokens {
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte(kind)},
+		{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+		{Type: hclsyntax.TokenIdent, Bytes: []byte(elem)},
+		{Type: hclsyntax.TokenCParen, Bytes: []byte(")")},
+	}
+}
+
+func trimNewlines(tokens hclwrite.Tokens) hclwrite.Tokens {
+	start, end := 0, len(tokens)
+	for start < end && tokens[start].Type == hclsyntax.TokenNewline {
+		start++
+	}
+	for end > start && tokens[end-1].Type == hclsyntax.TokenNewline {
+		end--
+	}
+	return tokens[start:end]
+}
@@ -99,3 +99,75 @@ func TestFormatRejectsInvalidUTF8(t *testing.T) {
 		t.Fatalf("expected error for invalid UTF-8 input")
 	}
 }
+
+func TestFormatTerraformRewrites(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "unwrap_interpolation",
+			input: "a = \"${var.foo}\"\n",
+			want:  "a = var.foo\n",
+		},
+		{
+			name:  "keep_template",
+			input: "a = \"${var.foo}-bar\"\nb = \"${var.a}${var.b}\"\n",
+			want:  "a = \"${var.foo}-bar\"\nb = \"${var.a}${var.b}\"\n",
+		},
+		{
+			name:  "nested_quotes",
+			input: "a = \"${foo(\"${bar}\")}\"\n",
+			want:  "a = foo(\"${bar}\")\n",
+		},
+		{
+			name:  "multi_line_wrapped",
+			input: "a = \"${\nvar.x +\nvar.y\n}\"\n",
+			want:  "a = (var.x +\nvar.y)\n",
+		},
+		{
+			name:  "multi_line_collapsed",
+			input: "a = \"${\nvar.x\n}\"\n",
+			want:  "a = var.x\n",
+		},
+		{
+			name:  "heredoc_interpolation",
+			input: "a = \"${<<EOT\nhi\nEOT\n}\"\n",
+			want:  "a = <<EOT\nhi\nEOT\n",
+		},
+		{
+			name:  "legacy_types",
+			input: "variable \"a\" {\ntype = \"string\"\n}\nvariable \"b\" {\ntype = \"list\"\n}\nvariable \"c\" {\ntype = map\n}\n",
+			want:  "variable \"a\" {\n  type = string\n}\nvariable \"b\" {\n  type = list(string)\n}\nvariable \"c\" {\n  type = map(any)\n}\n",
+		},
+		{
+			name:  "type_outside_variable",
+			input: "locals {\ntype = \"string\"\n}\n",
+			want:  "locals {\n  type = \"string\"\n}\n",
+		},
+		{
+			name:  "quote_labels",
+			input: "resource aws_instance web {\n}\n",
+			want:  "resource \"aws_instance\" \"web\" {\n}\n",
+		},
+		{
+			name:  "ellipsis",
+			input: "a = max(var.values ...)\n",
+			want:  "a = max(var.values...)\n",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, _, err := Format([]byte(tc.input), "test.tf")
+			if err != nil {
+				t.Fatalf("Format returned error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("unexpected output\nwant: %q\n got: %q", tc.want, got)
+			}
+		})
+	}
+}
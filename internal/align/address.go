@@ -0,0 +1,126 @@
+// internal/align/address.go
+package align
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type filterDecision int
+
+const (
+	filterAlign filterDecision = iota
+	filterDescend
+	filterExclude
+)
+
+type AddressFilter struct {
+	only      [][]string
+	skip      [][]string
+	matched   atomic.Int64
+	unmatched atomic.Int64
+}
+
+func NewAddressFilter(only, skip []string) (*AddressFilter, error) {
+	f := &AddressFilter{}
+	var err error
+	if f.only, err = parseAddressPatterns(only); err != nil {
+		return nil, fmt.Errorf("invalid only: %w", err)
+	}
+	if f.skip, err = parseAddressPatterns(skip); err != nil {
+		return nil, fmt.Errorf("invalid skip: %w", err)
+	}
+	return f, nil
+}
+
+func ValidateAddressPatterns(patterns []string) error {
+	_, err := parseAddressPatterns(patterns)
+	return err
+}
+
+func parseAddressPatterns(patterns []string) ([][]string, error) {
+	parsed := make([][]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p == "" {
+			return nil, fmt.Errorf("address pattern is empty")
+		}
+		segs := strings.Split(p, ".")
+		for _, s := range segs {
+			if s == "" {
+				return nil, fmt.Errorf("address pattern '%s' has an empty segment", p)
+			}
+			if _, err := path.Match(s, ""); err != nil {
+				return nil, fmt.Errorf("invalid address pattern '%s': %w", p, err)
+			}
+		}
+		parsed = append(parsed, segs)
+	}
+	return parsed, nil
+}
+
+func (f *AddressFilter) Clone() *AddressFilter {
+	if f == nil {
+		return nil
+	}
+	return &AddressFilter{only: f.only, skip: f.skip}
+}
+
+func (f *AddressFilter) Counts() (matched, unmatched int64) {
+	return f.matched.Load(), f.unmatched.Load()
+}
+
+func (f *AddressFilter) Active() bool {
+	return f != nil && (len(f.only) > 0 || len(f.skip) > 0)
+}
+
+func (f *AddressFilter) decide(addr []string, selected bool) filterDecision {
+	for _, p := range f.skip {
+		if matchAddress(p, addr) {
+			f.unmatched.Add(1)
+			return filterExclude
+		}
+	}
+	if selected {
+		return filterAlign
+	}
+	if len(f.only) == 0 {
+		f.matched.Add(1)
+		return filterAlign
+	}
+	for _, p := range f.only {
+		if matchAddress(p, addr) {
+			f.matched.Add(1)
+			return filterAlign
+		}
+	}
+	for _, p := range f.only {
+		if len(p) > len(addr) && matchAddress(p[:len(addr)], addr) {
+			return filterDescend
+		}
+	}
+	f.unmatched.Add(1)
+	return filterExclude
+}
+
+func matchAddress(pattern, addr []string) bool {
+	if len(pattern) > len(addr) {
+		return false
+	}
+	for i, p := range pattern {
+		if ok, _ := path.Match(p, addr[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func blockAddress(parent []string, block *hclwrite.Block) []string {
+	addr := make([]string, 0, len(parent)+1+len(block.Labels()))
+	addr = append(addr, parent...)
+	addr = append(addr, block.Type())
+	return append(addr, block.Labels()...)
+}
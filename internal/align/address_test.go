@@ -0,0 +1,131 @@
+// internal/align/address_test.go
+package align_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressFilter(t *testing.T) {
+	src := `resource "aws_iam_policy" "p" {
+  name   = "p"
+  policy = "{}"
+  lifecycle {
+    prevent_destroy       = true
+    create_before_destroy = true
+  }
+}
+
+resource "aws_s3_bucket" "b" {
+  bucket = "b"
+  count  = 1
+}
+
+module "legacy_vpc" {
+  version = "1.0.0"
+  source  = "./vpc"
+}
+
+module "network" {
+  version = "1.0.0"
+  source  = "./net"
+}
+`
+	policyAligned := `resource "aws_iam_policy" "p" {
+  name   = "p"
+  policy = "{}"
+
+  lifecycle {
+    prevent_destroy       = true
+    create_before_destroy = true
+  }
+}
+`
+	bucketAligned := `resource "aws_s3_bucket" "b" {
+  count  = 1
+  bucket = "b"
+}
+`
+	cases := []struct {
+		name      string
+		only      []string
+		skip      []string
+		contains  []string
+		unchanged []string
+		matched   int64
+		unmatched int64
+	}{
+		{
+			name:      "only resource type",
+			only:      []string{"resource.aws_iam_policy.*"},
+			contains:  []string{policyAligned},
+			unchanged: []string{"bucket = \"b\"\n  count  = 1", "version = \"1.0.0\"\n  source  = \"./vpc\"", "version = \"1.0.0\"\n  source  = \"./net\""},
+			matched:   1,
+			unmatched: 3,
+		},
+		{
+			name:      "skip module glob",
+			skip:      []string{"module.legacy_*"},
+			contains:  []string{policyAligned, bucketAligned, "source  = \"./net\"\n  version = \"1.0.0\""},
+			unchanged: []string{"version = \"1.0.0\"\n  source  = \"./vpc\""},
+			matched:   3,
+			unmatched: 1,
+		},
+		{
+			name:      "only nested block",
+			only:      []string{"resource.*.*.lifecycle"},
+			unchanged: []string{"policy = \"{}\"\n  lifecycle {", "bucket = \"b\"\n  count  = 1"},
+			matched:   1,
+			unmatched: 2,
+		},
+		{
+			name:      "skip wins over only",
+			only:      []string{"resource"},
+			skip:      []string{"resource.*.*.lifecycle"},
+			contains:  []string{policyAligned, bucketAligned},
+			unchanged: []string{"version = \"1.0.0\"\n  source  = \"./vpc\""},
+			matched:   2,
+			unmatched: 3,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := alignpkg.NewAddressFilter(tc.only, tc.skip)
+			require.NoError(t, err)
+			file, diags := hclwrite.ParseConfig([]byte(src), "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			require.NoError(t, alignpkg.Apply(file, &alignpkg.Options{Filter: filter}))
+			got := string(hclwrite.Format(file.Bytes()))
+			for _, want := range tc.contains {
+				require.Contains(t, got, want)
+			}
+			for _, want := range tc.unchanged {
+				require.Contains(t, got, want)
+			}
+			matched, unmatched := filter.Counts()
+			require.Equal(t, tc.matched, matched)
+			require.Equal(t, tc.unmatched, unmatched)
+
+			clone := filter.Clone()
+			matched, unmatched = clone.Counts()
+			require.Zero(t, matched+unmatched)
+		})
+	}
+}
+
+func TestAddressFilterInvalid(t *testing.T) {
+	for _, patterns := range [][]string{{""}, {"resource..x"}, {"resource.[x"}} {
+		require.Error(t, alignpkg.ValidateAddressPatterns(patterns))
+		_, err := alignpkg.NewAddressFilter(patterns, nil)
+		require.ErrorContains(t, err, "invalid only")
+		_, err = alignpkg.NewAddressFilter(nil, patterns)
+		require.ErrorContains(t, err, "invalid skip")
+	}
+	var filter *alignpkg.AddressFilter
+	require.False(t, filter.Active())
+	require.Nil(t, filter.Clone())
+}
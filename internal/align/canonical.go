@@ -13,5 +13,6 @@ var CanonicalBlockAttrOrder = map[string][]string{
 		"required_providers",
 		"backend",
 		"cloud",
+		"encryption",
 	},
 }
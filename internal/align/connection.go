@@ -11,7 +11,6 @@ type connectionStrategy struct{}
 func (connectionStrategy) Name() string { return "connection" }
 
 func (connectionStrategy) Align(block *hclwrite.Block, opts *Options) error {
-	attrs := block.Body().Attributes()
 	allowed := map[string]struct{}{
 		"type":                 {},
 		"host":                 {},
@@ -36,7 +35,8 @@ func (connectionStrategy) Align(block *hclwrite.Block, opts *Options) error {
 		"host_key":             {},
 		"bastion_host_key":     {},
 	}
-	order := ihcl.AttributeOrder(block.Body(), attrs)
+	layout := ihcl.NewLayout(block.Body())
+	order := layout.AttrNames()
 	var names []string
 	for _, name := range order {
 		if _, ok := allowed[name]; ok {
@@ -48,7 +48,7 @@ func (connectionStrategy) Align(block *hclwrite.Block, opts *Options) error {
 			names = append(names, name)
 		}
 	}
-	return reorderBlock(block, names)
+	return reorderBlock(block, layout, names)
 }
 
 func init() { Register(connectionStrategy{}) }
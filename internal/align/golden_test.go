@@ -15,6 +15,7 @@ import (
 	alignschema "github.com/oferchen/hclalign/internal/align/schema"
 	terraformfmt "github.com/oferchen/hclalign/internal/fmt"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/tool"
 )
 
 func TestGolden(t *testing.T) {
@@ -58,7 +59,7 @@ func TestGolden(t *testing.T) {
 				t.Fatalf("read out: %v", err)
 			}
 
-			fmtBytes, _, err := terraformfmt.Format(context.Background(), inBytes, inPath, string(terraformfmt.StrategyGo))
+			fmtBytes, _, err := terraformfmt.Format(context.Background(), tool.Binary{}, inBytes, inPath, string(terraformfmt.StrategyGo))
 			if err != nil {
 				t.Fatalf("format input: %v", err)
 			}
@@ -67,7 +68,7 @@ func TestGolden(t *testing.T) {
 				fmtBytes = fmtBytes[:len(fmtBytes)-1]
 			}
 
-			againFmt, _, err := terraformfmt.Format(context.Background(), fmtBytes, inPath, string(terraformfmt.StrategyGo))
+			againFmt, _, err := terraformfmt.Format(context.Background(), tool.Binary{}, fmtBytes, inPath, string(terraformfmt.StrategyGo))
 			if err != nil {
 				t.Fatalf("format fmt: %v", err)
 			}
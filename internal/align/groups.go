@@ -0,0 +1,170 @@
+// internal/align/groups.go
+package align
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ihcl "github.com/oferchen/hclalign/internal/hcl"
+)
+
+type attrGroup int
+
+const (
+	groupMeta attrGroup = iota
+	groupRequired
+	groupOptional
+	groupComputed
+	groupUnknown
+	groupBlocks
+	groupLifecycle
+	groupDependsOn
+)
+
+var groupedBlockTypes = map[string]struct{}{
+	"resource": {},
+	"data":     {},
+	"module":   {},
+	"variable": {},
+}
+
+func separateGroups(block *hclwrite.Block, schema *Schema) {
+	if _, ok := groupedBlockTypes[block.Type()]; !ok {
+		return
+	}
+	body := block.Body()
+	layout := ihcl.NewLayout(body)
+	if len(layout.Items) < 2 {
+		return
+	}
+	var open hclwrite.Tokens
+	if first := layout.Items[0].Before; len(first) > 0 && first[0].Type == hclsyntax.TokenNewline {
+		open = first[:1]
+		layout.Items[0].Before = first[1:]
+	}
+	items := make([]ihcl.Item, 0, len(layout.Items))
+	var trailing []ihcl.Item
+	for _, it := range layout.Items {
+		if itemGroup(block.Type(), it, schema) >= groupLifecycle {
+			trailing = append(trailing, it)
+			continue
+		}
+		items = append(items, it)
+	}
+	sortTrailing(trailing)
+	items = append(items, trailing...)
+
+	changed := false
+	befores := make([]hclwrite.Tokens, len(items))
+	for i, it := range items {
+		befores[i] = it.Before
+		moved := it.Block != layout.Items[i].Block || it.Name != layout.Items[i].Name
+		changed = changed || moved
+		if i == 0 {
+			before := it.Before
+			if moved {
+				before = trimNewlines(before)
+			}
+			befores[i] = append(append(hclwrite.Tokens{}, open...), before...)
+			continue
+		}
+		if itemGroup(block.Type(), it, schema) == itemGroup(block.Type(), items[i-1], schema) {
+			continue
+		}
+		sep := blankSeparator(it.Before, layout.Newline)
+		if !tokensEqual(sep, it.Before) {
+			befores[i] = sep
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	tail := layout.Tail
+	if last := len(items) - 1; items[last].Block != layout.Items[last].Block || items[last].Name != layout.Items[last].Name {
+		tail = trimNewlines(tail)
+	}
+
+	detachItems(body, layout)
+	w := ihcl.NewWriter(body, layout.Newline)
+	for i, it := range items {
+		w.Tokens(befores[i])
+		if it.Kind == ihcl.ItemBlock {
+			w.Block(it.Block)
+			continue
+		}
+		w.Tokens(it.Lead)
+		w.Attr(it.Name, it.Expr)
+	}
+	w.Tokens(tail)
+	w.Finish(layout.TrailingComma)
+}
+
+func itemGroup(blockType string, it ihcl.Item, schema *Schema) attrGroup {
+	if it.Kind == ihcl.ItemBlock {
+		if it.Block.Type() == "lifecycle" {
+			return groupLifecycle
+		}
+		return groupBlocks
+	}
+	if it.Name == "depends_on" && blockType != "variable" {
+		return groupDependsOn
+	}
+	for _, name := range CanonicalBlockAttrOrder[blockType] {
+		if name != it.Name {
+			continue
+		}
+		if blockType == "variable" {
+			return groupOptional
+		}
+		return groupMeta
+	}
+	if schema == nil || blockType == "module" || blockType == "variable" {
+		return groupUnknown
+	}
+	if _, ok := schema.Required[it.Name]; ok {
+		return groupRequired
+	}
+	if _, ok := schema.Optional[it.Name]; ok {
+		return groupOptional
+	}
+	if _, ok := schema.Computed[it.Name]; ok {
+		return groupComputed
+	}
+	return groupUnknown
+}
+
+func sortTrailing(items []ihcl.Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Kind == ihcl.ItemBlock && items[j].Kind != ihcl.ItemBlock
+	})
+}
+
+func trimNewlines(before hclwrite.Tokens) hclwrite.Tokens {
+	for len(before) > 0 && before[0].Type == hclsyntax.TokenNewline {
+		before = before[1:]
+	}
+	return before
+}
+
+func blankSeparator(before hclwrite.Tokens, newline []byte) hclwrite.Tokens {
+	i := 0
+	for i < len(before) && before[i].Type == hclsyntax.TokenNewline {
+		i++
+	}
+	sep := hclwrite.Tokens{{Type: hclsyntax.TokenNewline, Bytes: newline}}
+	return append(sep, before[i:]...)
+}
+
+func tokensEqual(a, b hclwrite.Tokens) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || string(a[i].Bytes) != string(b[i].Bytes) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+// internal/align/groups_test.go
+package align_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupSeparators(t *testing.T) {
+	sch := &alignpkg.Schema{
+		Required: map[string]struct{}{"foo": {}},
+		Optional: map[string]struct{}{"bar": {}},
+		Computed: map[string]struct{}{"baz": {}},
+	}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "resource with schema",
+			in: `resource "test_thing" "ex" {
+  random = 4
+  baz = 3
+  bar = 2
+  depends_on = []
+  foo = 1
+
+
+  count = 1
+  lifecycle {}
+  nested {}
+}
+`,
+			want: `resource "test_thing" "ex" {
+  count = 1
+
+  foo = 1
+
+  bar = 2
+
+  baz = 3
+
+  random = 4
+
+  nested {}
+
+  lifecycle {}
+
+  depends_on = []
+}
+`,
+		},
+		{
+			name: "depends_on and lifecycle last",
+			in: `resource "test_thing" "ex" {
+  depends_on = [a.b]
+  # keep with lifecycle
+  lifecycle {
+    prevent_destroy = true
+  }
+  foo = 1
+}
+`,
+			want: `resource "test_thing" "ex" {
+  foo = 1
+
+  # keep with lifecycle
+  lifecycle {
+    prevent_destroy = true
+  }
+
+  depends_on = [a.b]
+}
+`,
+		},
+		{
+			name: "blank lines before the brace do not follow a moved item",
+			in: `module "m" {
+  source = "./m"
+  depends_on = [a.b]
+  cidr = "10.0.0.0/16"
+
+}
+`,
+			want: `module "m" {
+  source = "./m"
+
+  cidr = "10.0.0.0/16"
+
+  depends_on = [a.b]
+}
+`,
+		},
+		{
+			name: "comments stay with their attribute",
+			in: `data "test_thing" "ex" {
+  for_each = {}
+  # the foo
+  foo = 1
+  bar = 2
+}
+`,
+			want: `data "test_thing" "ex" {
+  for_each = {}
+
+  # the foo
+  foo = 1
+
+  bar = 2
+}
+`,
+		},
+		{
+			name: "module",
+			in: `module "m" {
+  name = "x"
+  source = "./m"
+  version = "1.0"
+}
+`,
+			want: `module "m" {
+  source  = "./m"
+  version = "1.0"
+
+  name = "x"
+}
+`,
+		},
+		{
+			name: "variable",
+			in: `variable "v" {
+  custom = 1
+  type = string
+  validation {
+    condition     = true
+    error_message = "x"
+  }
+}
+`,
+			want: `variable "v" {
+  type = string
+
+  custom = 1
+
+  validation {
+    condition     = true
+    error_message = "x"
+  }
+}
+`,
+		},
+		{
+			name: "other blocks untouched",
+			in: `output "o" {
+  value       = 1
+  description = "d"
+  custom      = 2
+}
+`,
+			want: `output "o" {
+  description = "d"
+  value       = 1
+  custom      = 2
+}
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tc.in), "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			opts := &alignpkg.Options{Schemas: map[string]*alignpkg.Schema{"test_thing": sch}, GroupSeparators: true}
+			require.NoError(t, alignpkg.Apply(file, opts))
+			got := hclwrite.Format(file.Bytes())
+			require.Equal(t, tc.want, string(got))
+
+			again, diags := hclwrite.ParseConfig(got, "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			require.NoError(t, alignpkg.Apply(again, opts))
+			require.Equal(t, string(got), string(hclwrite.Format(again.Bytes())))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// internal/align/keys.go
+package align
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ihcl "github.com/oferchen/hclalign/internal/hcl"
+)
+
+func ValidateAttrPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if p == "" {
+			return fmt.Errorf("attribute pattern is empty")
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("attribute pattern '%s': %w", p, err)
+		}
+	}
+	return nil
+}
+
+func attrMatches(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func sortObjectKeys(body *hclwrite.Body, patterns []string) {
+	for name, attr := range body.Attributes() {
+		if !attrMatches(patterns, name) {
+			continue
+		}
+		if toks, changed := ihcl.SortObjectKeys(attr.Expr().BuildTokens(nil)); changed {
+			body.SetAttributeRaw(name, toks)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// internal/align/keys_test.go
+package align_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortObjectKeys(t *testing.T) {
+	src := "resource \"r\" \"x\" {\n  tags = { b = 1, a = 2 }\n  default_tags = { d = 1, c = 2 }\n  env          = { f = 1, e = 2 }\n  nested {\n    extra_tags = { h = 1, g = 2 }\n  }\n}\n"
+	tests := []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{
+			name: "everywhere",
+			want: "resource \"r\" \"x\" {\n  tags         = { a = 2, b = 1 }\n  default_tags = { c = 2, d = 1 }\n  env          = { e = 2, f = 1 }\n  nested {\n    extra_tags = { g = 2, h = 1 }\n  }\n}\n",
+		},
+		{
+			name:     "selected attributes",
+			patterns: []string{"tags", "*_tags"},
+			want:     "resource \"r\" \"x\" {\n  tags         = { a = 2, b = 1 }\n  default_tags = { c = 2, d = 1 }\n  env          = { f = 1, e = 2 }\n  nested {\n    extra_tags = { g = 2, h = 1 }\n  }\n}\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(src), "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			opts := &alignpkg.Options{Types: map[string]struct{}{}, SortObjectKeys: true, ObjectKeyAttrs: tc.patterns}
+			require.NoError(t, alignpkg.Apply(file, opts))
+			require.Equal(t, tc.want, string(file.Bytes()))
+		})
+	}
+}
+
+func TestSortObjectKeysOnly(t *testing.T) {
+	src := "resource \"a\" \"x\" {\n  tags = { z = 1, y = 2 }\n}\n\nresource \"b\" \"y\" {\n  tags = { z = 1, y = 2 }\n  nested {\n    tags = { z = 1, y = 2 }\n  }\n}\n\ntags = { z = 1, y = 2 }\n"
+	filter, err := alignpkg.NewAddressFilter([]string{"resource.b.*"}, nil)
+	require.NoError(t, err)
+	file, diags := hclwrite.ParseConfig([]byte(src), "in.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	opts := &alignpkg.Options{Types: map[string]struct{}{}, Filter: filter, SortObjectKeys: true}
+	require.NoError(t, alignpkg.Apply(file, opts))
+	want := "resource \"a\" \"x\" {\n  tags = { z = 1, y = 2 }\n}\n\nresource \"b\" \"y\" {\n  tags = { y = 2, z = 1 }\n  nested {\n    tags = { y = 2, z = 1 }\n  }\n}\n\ntags = { z = 1, y = 2 }\n"
+	require.Equal(t, want, string(file.Bytes()))
+}
+
+func TestValidateAttrPatterns(t *testing.T) {
+	require.NoError(t, alignpkg.ValidateAttrPatterns([]string{"tags", "*_tags"}))
+	require.ErrorContains(t, alignpkg.ValidateAttrPatterns([]string{""}), "empty")
+	require.Error(t, alignpkg.ValidateAttrPatterns([]string{"tags["}))
+}
@@ -0,0 +1,37 @@
+// internal/align/layout_bench_test.go
+package align_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func benchmarkStress(b *testing.B, types map[string]struct{}) {
+	src, err := os.ReadFile(filepath.Join("..", "..", "tests", "cases", "stress", "in.tf"))
+	require.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		file, diags := hclwrite.ParseConfig(src, "in.tf", hcl.InitialPos)
+		if diags.HasErrors() {
+			b.Fatal(diags)
+		}
+		if err := alignpkg.Apply(file, &alignpkg.Options{Types: types}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAlignStress(b *testing.B) {
+	benchmarkStress(b, nil)
+}
+
+func BenchmarkParseStress(b *testing.B) {
+	benchmarkStress(b, map[string]struct{}{})
+}
@@ -0,0 +1,23 @@
+// internal/align/lists.go
+package align
+
+import (
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ihcl "github.com/oferchen/hclalign/internal/hcl"
+)
+
+var DefaultListAttrs = []string{"depends_on", "ignore_changes", "security_group_ids", "vpc_security_group_ids", "cidr_blocks", "ipv6_cidr_blocks"}
+
+func sortLists(body *hclwrite.Body, patterns []string) {
+	if len(patterns) == 0 {
+		patterns = DefaultListAttrs
+	}
+	for name, attr := range body.Attributes() {
+		if !attrMatches(patterns, name) {
+			continue
+		}
+		if toks, changed := ihcl.SortList(attr.Expr().BuildTokens(nil)); changed {
+			body.SetAttributeRaw(name, toks)
+		}
+	}
+}
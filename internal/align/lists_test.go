@@ -0,0 +1,51 @@
+// internal/align/lists_test.go
+package align_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortLists(t *testing.T) {
+	src := "resource \"r\" \"x\" {\n  depends_on = [b, a]\n  zones = [\"b\", \"a\"]\n  lifecycle {\n    ignore_changes = [tags, ami, tags]\n  }\n}\n"
+	tests := []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{
+			name: "default attributes",
+			want: "resource \"r\" \"x\" {\n  depends_on = [a, b]\n  zones      = [\"b\", \"a\"]\n  lifecycle {\n    ignore_changes = [ami, tags]\n  }\n}\n",
+		},
+		{
+			name:     "configured attributes",
+			patterns: []string{"zones"},
+			want:     "resource \"r\" \"x\" {\n  depends_on = [b, a]\n  zones      = [\"a\", \"b\"]\n  lifecycle {\n    ignore_changes = [tags, ami, tags]\n  }\n}\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(src), "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			opts := &alignpkg.Options{Types: map[string]struct{}{}, SortLists: true, ListAttrs: tc.patterns}
+			require.NoError(t, alignpkg.Apply(file, opts))
+			require.Equal(t, tc.want, string(file.Bytes()))
+		})
+	}
+}
+
+func TestSortListsOnly(t *testing.T) {
+	src := "resource \"a\" \"x\" {\n  depends_on = [b, a]\n}\n\nresource \"b\" \"y\" {\n  depends_on = [b, a]\n  lifecycle {\n    ignore_changes = [tags, ami]\n  }\n}\n"
+	filter, err := alignpkg.NewAddressFilter(nil, []string{"resource.a.*"})
+	require.NoError(t, err)
+	file, diags := hclwrite.ParseConfig([]byte(src), "in.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	opts := &alignpkg.Options{Types: map[string]struct{}{}, Filter: filter, SortLists: true}
+	require.NoError(t, alignpkg.Apply(file, opts))
+	want := "resource \"a\" \"x\" {\n  depends_on = [b, a]\n}\n\nresource \"b\" \"y\" {\n  depends_on = [a, b]\n  lifecycle {\n    ignore_changes = [ami, tags]\n  }\n}\n"
+	require.Equal(t, want, string(file.Bytes()))
+}
@@ -2,7 +2,6 @@
 package align
 
 import (
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	ihcl "github.com/oferchen/hclalign/internal/hcl"
 )
@@ -13,18 +12,10 @@ func (moduleStrategy) Name() string { return "module" }
 
 func (moduleStrategy) Align(block *hclwrite.Block, _ *Options) error {
 	body := block.Body()
-	attrs := body.Attributes()
 	canonical := CanonicalBlockAttrOrder["module"]
 
-	tokens := body.BuildTokens(nil)
-	newline := ihcl.DetectLineEnding(tokens)
-	trailingComma := ihcl.HasTrailingComma(tokens)
-
-	attrTokens := make(map[string]ihcl.AttrTokens, len(attrs))
-	for name, attr := range attrs {
-		attrTokens[name] = ihcl.ExtractAttrTokens(attr, nil)
-	}
-	blocks := body.Blocks()
+	layout := ihcl.NewLayout(body)
+	startTokens, attrTokens := layout.AttrTokens()
 
 	type segment struct {
 		attrs []string
@@ -32,92 +23,44 @@ func (moduleStrategy) Align(block *hclwrite.Block, _ *Options) error {
 	}
 	segments := []segment{}
 	current := []string{}
-	depth := 0
-	bi := 0
-	for i := 0; i < len(tokens); i++ {
-		tok := tokens[i]
-		switch tok.Type {
-		case hclsyntax.TokenOBrace, hclsyntax.TokenOParen:
-			depth++
-			continue
-		case hclsyntax.TokenCBrace, hclsyntax.TokenCParen:
-			if depth > 0 {
-				depth--
-			}
+	for _, it := range layout.Items {
+		if it.Kind == ihcl.ItemAttribute {
+			current = append(current, it.Name)
 			continue
 		}
-		if depth == 0 && tok.Type == hclsyntax.TokenIdent {
-			name := string(tok.Bytes)
-			if _, ok := attrs[name]; ok && tokens[i+1].Type == hclsyntax.TokenEqual {
-				current = append(current, name)
-				continue
-			}
-			if bi < len(blocks) {
-				if len(current) > 0 {
-					segments = append(segments, segment{attrs: current})
-					current = nil
-				}
-				segments = append(segments, segment{block: blocks[bi]})
-				btoks := blocks[bi].BuildTokens(nil)
-				bi++
-				i += len(btoks) - 1
-			}
+		if len(current) > 0 {
+			segments = append(segments, segment{attrs: current})
+			current = nil
 		}
+		segments = append(segments, segment{block: it.Block})
 	}
 	if len(current) > 0 {
 		segments = append(segments, segment{attrs: current})
 	}
 
-	for name := range attrs {
-		body.RemoveAttribute(name)
-	}
-	for _, b := range blocks {
-		body.RemoveBlock(b)
-	}
-	body.Clear()
-	body.AppendUnstructuredTokens(startTokens)
+	detachItems(body, layout)
+	w := ihcl.NewWriter(body, layout.Newline)
+	w.Tokens(startTokens)
 
-	if len(segments) > 0 {
-		body.AppendUnstructuredTokens(hclwrite.Tokens{
-			&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline},
-		})
-	}
 	for i, seg := range segments {
 		if i > 0 {
-			body.AppendUnstructuredTokens(hclwrite.Tokens{
-				&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline},
-			})
+			w.Newline()
 		}
 		if seg.block != nil {
-			body.AppendBlock(seg.block)
+			w.Block(seg.block)
 			continue
 		}
 		ordered := orderModuleAttrs(seg.attrs, canonical)
 		for _, name := range ordered {
 			tok := attrTokens[name]
-			body.AppendUnstructuredTokens(tok.PreTokens)
-			body.AppendUnstructuredTokens(tok.LeadTokens)
-			body.SetAttributeRaw(name, tok.ExprTokens)
-			body.AppendUnstructuredTokens(tok.PostTokens)
+			w.Tokens(tok.PreTokens)
+			w.Tokens(tok.LeadTokens)
+			w.Attr(name, tok.ExprTokens)
+			w.Tokens(tok.PostTokens)
 		}
 	}
 
-	if trailingComma && len(segments) > 0 {
-		body.AppendUnstructuredTokens(hclwrite.Tokens{
-			&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte{','}},
-		})
-	}
-	toks := body.BuildTokens(nil)
-	if len(toks) > 0 {
-		last := toks[len(toks)-1]
-		if last.Type != hclsyntax.TokenNewline {
-			if last.Type != hclsyntax.TokenComment || (len(last.Bytes) > 0 && last.Bytes[len(last.Bytes)-1] != '\n') {
-				body.AppendUnstructuredTokens(hclwrite.Tokens{
-					&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline},
-				})
-			}
-		}
-	}
+	w.Finish(layout.TrailingComma && len(segments) > 0)
 	return nil
 }
 
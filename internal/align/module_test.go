@@ -39,3 +39,22 @@ func TestModuleProvisionerAndProviders(t *testing.T) {
 }`
 	require.Equal(t, exp, got)
 }
+
+func TestModuleKeepsCallExpressions(t *testing.T) {
+	src := []byte(`module "example" {
+  count   = length(var.items)
+  source  = "./m"
+  version = "1.0.0"
+}
+`)
+	file, diags := hclwrite.ParseConfig(src, "in.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, alignpkg.Apply(file, &alignpkg.Options{}))
+	exp := `module "example" {
+  source  = "./m"
+  version = "1.0.0"
+  count   = length(var.items)
+}
+`
+	require.Equal(t, exp, string(file.Bytes()))
+}
@@ -24,16 +24,6 @@ func (outputStrategy) Align(block *hclwrite.Block, opts *Options) error {
 		reserved[name] = struct{}{}
 	}
 
-	original := ihcl.AttributeOrder(body, attrs)
-
-	nonCanonical := make([]string, 0)
-	for _, name := range original {
-		if _, ok := reserved[name]; !ok {
-			nonCanonical = append(nonCanonical, name)
-		}
-	}
-	order = append(order, nonCanonical...)
-
 	nested := body.Blocks()
 	if len(nested) > 0 {
 		pre := make([]*hclwrite.Block, 0)
@@ -58,7 +48,16 @@ func (outputStrategy) Align(block *hclwrite.Block, opts *Options) error {
 		}
 	}
 
-	return reorderBlock(block, order)
+	layout := ihcl.NewLayout(body)
+	nonCanonical := make([]string, 0)
+	for _, name := range layout.AttrNames() {
+		if _, ok := reserved[name]; !ok {
+			nonCanonical = append(nonCanonical, name)
+		}
+	}
+	order = append(order, nonCanonical...)
+
+	return reorderBlock(block, layout, order)
 }
 
 func init() { Register(outputStrategy{}) }
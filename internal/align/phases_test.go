@@ -13,6 +13,7 @@ import (
 	alignschema "github.com/oferchen/hclalign/internal/align/schema"
 	terraformfmt "github.com/oferchen/hclalign/internal/fmt"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/tool"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,14 +34,14 @@ func TestPhases(t *testing.T) {
 			wantOut, err := os.ReadFile(outPath)
 			require.NoError(t, err)
 
-			fmtBytes, _, err := terraformfmt.Format(context.Background(), inBytes, inPath, string(terraformfmt.StrategyGo))
+			fmtBytes, _, err := terraformfmt.Format(context.Background(), tool.Binary{}, inBytes, inPath, string(terraformfmt.StrategyGo))
 			require.NoError(t, err)
 
 			hadNewline := len(inBytes) > 0 && inBytes[len(inBytes)-1] == '\n'
 			if !hadNewline && len(fmtBytes) > 0 && fmtBytes[len(fmtBytes)-1] == '\n' {
 				fmtBytes = fmtBytes[:len(fmtBytes)-1]
 			}
-			againFmt, _, err := terraformfmt.Format(context.Background(), fmtBytes, inPath, string(terraformfmt.StrategyGo))
+			againFmt, _, err := terraformfmt.Format(context.Background(), tool.Binary{}, fmtBytes, inPath, string(terraformfmt.StrategyGo))
 			require.NoError(t, err)
 			hadFmtNewline := len(fmtBytes) > 0 && fmtBytes[len(fmtBytes)-1] == '\n'
 			if !hadFmtNewline && len(againFmt) > 0 && againFmt[len(againFmt)-1] == '\n' {
@@ -70,7 +71,7 @@ func TestPhases(t *testing.T) {
 	}
 
 	t.Run("error", func(t *testing.T) {
-		_, _, err := terraformfmt.Format(context.Background(), []byte("variable \"a\" {"), "bad.hcl", string(terraformfmt.StrategyGo))
+		_, _, err := terraformfmt.Format(context.Background(), tool.Binary{}, []byte("variable \"a\" {"), "bad.hcl", string(terraformfmt.StrategyGo))
 		require.Error(t, err)
 	})
 }
@@ -23,14 +23,15 @@ func (providerStrategy) Align(block *hclwrite.Block, _ *Options) error {
 		reserved[name] = struct{}{}
 	}
 
-	original := ihcl.AttributeOrder(block.Body(), attrs)
+	layout := ihcl.NewLayout(block.Body())
+	original := layout.AttrNames()
 	for _, name := range original {
 		if _, ok := reserved[name]; ok {
 			continue
 		}
 		names = append(names, name)
 	}
-	return reorderBlock(block, names)
+	return reorderBlock(block, layout, names)
 }
 
 func init() { Register(providerStrategy{}) }
@@ -7,109 +7,66 @@ import (
 	ihcl "github.com/oferchen/hclalign/internal/hcl"
 )
 
-func reorderBlock(block *hclwrite.Block, order []string) error {
+func reorderBlock(block *hclwrite.Block, layout *ihcl.Layout, order []string) error {
 	body := block.Body()
-	attrs := body.Attributes()
-	nestedBlocks := body.Blocks()
 
-	tokens := body.BuildTokens(nil)
-	newline := ihcl.DetectLineEnding(tokens)
-	trailingComma := ihcl.HasTrailingComma(tokens)
-
-	attrPre := map[string]hclwrite.Tokens{}
-	blockPre := make([]hclwrite.Tokens, len(nestedBlocks))
-	prefixTokens := hclwrite.Tokens{}
-	current := hclwrite.Tokens{}
-	prefixCaptured := false
-	blockIndex := 0
-	for i := 0; i < len(tokens); {
-		tok := tokens[i]
-		if tok.Type == hclsyntax.TokenIdent {
-			name := string(tok.Bytes)
-			if attr, ok := attrs[name]; ok && i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenEqual {
-				attrToks := attr.BuildTokens(nil)
-				leadCount := 0
-				for leadCount < len(attrToks) && attrToks[leadCount].Type == hclsyntax.TokenComment {
-					leadCount++
-				}
-				if !prefixCaptured {
-					prefixTokens = append(prefixTokens, current...)
-					prefixCaptured = true
-				} else if len(current) > leadCount {
-					attrPre[name] = append(hclwrite.Tokens{}, current[:len(current)-leadCount]...)
-				}
-				current = nil
-				i += len(attrToks) - leadCount
-				continue
-			}
-			if blockIndex < len(nestedBlocks) {
-				btoks := nestedBlocks[blockIndex].BuildTokens(nil)
-				leadCount := 0
-				for leadCount < len(btoks) && btoks[leadCount].Type == hclsyntax.TokenComment {
-					leadCount++
-				}
-				if !prefixCaptured {
-					prefixTokens = append(prefixTokens, current...)
-					prefixCaptured = true
-				} else if len(current) > leadCount {
-					blockPre[blockIndex] = append(hclwrite.Tokens{}, current[:len(current)-leadCount]...)
-				}
-				current = nil
-				i += len(btoks) - leadCount
-				blockIndex++
-				continue
-			}
-		}
-		current = append(current, tok)
-		i++
-	}
-	if !prefixCaptured {
-		prefixTokens = append(prefixTokens, current...)
+	prefixTokens := layout.Tail
+	var tailTokens hclwrite.Tokens
+	if len(layout.Items) > 0 {
+		prefixTokens = layout.Items[0].Before
+		tailTokens = layout.Tail
 	}
-
-	attrTokensMap := map[string]ihcl.AttrTokens{}
-	for name, attr := range attrs {
-		attrTokensMap[name] = ihcl.ExtractAttrTokens(attr, attrPre[name])
-	}
-
-	for name := range attrs {
-		body.RemoveAttribute(name)
-	}
-	for _, nb := range nestedBlocks {
-		body.RemoveBlock(nb)
+	attrItems := make(map[string]ihcl.Item, len(layout.Items))
+	var blockItems []ihcl.Item
+	for i, it := range layout.Items {
+		if i == 0 {
+			it.Before = nil
+		}
+		if it.Kind == ihcl.ItemAttribute {
+			attrItems[it.Name] = it
+		} else {
+			blockItems = append(blockItems, it)
+		}
 	}
 
-	body.Clear()
-	body.AppendUnstructuredTokens(prefixTokens)
+	detachItems(body, layout)
+	w := ihcl.NewWriter(body, layout.Newline)
+	w.Tokens(prefixTokens)
+	emitted := 0
 	for _, name := range order {
-		if tok, ok := attrTokensMap[name]; ok {
-			body.AppendUnstructuredTokens(tok.PreTokens)
-			body.AppendUnstructuredTokens(tok.LeadTokens)
-			body.SetAttributeRaw(name, tok.ExprTokens)
-			body.AppendUnstructuredTokens(tok.TrailTokens)
+		it, ok := attrItems[name]
+		if !ok {
+			continue
 		}
-	}
-	for i, nb := range nestedBlocks {
-		if pre := blockPre[i]; len(pre) > 0 {
-			body.AppendUnstructuredTokens(pre)
+		pre := it.Before
+		if emitted == 0 {
+			for len(pre) > 0 && pre[0].Type == hclsyntax.TokenNewline {
+				pre = pre[1:]
+			}
 		}
-		body.AppendBlock(nb)
+		w.Tokens(pre)
+		emitted++
+		w.Tokens(it.Lead)
+		w.Attr(name, it.Expr)
 	}
-	if trailingComma && (len(order) > 0 || len(nestedBlocks) > 0) {
-		body.AppendUnstructuredTokens(hclwrite.Tokens{
-			&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")},
-		})
-	}
-	toks := body.BuildTokens(nil)
-	if len(toks) > 0 {
-		last := toks[len(toks)-1]
-		if last.Type != hclsyntax.TokenNewline {
-			if last.Type != hclsyntax.TokenComment || (len(last.Bytes) > 0 && last.Bytes[len(last.Bytes)-1] != '\n') {
-				body.AppendUnstructuredTokens(hclwrite.Tokens{
-					&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline},
-				})
-			}
+	for _, it := range blockItems {
+		w.Tokens(it.Before)
+		if !w.EndsWithBlankLine() {
+			w.Newline()
 		}
+		w.Block(it.Block)
 	}
+	w.Tokens(tailTokens)
+	w.Finish(layout.TrailingComma && (len(order) > 0 || len(blockItems) > 0))
 	return nil
 }
+
+func detachItems(body *hclwrite.Body, layout *ihcl.Layout) {
+	for _, it := range layout.Items {
+		if it.Kind == ihcl.ItemAttribute {
+			body.RemoveAttribute(it.Name)
+		} else {
+			body.RemoveBlock(it.Block)
+		}
+	}
+}
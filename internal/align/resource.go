@@ -20,7 +20,8 @@ func init() { Register(resourceStrategy{}) }
 func schemaAwareOrder(block *hclwrite.Block, opts *Options) error {
 	body := block.Body()
 	attrs := body.Attributes()
-	originalOrder := ihcl.AttributeOrder(body, attrs)
+	layout := ihcl.NewLayout(body)
+	originalOrder := layout.AttrNames()
 
 	canonical := CanonicalBlockAttrOrder[block.Type()]
 	metaAttrs := make([]string, 0, len(canonical))
@@ -41,7 +42,7 @@ func schemaAwareOrder(block *hclwrite.Block, opts *Options) error {
 
 	if opts == nil || opts.Schema == nil {
 		order := append(metaAttrs, rest...)
-		return reorderBlock(block, order)
+		return reorderBlock(block, layout, order)
 	}
 
 	reqSet := map[string]struct{}{}
@@ -64,27 +65,32 @@ func schemaAwareOrder(block *hclwrite.Block, opts *Options) error {
 		unk = append(unk, name)
 	}
 
-	var req, opt, comp []string
-	for _, n := range opts.Schema.RequiredOrder {
-		if _, ok := reqSet[n]; ok {
-			req = append(req, n)
-		}
-	}
-	for _, n := range opts.Schema.OptionalOrder {
-		if _, ok := optSet[n]; ok {
-			opt = append(opt, n)
-		}
-	}
-	for _, n := range opts.Schema.ComputedOrder {
-		if _, ok := compSet[n]; ok {
-			comp = append(comp, n)
-		}
-	}
+	req := schemaOrder(opts.Schema.RequiredOrder, reqSet, rest)
+	opt := schemaOrder(opts.Schema.OptionalOrder, optSet, rest)
+	comp := schemaOrder(opts.Schema.ComputedOrder, compSet, rest)
 
 	order := append(metaAttrs, req...)
 	order = append(order, opt...)
 	order = append(order, comp...)
 	order = append(order, unk...)
 
-	return reorderBlock(block, order)
+	return reorderBlock(block, layout, order)
+}
+
+func schemaOrder(order []string, set map[string]struct{}, source []string) []string {
+	out := make([]string, 0, len(set))
+	placed := map[string]struct{}{}
+	for _, n := range order {
+		if _, ok := set[n]; ok {
+			out = append(out, n)
+			placed[n] = struct{}{}
+		}
+	}
+	for _, n := range source {
+		_, ok := set[n]
+		if _, done := placed[n]; ok && !done {
+			out = append(out, n)
+		}
+	}
+	return out
 }
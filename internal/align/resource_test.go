@@ -75,8 +75,8 @@ resource "null_resource" "n" {
 	got := string(file.Bytes())
 	exp := `resource "aws_s3_bucket" "b" {
   bucket = "b"
-  tags   = {}
   acl    = "private"
+  tags   = {}
   id     = "id"
 }
 
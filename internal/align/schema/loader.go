@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/oferchen/hclalign/internal/align"
+	"github.com/oferchen/hclalign/internal/tool"
 )
 
 func Load(r io.Reader) (map[string]*align.Schema, error) {
@@ -161,35 +162,37 @@ func LoadFile(path string) (map[string]*align.Schema, error) {
 
 var execCommandContext = exec.CommandContext
 
-func cacheKey(ctx context.Context, modulePath string) (string, error) {
-	cmd := execCommandContext(ctx, "terraform", "version", "-json")
+func cacheKey(ctx context.Context, bin tool.Binary, modulePath string) (string, error) {
+	cmd := execCommandContext(ctx, bin.Path, "version", "-json")
 	cmd.Dir = modulePath
 	out, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("terraform version: %w", err)
+		return "", fmt.Errorf("%s version: %w", bin.Name(), err)
 	}
-	var v struct {
-		TerraformVersion   string `json:"terraform_version"`
-		ProviderSelections map[string]struct {
-			Version string `json:"version"`
-		} `json:"provider_selections"`
-	}
-	if err := json.Unmarshal(out, &v); err != nil {
-		return "", err
+	v, err := tool.ParseVersion(out)
+	if err != nil {
+		return "", fmt.Errorf("%s version: %w", bin.Name(), err)
 	}
 	providers := make([]string, 0, len(v.ProviderSelections))
-	for name, sel := range v.ProviderSelections {
-		providers = append(providers, name+"@"+sel.Version)
+	for name, version := range v.ProviderSelections {
+		providers = append(providers, name+"@"+version)
 	}
 	sort.Strings(providers)
-	sum := sha256.Sum256([]byte(v.TerraformVersion + "|" + strings.Join(providers, ",") + "|" + modulePath))
+	sum := sha256.Sum256([]byte(string(v.Tool) + "|" + v.Version + "|" + strings.Join(providers, ",") + "|" + modulePath))
 	return hex.EncodeToString(sum[:]), nil
 }
 
 func FromTerraform(ctx context.Context, cacheDir, modulePath string, noCache bool) (map[string]*align.Schema, error) {
+	return FromTool(ctx, tool.Binary{Kind: tool.Terraform, Path: "terraform"}, cacheDir, modulePath, noCache)
+}
+
+func FromTool(ctx context.Context, bin tool.Binary, cacheDir, modulePath string, noCache bool) (map[string]*align.Schema, error) {
+	if !bin.Available() {
+		return nil, fmt.Errorf("%s binary not found", bin.Name())
+	}
 	var cachePath string
 	if !noCache {
-		key, err := cacheKey(ctx, modulePath)
+		key, err := cacheKey(ctx, bin, modulePath)
 		if err != nil {
 			return nil, err
 		}
@@ -201,11 +204,11 @@ func FromTerraform(ctx context.Context, cacheDir, modulePath string, noCache boo
 		}
 	}
 
-	cmd := execCommandContext(ctx, "terraform", "providers", "schema", "-json")
+	cmd := execCommandContext(ctx, bin.Path, "providers", "schema", "-json")
 	cmd.Dir = modulePath
 	out, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("terraform providers schema: %w", err)
+		return nil, fmt.Errorf("%s providers schema: %w", bin.Name(), err)
 	}
 	if !noCache && cachePath != "" {
 		if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err == nil {
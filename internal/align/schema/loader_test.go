@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/oferchen/hclalign/internal/tool"
 	"github.com/stretchr/testify/require"
 )
 
@@ -141,3 +142,32 @@ func TestFromTerraformNoCache(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, providersCalls)
 }
+
+func TestFromToolTofu(t *testing.T) {
+	dir := t.TempDir()
+	samplePath := filepath.Join(dir, "sample.json")
+	versionPath := filepath.Join(dir, "version.json")
+	require.NoError(t, os.WriteFile(samplePath, []byte(sample), 0o644))
+	version := `{"tofu_version":"1.8.0","platform":"linux_amd64","provider_selections":{"registry.opentofu.org/hashicorp/test":"1.0.0"}}`
+	require.NoError(t, os.WriteFile(versionPath, []byte(version), 0o644))
+
+	var names []string
+	orig := execCommandContext
+	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		names = append(names, name)
+		if len(args) > 0 && args[0] == "version" {
+			return exec.CommandContext(ctx, "cat", versionPath)
+		}
+		return exec.CommandContext(ctx, "cat", samplePath)
+	}
+	defer func() { execCommandContext = orig }()
+
+	bin := tool.Binary{Kind: tool.Tofu, Path: "/opt/tofu/bin/tofu"}
+	schemas, err := FromTool(context.Background(), bin, filepath.Join(dir, "cache"), dir, false)
+	require.NoError(t, err)
+	require.NotEmpty(t, schemas)
+	require.Equal(t, []string{bin.Path, bin.Path}, names)
+
+	_, err = FromTool(context.Background(), tool.Binary{Kind: tool.Tofu}, dir, dir, true)
+	require.ErrorContains(t, err, "tofu binary not found")
+}
@@ -0,0 +1,208 @@
+// internal/align/sort.go
+package align
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ihcl "github.com/oferchen/hclalign/internal/hcl"
+)
+
+type SortMode string
+
+const (
+	SortNatural       SortMode = "natural"
+	SortRequiredFirst SortMode = "required-first"
+	SortAsIs          SortMode = "as-is"
+)
+
+var DefaultBlockOrder = []string{"terraform", "provider", "variable", "locals", "data", "resource", "module", "output"}
+
+type BlockSort struct {
+	order map[string]int
+	modes map[string]SortMode
+}
+
+func NewBlockSort(order, modes []string) (*BlockSort, error) {
+	if len(order) == 0 {
+		order = DefaultBlockOrder
+	}
+	s := &BlockSort{order: make(map[string]int, len(order)), modes: map[string]SortMode{}}
+	for i, kind := range order {
+		if kind == "" {
+			return nil, fmt.Errorf("empty block kind in order")
+		}
+		if _, dup := s.order[kind]; dup {
+			return nil, fmt.Errorf("duplicate block kind %q in order", kind)
+		}
+		s.order[kind] = i
+	}
+	for _, spec := range modes {
+		kind, mode, ok := strings.Cut(spec, "=")
+		if !ok || kind == "" {
+			return nil, fmt.Errorf("invalid block sort %q: want kind=mode", spec)
+		}
+		switch SortMode(mode) {
+		case SortNatural, SortAsIs:
+		case SortRequiredFirst:
+			if kind != "variable" {
+				return nil, fmt.Errorf("invalid block sort %q: required-first only applies to variable", spec)
+			}
+		default:
+			return nil, fmt.Errorf("invalid block sort %q: mode must be natural, required-first or as-is", spec)
+		}
+		s.modes[kind] = SortMode(mode)
+	}
+	return s, nil
+}
+
+func (s *BlockSort) mode(kind string) SortMode {
+	if m, ok := s.modes[kind]; ok {
+		return m
+	}
+	return SortNatural
+}
+
+func (s *BlockSort) rank(kind string) int {
+	if i, ok := s.order[kind]; ok {
+		return i
+	}
+	return len(s.order)
+}
+
+func orderBlocks(body *hclwrite.Body, s *BlockSort, topo bool) {
+	if len(body.Attributes()) > 0 {
+		return
+	}
+	layout := ihcl.NewLayout(body)
+	if len(layout.Items) < 2 {
+		return
+	}
+	items := append([]ihcl.Item(nil), layout.Items...)
+	if s != nil {
+		sort.SliceStable(items, func(i, j int) bool {
+			return s.less(items[i].Block, items[j].Block)
+		})
+	}
+	if topo {
+		items = topoOrder(items)
+	}
+	moved := false
+	for i := range items {
+		if items[i].Block != layout.Items[i].Block {
+			moved = true
+			break
+		}
+	}
+	if !moved {
+		return
+	}
+
+	header := layout.Items[0].Before
+	first := layout.Items[0].Block
+	detachItems(body, layout)
+	w := ihcl.NewWriter(body, layout.Newline)
+	for i, it := range items {
+		before := it.Before
+		switch {
+		case i == 0:
+			w.Tokens(header)
+			if it.Block != first {
+				before = trimLeadingNewlines(before)
+			} else {
+				before = nil
+			}
+		case it.Block == first:
+			before = leadingNewlines(layout.Items[1].Before)
+		}
+		w.Tokens(before)
+		w.Block(it.Block)
+	}
+	w.Tokens(layout.Tail)
+}
+
+func (s *BlockSort) less(a, b *hclwrite.Block) bool {
+	ka, kb := a.Type(), b.Type()
+	if ra, rb := s.rank(ka), s.rank(kb); ra != rb {
+		return ra < rb
+	}
+	if ka != kb {
+		return false
+	}
+	switch s.mode(ka) {
+	case SortAsIs:
+		return false
+	case SortRequiredFirst:
+		ra, rb := a.Body().GetAttribute("default") == nil, b.Body().GetAttribute("default") == nil
+		if ra != rb {
+			return ra
+		}
+	}
+	return labelsLess(a.Labels(), b.Labels())
+}
+
+func labelsLess(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return naturalLess(a[i], b[i])
+		}
+	}
+	return len(a) < len(b)
+}
+
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		ca, cb := a[0], b[0]
+		if isDigit(ca) && isDigit(cb) {
+			na, ra := leadingDigits(a)
+			nb, rb := leadingDigits(b)
+			ta, tb := strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+			if len(ta) != len(tb) {
+				return len(ta) < len(tb)
+			}
+			if ta != tb {
+				return ta < tb
+			}
+			if na != nb {
+				return len(na) < len(nb)
+			}
+			a, b = ra, rb
+			continue
+		}
+		if ca != cb {
+			return ca < cb
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func trimLeadingNewlines(tokens hclwrite.Tokens) hclwrite.Tokens {
+	for len(tokens) > 0 && tokens[0].Type == hclsyntax.TokenNewline {
+		tokens = tokens[1:]
+	}
+	return tokens
+}
+
+func leadingNewlines(tokens hclwrite.Tokens) hclwrite.Tokens {
+	n := 0
+	for n < len(tokens) && tokens[n].Type == hclsyntax.TokenNewline {
+		n++
+	}
+	return tokens[:n]
+}
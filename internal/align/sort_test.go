@@ -0,0 +1,102 @@
+// internal/align/sort_test.go
+package align_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBlockSort(t *testing.T) {
+	_, err := alignpkg.NewBlockSort(nil, []string{"variable=required-first", "resource=as-is", "output=natural"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		order []string
+		modes []string
+		msg   string
+	}{
+		{name: "duplicate kind", order: []string{"variable", "variable"}, msg: `duplicate block kind "variable"`},
+		{name: "empty kind", order: []string{""}, msg: "empty block kind"},
+		{name: "missing mode", modes: []string{"variable"}, msg: "want kind=mode"},
+		{name: "unknown mode", modes: []string{"variable=random"}, msg: "mode must be natural, required-first or as-is"},
+		{name: "required-first elsewhere", modes: []string{"output=required-first"}, msg: "required-first only applies to variable"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := alignpkg.NewBlockSort(tc.order, tc.modes)
+			require.ErrorContains(t, err, tc.msg)
+		})
+	}
+}
+
+func TestSortBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+		modes []string
+		in    string
+		want  string
+	}{
+		{
+			name: "kind order and natural labels",
+			in:   "output \"o\" {}\n\nresource \"r\" \"n10\" {}\nresource \"r\" \"n9\" {}\nresource \"a\" \"z\" {}\nvariable \"v\" {}\n",
+			want: "variable \"v\" {}\nresource \"a\" \"z\" {}\nresource \"r\" \"n9\" {}\n\nresource \"r\" \"n10\" {}\n\noutput \"o\" {}\n",
+		},
+		{
+			name: "comments move with their block",
+			in:   "# header\n\n# about b\nvariable \"b\" {}\n\n# about a\nvariable \"a\" {}\n",
+			want: "# header\n\n# about a\nvariable \"a\" {}\n\n# about b\nvariable \"b\" {}\n",
+		},
+		{
+			name:  "required first",
+			modes: []string{"variable=required-first"},
+			in:    "variable \"a\" {\n  default = 1\n}\nvariable \"c\" {}\nvariable \"b\" {}\n",
+			want:  "variable \"b\" {}\nvariable \"c\" {}\nvariable \"a\" {\n  default = 1\n}\n",
+		},
+		{
+			name:  "as-is keeps order within kind",
+			modes: []string{"variable=as-is"},
+			in:    "output \"o\" {}\nvariable \"b\" {}\nvariable \"a\" {}\n",
+			want:  "variable \"b\" {}\nvariable \"a\" {}\noutput \"o\" {}\n",
+		},
+		{
+			name:  "custom order with unlisted kinds last",
+			order: []string{"output", "variable"},
+			in:    "moved {}\nvariable \"a\" {}\nimport {}\noutput \"o\" {}\n",
+			want:  "output \"o\" {}\nvariable \"a\" {}\nmoved {}\nimport {}\n",
+		},
+		{
+			name: "blocks keep their own separation",
+			in:   "output \"o\" {}\n\n\nvariable \"b\" {}\nvariable \"a\" {}\n\nlocals {}\n",
+			want: "variable \"a\" {}\n\n\nvariable \"b\" {}\n\nlocals {}\n\n\noutput \"o\" {}\n",
+		},
+		{
+			name: "top-level attributes are left alone",
+			in:   "b = 1\nvariable \"b\" {}\nvariable \"a\" {}\n",
+			want: "b = 1\nvariable \"b\" {}\nvariable \"a\" {}\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sorter, err := alignpkg.NewBlockSort(tc.order, tc.modes)
+			require.NoError(t, err)
+			opts := &alignpkg.Options{Types: map[string]struct{}{}, BlockSort: sorter}
+
+			file, diags := hclwrite.ParseConfig([]byte(tc.in), "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			require.NoError(t, alignpkg.Apply(file, opts))
+			got := file.Bytes()
+			require.Equal(t, tc.want, string(got))
+
+			again, diags := hclwrite.ParseConfig(got, "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			require.NoError(t, alignpkg.Apply(again, opts))
+			require.Equal(t, string(got), string(again.Bytes()))
+		})
+	}
+}
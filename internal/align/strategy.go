@@ -2,18 +2,38 @@
 package align
 
 import (
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	ihcl "github.com/oferchen/hclalign/internal/hcl"
 )
 
 type Options struct {
+	Path    string
 	Order   []string
 	Schemas map[string]*Schema
 
 	Schema *Schema
 
 	Types map[string]struct{}
+
+	Filter *AddressFilter
+
+	Spacing ihcl.Spacing
+
+	GroupSeparators bool
+
+	BlockSort *BlockSort
+	TopoSort  bool
+
+	SortObjectKeys bool
+	ObjectKeyAttrs []string
+	SortLists      bool
+	ListAttrs      []string
+
+	Trace func(strategy string) func()
 }
 
 type Schema struct {
@@ -36,21 +56,65 @@ type Strategy interface {
 	Align(block *hclwrite.Block, opts *Options) error
 }
 
-var registry = map[string]Strategy{}
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Strategy{}
+)
 
 func Register(s Strategy) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 	registry[s.Name()] = s
 }
 
+func Lookup(name string) (Strategy, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	s, ok := registry[name]
+	return s, ok
+}
+
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func Apply(file *hclwrite.File, opts *Options) error {
 	if opts == nil {
 		opts = &Options{}
 	}
-	return applyBody(file.Body(), opts)
+	if err := applyBody(file.Body(), opts, nil, !opts.Filter.Active()); err != nil {
+		return err
+	}
+	if opts.BlockSort != nil || opts.TopoSort {
+		orderBlocks(file.Body(), opts.BlockSort, opts.TopoSort)
+	}
+	return nil
 }
 
-func applyBody(body *hclwrite.Body, opts *Options) error {
+func applyBody(body *hclwrite.Body, opts *Options, parent []string, selected bool) error {
+	if selected && opts.SortObjectKeys {
+		sortObjectKeys(body, opts.ObjectKeyAttrs)
+	}
+	if selected && opts.SortLists {
+		sortLists(body, opts.ListAttrs)
+	}
 	for _, b := range body.Blocks() {
+		addr := blockAddress(parent, b)
+		decision := filterAlign
+		if opts.Filter.Active() {
+			decision = opts.Filter.decide(addr, selected)
+		}
+		if decision == filterExclude {
+			continue
+		}
+
 		sub := *opts
 		sub.Schema = nil
 		if len(b.Labels()) > 0 && opts.Schemas != nil {
@@ -71,20 +135,36 @@ func applyBody(body *hclwrite.Body, opts *Options) error {
 			}
 		}
 
-		if strategy, ok := registry[b.Type()]; ok {
+		if decision == filterDescend {
+			if err := applyBody(b.Body(), &sub, addr, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if strategy, ok := Lookup(b.Type()); ok {
 			if opts.Types != nil {
 				if _, ok := opts.Types[b.Type()]; !ok {
-					if err := applyBody(b.Body(), &sub); err != nil {
+					if err := applyBody(b.Body(), &sub, addr, true); err != nil {
 						return err
 					}
 					continue
 				}
 			}
-			if err := strategy.Align(b, &sub); err != nil {
+			done := func() {}
+			if opts.Trace != nil {
+				done = opts.Trace(strategy.Name())
+			}
+			err := strategy.Align(b, &sub)
+			if err == nil && opts.GroupSeparators {
+				separateGroups(b, sub.Schema)
+			}
+			done()
+			if err != nil {
 				return err
 			}
 		}
-		if err := applyBody(b.Body(), &sub); err != nil {
+		if err := applyBody(b.Body(), &sub, addr, true); err != nil {
 			return err
 		}
 	}
@@ -2,7 +2,6 @@
 package align
 
 import (
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	ihcl "github.com/oferchen/hclalign/internal/hcl"
 )
@@ -14,20 +13,11 @@ func (terraformStrategy) Name() string { return "terraform" }
 func (terraformStrategy) Align(block *hclwrite.Block, _ *Options) error {
 	body := block.Body()
 
-	attrs := body.Attributes()
-	blocks := body.Blocks()
-
-	tokens := body.BuildTokens(nil)
-	newline := ihcl.DetectLineEnding(tokens)
-	trailingComma := ihcl.HasTrailingComma(tokens)
-
-	order := ihcl.AttributeOrder(body, attrs)
-
-	attrTokens := map[string]ihcl.AttrTokens{}
-	for name, attr := range attrs {
-		attrTokens[name] = ihcl.ExtractAttrTokens(attr, nil)
-		body.RemoveAttribute(name)
-	}
+	layout := ihcl.NewLayout(body)
+	order := layout.AttrNames()
+	startTokens, attrTokens := layout.AttrTokens()
+	blocks := layout.Blocks()
+	detachItems(body, layout)
 
 	canonical := CanonicalBlockAttrOrder["terraform"]
 	canonicalSet := make(map[string]struct{}, len(canonical))
@@ -38,7 +28,6 @@ func (terraformStrategy) Align(block *hclwrite.Block, _ *Options) error {
 	blockByType := make(map[string]*hclwrite.Block, len(blocks))
 	var otherBlocks []*hclwrite.Block
 	for _, b := range blocks {
-		body.RemoveBlock(b)
 		if _, ok := canonicalSet[b.Type()]; ok {
 			blockByType[b.Type()] = b
 		} else {
@@ -47,9 +36,8 @@ func (terraformStrategy) Align(block *hclwrite.Block, _ *Options) error {
 	}
 
 	if rp := blockByType["required_providers"]; rp != nil {
-		rpAttrs := rp.Body().Attributes()
-		names := ihcl.AttributeOrder(rp.Body(), rpAttrs)
-		if err := reorderBlock(rp, names); err != nil {
+		rpLayout := ihcl.NewLayout(rp.Body())
+		if err := reorderBlock(rp, rpLayout, rpLayout.AttrNames()); err != nil {
 			return err
 		}
 	}
@@ -91,41 +79,23 @@ func (terraformStrategy) Align(block *hclwrite.Block, _ *Options) error {
 		items = append(items, item{block: b})
 	}
 
-	body.Clear()
-	body.AppendUnstructuredTokens(startTokens)
+	w := ihcl.NewWriter(body, layout.Newline)
+	w.Tokens(startTokens)
 	for _, it := range items {
 		if it.isAttr {
 			tok := attrTokens[it.name]
-			body.AppendUnstructuredTokens(tok.PreTokens)
-			body.AppendUnstructuredTokens(tok.LeadTokens)
-			body.SetAttributeRaw(it.name, tok.ExprTokens)
-			body.AppendUnstructuredTokens(tok.PostTokens)
+			w.Tokens(tok.PreTokens)
+			w.Tokens(tok.LeadTokens)
+			w.Attr(it.name, tok.ExprTokens)
+			w.Tokens(tok.PostTokens)
 		} else {
-			toks := body.BuildTokens(nil)
-			if len(toks) == 0 || toks[len(toks)-1].Type != hclsyntax.TokenNewline {
-				body.AppendUnstructuredTokens(hclwrite.Tokens{
-					&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline},
-				})
-			}
-			body.AppendBlock(it.block)
-		}
-	}
-	if trailingComma && len(items) > 0 {
-		body.AppendUnstructuredTokens(hclwrite.Tokens{
-			&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")},
-		})
-	}
-	toks := body.BuildTokens(nil)
-	if len(toks) > 0 {
-		last := toks[len(toks)-1]
-		if last.Type != hclsyntax.TokenNewline {
-			if last.Type != hclsyntax.TokenComment || (len(last.Bytes) > 0 && last.Bytes[len(last.Bytes)-1] != '\n') {
-				body.AppendUnstructuredTokens(hclwrite.Tokens{
-					&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline},
-				})
+			if !w.EndsWithBlankLine() {
+				w.Newline()
 			}
+			w.Block(it.block)
 		}
 	}
+	w.Finish(layout.TrailingComma && len(items) > 0)
 	return nil
 }
 
@@ -81,3 +81,32 @@ func TestTerraformBlocksOrderWithoutExperiments(t *testing.T) {
 }`
 	require.Equal(t, exp, string(file.Bytes()))
 }
+
+func TestTerraformEncryptionBlock(t *testing.T) {
+	src := []byte(`terraform {
+  encryption {
+    key_provider "pbkdf2" "main" {
+      passphrase = var.passphrase
+    }
+  }
+  other = 1
+  backend "s3" {}
+  required_version = ">= 1.7.0"
+}`)
+	file, diags := hclwrite.ParseConfig(src, "in.tofu", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, alignpkg.Apply(file, &alignpkg.Options{}))
+	exp := `terraform {
+  required_version = ">= 1.7.0"
+
+  backend "s3" {}
+
+  encryption {
+    key_provider "pbkdf2" "main" {
+      passphrase = var.passphrase
+    }
+  }
+  other = 1
+}`
+	require.Equal(t, exp, string(file.Bytes()))
+}
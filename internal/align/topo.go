@@ -0,0 +1,212 @@
+// internal/align/topo.go
+package align
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ihcl "github.com/oferchen/hclalign/internal/hcl"
+)
+
+func topoOrder(items []ihcl.Item) []ihcl.Item {
+	owners := map[string]int{}
+	parsed := make([]*hclsyntax.Block, len(items))
+	for i, it := range items {
+		parsed[i] = parseBlock(it.Block)
+		for _, key := range definedKeys(parsed[i]) {
+			if _, taken := owners[key]; !taken {
+				owners[key] = i
+			}
+		}
+	}
+	deps := make([][]int, len(items))
+	for i, block := range parsed {
+		seen := map[int]bool{}
+		for _, traversal := range blockTraversals(block) {
+			for _, key := range referenceKeys(traversal) {
+				j, ok := owners[key]
+				if !ok || j == i || seen[j] {
+					continue
+				}
+				seen[j] = true
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	comp := components(deps)
+	pending := make([]int, len(items))
+	dependents := make([][]int, len(items))
+	for i, ds := range deps {
+		for _, j := range ds {
+			if comp[i] == comp[j] {
+				continue
+			}
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	var ready []int
+	for i := range items {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	out := make([]ihcl.Item, 0, len(items))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		i := ready[0]
+		ready = ready[1:]
+		out = append(out, items[i])
+		for _, d := range dependents[i] {
+			pending[d]--
+			if pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	return out
+}
+
+func components(deps [][]int) []int {
+	n := len(deps)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	comp := make([]int, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	next, count := 0, 0
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range deps[v] {
+			if index[w] < 0 {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			comp[w] = count
+			if w == v {
+				break
+			}
+		}
+		count++
+	}
+	for v := 0; v < n; v++ {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+	return comp
+}
+
+func parseBlock(block *hclwrite.Block) *hclsyntax.Block {
+	file, diags := hclsyntax.ParseConfig(block.BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok || len(body.Blocks) != 1 {
+		return nil
+	}
+	return body.Blocks[0]
+}
+
+func definedKeys(block *hclsyntax.Block) []string {
+	if block == nil {
+		return nil
+	}
+	labels := block.Labels
+	switch {
+	case block.Type == "locals":
+		keys := make([]string, 0, len(block.Body.Attributes))
+		for name := range block.Body.Attributes {
+			keys = append(keys, "local."+name)
+		}
+		return keys
+	case block.Type == "variable" && len(labels) == 1:
+		return []string{"var." + labels[0]}
+	case block.Type == "module" && len(labels) == 1:
+		return []string{"module." + labels[0]}
+	case block.Type == "data" && len(labels) == 2:
+		return []string{"data." + labels[0] + "." + labels[1]}
+	case (block.Type == "resource" || block.Type == "ephemeral") && len(labels) == 2:
+		prefix := ""
+		if block.Type == "ephemeral" {
+			prefix = "ephemeral."
+		}
+		return []string{prefix + labels[0] + "." + labels[1]}
+	case block.Type == "provider" && len(labels) == 1:
+		if attr, ok := block.Body.Attributes["alias"]; ok {
+			if val, diags := attr.Expr.Value(nil); !diags.HasErrors() && val.Type().FriendlyName() == "string" {
+				return []string{labels[0] + "." + val.AsString()}
+			}
+		}
+		return []string{labels[0]}
+	}
+	return nil
+}
+
+func blockTraversals(block *hclsyntax.Block) []hcl.Traversal {
+	if block == nil {
+		return nil
+	}
+	var out []hcl.Traversal
+	var walk func(body *hclsyntax.Body)
+	walk = func(body *hclsyntax.Body) {
+		for _, attr := range body.Attributes {
+			out = append(out, hclsyntax.Variables(attr.Expr)...)
+		}
+		for _, nested := range body.Blocks {
+			walk(nested.Body)
+		}
+	}
+	walk(block.Body)
+	return out
+}
+
+func referenceKeys(traversal hcl.Traversal) []string {
+	names := []string{traversal.RootName()}
+	for _, step := range traversal[1:] {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			break
+		}
+		names = append(names, attr.Name)
+	}
+	keys := make([]string, 0, 2)
+	switch names[0] {
+	case "var", "local", "module":
+		if len(names) >= 2 {
+			keys = append(keys, names[0]+"."+names[1])
+		}
+	case "data", "ephemeral":
+		if len(names) >= 3 {
+			keys = append(keys, names[0]+"."+names[1]+"."+names[2])
+		}
+	default:
+		keys = append(keys, names[0])
+		if len(names) >= 2 {
+			keys = append(keys, names[0]+"."+names[1])
+		}
+	}
+	return keys
+}
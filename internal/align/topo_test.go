@@ -0,0 +1,78 @@
+// internal/align/topo_test.go
+package align_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopoSort(t *testing.T) {
+	tests := []struct {
+		name string
+		sort bool
+		in   string
+		want string
+	}{
+		{
+			name: "already ordered",
+			in:   "variable \"a\" {}\nresource \"r\" \"x\" {\n  v = var.a\n}\n",
+			want: "variable \"a\" {}\nresource \"r\" \"x\" {\n  v = var.a\n}\n",
+		},
+		{
+			name: "references move after their targets",
+			in:   "output \"o\" {\n  value = module.m.id\n}\n\nmodule \"m\" {\n  id = data.d.x.id\n}\n\ndata \"d\" \"x\" {\n  name = local.n\n}\n\nlocals {\n  n = var.a\n}\n\nvariable \"a\" {}\n",
+			want: "variable \"a\" {}\n\nlocals {\n  n = var.a\n}\n\ndata \"d\" \"x\" {\n  name = local.n\n}\n\nmodule \"m\" {\n  id = data.d.x.id\n}\n\noutput \"o\" {\n  value = module.m.id\n}\n",
+		},
+		{
+			name: "original order breaks ties",
+			in:   "resource \"r\" \"b\" {\n  v = r.a.id\n}\n\nvariable \"z\" {}\n\nresource \"r\" \"a\" {}\n\nvariable \"y\" {}\n",
+			want: "variable \"z\" {}\n\nresource \"r\" \"a\" {}\n\nresource \"r\" \"b\" {\n  v = r.a.id\n}\n\nvariable \"y\" {}\n",
+		},
+		{
+			name: "nested blocks and for expressions",
+			in:   "resource \"r\" \"b\" {\n  dynamic \"d\" {\n    for_each = [for x in var.xs : x]\n  }\n}\n\nvariable \"xs\" {}\n\nvariable \"x\" {}\n",
+			want: "variable \"xs\" {}\n\nresource \"r\" \"b\" {\n  dynamic \"d\" {\n    for_each = [for x in var.xs : x]\n  }\n}\n\nvariable \"x\" {}\n",
+		},
+		{
+			name: "cycles stay in place",
+			in:   "locals {\n  a = local.b\n}\n\nvariable \"v\" {}\n\nlocals {\n  b = local.a\n}\n",
+			want: "locals {\n  a = local.b\n}\n\nvariable \"v\" {}\n\nlocals {\n  b = local.a\n}\n",
+		},
+		{
+			name: "provider aliases",
+			in:   "resource \"r\" \"x\" {\n  provider = aws.west\n}\n\nprovider \"aws\" {\n  alias = \"west\"\n}\n",
+			want: "provider \"aws\" {\n  alias = \"west\"\n}\n\nresource \"r\" \"x\" {\n  provider = aws.west\n}\n",
+		},
+		{
+			name: "combined with kind sort",
+			sort: true,
+			in:   "variable \"b\" {\n  default = var.a\n}\n\nvariable \"c\" {}\n\nvariable \"a\" {\n  default = var.c\n}\n",
+			want: "variable \"c\" {}\n\nvariable \"a\" {\n  default = var.c\n}\n\nvariable \"b\" {\n  default = var.a\n}\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &alignpkg.Options{Types: map[string]struct{}{}, TopoSort: true}
+			if tc.sort {
+				sorter, err := alignpkg.NewBlockSort(nil, nil)
+				require.NoError(t, err)
+				opts.BlockSort = sorter
+			}
+
+			file, diags := hclwrite.ParseConfig([]byte(tc.in), "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			require.NoError(t, alignpkg.Apply(file, opts))
+			got := file.Bytes()
+			require.Equal(t, tc.want, string(got))
+
+			again, diags := hclwrite.ParseConfig(got, "in.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors())
+			require.NoError(t, alignpkg.Apply(again, opts))
+			require.Equal(t, string(got), string(again.Bytes()))
+		})
+	}
+}
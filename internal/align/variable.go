@@ -54,88 +54,32 @@ func init() {
 func reorderVariableBlock(block *hclwrite.Block, order []string, canonicalOrder []string, canonicalSet map[string]struct{}, validationPos int) error {
 	body := block.Body()
 
-	attrs := body.Attributes()
-	attrOrder := make([]string, 0, len(attrs))
-	nestedBlocks := body.Blocks()
+	layout := ihcl.NewLayout(body)
+	newline := layout.Newline
+	attrOrder := layout.AttrNames()
+	nestedBlocks := layout.Blocks()
 
-	allTokens := body.BuildTokens(nil)
-	newline := ihcl.DetectLineEnding(allTokens)
-	prefixTokens := hclwrite.Tokens{}
+	prefixTokens := splitComments(layout.Tail, newline)
 	var tailTokens hclwrite.Tokens
 	blockLeadTokens := make(map[*hclwrite.Block]hclwrite.Tokens)
-	attrLeadTrim := make(map[string]int)
-	attrExtraLead := make(map[string]hclwrite.Tokens)
-	currentTokens := hclwrite.Tokens{}
-	prefixCaptured := false
-	blockIndex := 0
-	capturedComments := 0
-	for i := 0; i < len(allTokens); {
-		tok := allTokens[i]
-		if tok.Type == hclsyntax.TokenComment && !prefixCaptured {
-			cpy := *tok
-			if bytes.HasSuffix(cpy.Bytes, newline) {
-				cpy.Bytes = cpy.Bytes[:len(cpy.Bytes)-len(newline)]
-				prefixTokens = append(prefixTokens, &cpy)
-				prefixTokens = append(prefixTokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline})
-			} else {
-				prefixTokens = append(prefixTokens, &cpy)
+	attrTokensMap := make(map[string]ihcl.AttrTokens)
+	for i, it := range layout.Items {
+		if i == 0 {
+			prefixTokens = splitComments(it.Before, newline)
+			tailTokens = layout.Tail
+			it.Before = nil
+		}
+		if it.Kind == ihcl.ItemBlock {
+			if i > 0 {
+				blockLeadTokens[it.Block] = it.Before
 			}
-			capturedComments++
-			i++
 			continue
 		}
-		if tok.Type == hclsyntax.TokenIdent {
-			name := string(tok.Bytes)
-			if attr, ok := attrs[name]; ok && i+1 < len(allTokens) && allTokens[i+1].Type == hclsyntax.TokenEqual {
-				attrOrder = append(attrOrder, name)
-				attrToks := attr.BuildTokens(nil)
-				leadCount := 0
-				for leadCount < len(attrToks) && attrToks[leadCount].Type == hclsyntax.TokenComment {
-					leadCount++
-				}
-				if !prefixCaptured {
-					prefixTokens = append(prefixTokens, currentTokens...)
-					if capturedComments > 0 {
-						attrLeadTrim[name] = capturedComments
-						capturedComments = 0
-					}
-					prefixCaptured = true
-				} else if len(currentTokens) > 0 && leadCount == 0 {
-					attrExtraLead[name] = append(hclwrite.Tokens{}, currentTokens...)
-				}
-				currentTokens = nil
-				i += len(attrToks) - leadCount
-				continue
-			}
-			if blockIndex < len(nestedBlocks) {
-				blockToks := nestedBlocks[blockIndex].BuildTokens(nil)
-				leadCount := 0
-				for leadCount < len(blockToks) && blockToks[leadCount].Type == hclsyntax.TokenComment {
-					leadCount++
-				}
-				if !prefixCaptured {
-					prefixTokens = append(prefixTokens, currentTokens...)
-					capturedComments = 0
-					prefixCaptured = true
-				} else {
-					if len(currentTokens) >= leadCount {
-						blockLeadTokens[nestedBlocks[blockIndex]] = append(hclwrite.Tokens{}, currentTokens[:len(currentTokens)-leadCount]...)
-					}
-				}
-				currentTokens = nil
-				i += len(blockToks) - leadCount
-				blockIndex++
-				continue
-			}
+		attrTokensMap[it.Name] = ihcl.AttrTokens{
+			LeadTokens: append(append(hclwrite.Tokens{}, it.Before...), it.Lead...),
+			ExprTokens: it.Expr,
 		}
-		currentTokens = append(currentTokens, tok)
-		i++
-	}
-	if !prefixCaptured {
-		prefixTokens = append(prefixTokens, currentTokens...)
-		currentTokens = nil
 	}
-	tailTokens = currentTokens
 
 	ihcl.NormalizeTokens(prefixTokens)
 	for _, lead := range blockLeadTokens {
@@ -143,9 +87,6 @@ func reorderVariableBlock(block *hclwrite.Block, order []string, canonicalOrder
 	}
 	ihcl.NormalizeTokens(tailTokens)
 
-	for _, nb := range nestedBlocks {
-		body.RemoveBlock(nb)
-	}
 	validationBlocks := make([]*hclwrite.Block, 0)
 	otherBlocks := make([]*hclwrite.Block, 0)
 	for _, nb := range nestedBlocks {
@@ -156,29 +97,9 @@ func reorderVariableBlock(block *hclwrite.Block, order []string, canonicalOrder
 		}
 	}
 
-	attrTokensMap := make(map[string]ihcl.AttrTokens)
-	for name, attr := range attrs {
-		at := ihcl.ExtractAttrTokens(attr, nil)
-		if extra, ok := attrExtraLead[name]; ok {
-			at.LeadTokens = append(extra, at.LeadTokens...)
-		}
-		if trim := attrLeadTrim[name]; trim > 0 {
-			if trim < len(at.LeadTokens) {
-				at.LeadTokens = at.LeadTokens[trim:]
-			} else {
-				at.LeadTokens = nil
-			}
-		}
-		attrTokensMap[name] = at
-	}
-
-	for name := range attrs {
-		body.RemoveAttribute(name)
-	}
-
-	body.Clear()
-	body.AppendUnstructuredTokens(startTokens)
-	body.AppendUnstructuredTokens(prefixTokens)
+	detachItems(body, layout)
+	w := ihcl.NewWriter(body, newline)
+	w.Tokens(prefixTokens)
 
 	canonicalOrderSet := map[string]struct{}{}
 	orderedKnown := make([]string, 0, len(canonicalOrder))
@@ -216,28 +137,16 @@ func reorderVariableBlock(block *hclwrite.Block, order []string, canonicalOrder
 	insertedValidation := false
 	for i, name := range orderedKnown {
 		if !insertedValidation && validationPos != -1 && i == validationPos {
-			for _, nb := range validationBlocks {
-				if lead, ok := blockLeadTokens[nb]; ok {
-					body.AppendUnstructuredTokens(lead)
-				}
-				body.AppendBlock(nb)
-			}
+			emitBlocks(w, validationBlocks, blockLeadTokens)
 			insertedValidation = true
 		}
 		if tok, ok := attrTokensMap[name]; ok {
-			body.AppendUnstructuredTokens(tok.PreTokens)
-			body.AppendUnstructuredTokens(tok.LeadTokens)
-			body.SetAttributeRaw(name, tok.ExprTokens)
-			body.AppendUnstructuredTokens(tok.PostTokens)
+			w.Tokens(tok.LeadTokens)
+			w.Attr(name, tok.ExprTokens)
 		}
 	}
 	if !insertedValidation && validationPos != -1 {
-		for _, nb := range validationBlocks {
-			if lead, ok := blockLeadTokens[nb]; ok {
-				body.AppendUnstructuredTokens(lead)
-			}
-			body.AppendBlock(nb)
-		}
+		emitBlocks(w, validationBlocks, blockLeadTokens)
 		insertedValidation = true
 	}
 
@@ -249,38 +158,47 @@ func reorderVariableBlock(block *hclwrite.Block, order []string, canonicalOrder
 		}
 	}
 	if !hasLeadingNewline && len(nestedBlocks) == 0 && (len(tailTokens) == 0 || tailTokens[0].Type != hclsyntax.TokenNewline) {
-		toks := body.BuildTokens(nil)
-		if n := len(toks); n > 0 && toks[n-1].Type == hclsyntax.TokenNewline {
-			body.Clear()
-			body.AppendUnstructuredTokens(toks[:n-1])
-		}
+		w.TrimNewline()
 	}
 
 	for _, name := range unknown {
 		if tok, ok := attrTokensMap[name]; ok {
-			body.AppendUnstructuredTokens(tok.PreTokens)
-			body.AppendUnstructuredTokens(tok.LeadTokens)
-			body.SetAttributeRaw(name, tok.ExprTokens)
-			body.AppendUnstructuredTokens(tok.PostTokens)
+			w.Tokens(tok.LeadTokens)
+			w.Attr(name, tok.ExprTokens)
 		}
 	}
 
 	if !insertedValidation {
-		for _, nb := range validationBlocks {
-			if lead, ok := blockLeadTokens[nb]; ok {
-				body.AppendUnstructuredTokens(lead)
-			}
-			body.AppendBlock(nb)
-		}
+		emitBlocks(w, validationBlocks, blockLeadTokens)
 	}
 
-	for _, nb := range otherBlocks {
-		if lead, ok := blockLeadTokens[nb]; ok {
-			body.AppendUnstructuredTokens(lead)
+	emitBlocks(w, otherBlocks, blockLeadTokens)
+	w.Tokens(tailTokens)
+
+	return nil
+}
+
+func splitComments(tokens hclwrite.Tokens, newline []byte) hclwrite.Tokens {
+	out := make(hclwrite.Tokens, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenComment {
+			out = append(out, tok)
+			continue
+		}
+		cpy := *tok
+		if bytes.HasSuffix(cpy.Bytes, newline) {
+			cpy.Bytes = cpy.Bytes[:len(cpy.Bytes)-len(newline)]
+			out = append(out, &cpy, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline})
+			continue
 		}
-		body.AppendBlock(nb)
+		out = append(out, &cpy)
 	}
-	body.AppendUnstructuredTokens(tailTokens)
+	return out
+}
 
-	return nil
+func emitBlocks(w *ihcl.Writer, blocks []*hclwrite.Block, leads map[*hclwrite.Block]hclwrite.Tokens) {
+	for _, nb := range blocks {
+		w.Tokens(leads[nb])
+		w.Block(nb)
+	}
 }
@@ -0,0 +1,35 @@
+// internal/align/variable_test.go
+package align_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	alignpkg "github.com/oferchen/hclalign/internal/align"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableLeadCommentBeforeBlock(t *testing.T) {
+	src := []byte("variable \"a\" {\n  # c\n  validation {\n    condition = true\n  }\n}\n")
+	file, diags := hclwrite.ParseConfig(src, "in.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, alignpkg.Apply(file, &alignpkg.Options{}))
+	require.Equal(t, string(src), string(file.Bytes()))
+}
+
+func TestVariableLeadCommentMovesWithAttribute(t *testing.T) {
+	src := []byte("variable \"b\" {\n  # about default\n  default = 1\n  description = \"x\"\n}\n")
+	file, diags := hclwrite.ParseConfig(src, "in.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, alignpkg.Apply(file, &alignpkg.Options{}))
+	require.Equal(t, "variable \"b\" {\n  description = \"x\"\n  # about default\n  default = 1\n}\n", string(file.Bytes()))
+}
+
+func TestVariableHeaderCommentStaysFirst(t *testing.T) {
+	src := []byte("variable \"b\" {\n  # header\n\n  default = 1\n  description = \"x\"\n}\n")
+	file, diags := hclwrite.ParseConfig(src, "in.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, alignpkg.Apply(file, &alignpkg.Options{}))
+	require.Equal(t, "variable \"b\" {\n  # header\n\n  description = \"x\"\n  default     = 1\n}\n", string(file.Bytes()))
+}
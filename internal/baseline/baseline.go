@@ -0,0 +1,194 @@
+// internal/baseline/baseline.go
+package baseline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+const (
+	Version     = 1
+	DefaultPath = ".hclalign-baseline.json"
+)
+
+type Entry struct {
+	File        string   `json:"file"`
+	Address     string   `json:"address"`
+	Attributes  []string `json:"attributes"`
+	Fingerprint string   `json:"fingerprint"`
+}
+
+func NewEntry(file, address string, attrs []string) Entry {
+	attrs = append([]string{}, attrs...)
+	sort.Strings(attrs)
+	sum := sha256.Sum256([]byte(file + "\x00" + address + "\x00" + strings.Join(attrs, "\x00")))
+	return Entry{File: file, Address: address, Attributes: attrs, Fingerprint: hex.EncodeToString(sum[:12])}
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("%s: %s", e.File, e.Address)
+}
+
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+func New(entries []Entry) *Baseline {
+	sorted := append([]Entry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	return &Baseline{Version: Version, Entries: sorted}
+}
+
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("baseline %s has unsupported version %d", path, b.Version)
+	}
+	return &b, nil
+}
+
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
+
+func (b *Baseline) Diff(found []Entry, files []string) (fresh, fixed []Entry) {
+	remaining := make(map[string]int, len(b.Entries))
+	for _, e := range b.Entries {
+		remaining[e.Fingerprint]++
+	}
+	for _, e := range found {
+		if remaining[e.Fingerprint] > 0 {
+			remaining[e.Fingerprint]--
+			continue
+		}
+		fresh = append(fresh, e)
+	}
+	scanned := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		scanned[f] = struct{}{}
+	}
+	for _, e := range b.Entries {
+		if _, ok := scanned[e.File]; !ok || remaining[e.Fingerprint] == 0 {
+			continue
+		}
+		remaining[e.Fingerprint]--
+		fixed = append(fixed, e)
+	}
+	return fresh, fixed
+}
+
+func Rel(baseDir, path string) string {
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(absBase, absPath)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func Violations(file string, before, after []byte) ([]Entry, error) {
+	want, err := topLevelBlocks(before)
+	if err != nil {
+		return nil, fmt.Errorf("parse original: %w", err)
+	}
+	got, err := topLevelBlocks(after)
+	if err != nil {
+		return nil, fmt.Errorf("parse output: %w", err)
+	}
+	seen := map[string]int{}
+	var entries []Entry
+	for _, w := range want {
+		n := seen[w.address]
+		seen[w.address]++
+		if g := nthBlock(got, w.address, n); g != nil && bytes.Equal(w.src, g.src) {
+			continue
+		}
+		entries = append(entries, NewEntry(file, w.address, w.attrs))
+	}
+	return entries, nil
+}
+
+type block struct {
+	address string
+	attrs   []string
+	src     []byte
+}
+
+func nthBlock(blocks []block, address string, n int) *block {
+	for i := range blocks {
+		if blocks[i].address != address {
+			continue
+		}
+		if n == 0 {
+			return &blocks[i]
+		}
+		n--
+	}
+	return nil
+}
+
+func topLevelBlocks(src []byte) ([]block, error) {
+	file, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", file.Body)
+	}
+	blocks := make([]block, 0, len(body.Blocks))
+	for _, b := range body.Blocks {
+		attrs := make([]string, 0, len(b.Body.Attributes))
+		for name := range b.Body.Attributes {
+			attrs = append(attrs, name)
+		}
+		rng := b.Range()
+		blocks = append(blocks, block{
+			address: strings.Join(append([]string{b.Type}, b.Labels...), "."),
+			attrs:   attrs,
+			src:     src[rng.Start.Byte:rng.End.Byte],
+		})
+	}
+	return blocks, nil
+}
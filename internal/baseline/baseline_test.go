@@ -0,0 +1,97 @@
+// internal/baseline/baseline_test.go
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntryStable(t *testing.T) {
+	a := NewEntry("main.tf", "variable.a", []string{"type", "description"})
+	b := NewEntry("main.tf", "variable.a", []string{"description", "type"})
+	require.Equal(t, a, b)
+	require.Equal(t, []string{"description", "type"}, a.Attributes)
+	require.Len(t, a.Fingerprint, 24)
+	require.NotEqual(t, a.Fingerprint, NewEntry("other.tf", "variable.a", a.Attributes).Fingerprint)
+	require.NotEqual(t, a.Fingerprint, NewEntry("main.tf", "variable.a", []string{"type"}).Fingerprint)
+	require.Equal(t, "main.tf: variable.a", a.String())
+}
+
+func TestViolations(t *testing.T) {
+	before := []byte(`variable "a" {
+  type        = string
+  description = "d"
+}
+
+variable "b" {
+  description = "d"
+  type        = string
+}
+
+locals {
+  x = 1
+}
+`)
+	after := []byte(`variable "a" {
+  description = "d"
+  type        = string
+}
+
+variable "b" {
+  description = "d"
+  type        = string
+}
+
+locals {
+  x = 1
+}
+`)
+	entries, err := Violations("main.tf", before, after)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{NewEntry("main.tf", "variable.a", []string{"description", "type"})}, entries)
+
+	_, err = Violations("main.tf", []byte("a = {"), after)
+	require.Error(t, err)
+	_, err = Violations("main.tf", before, []byte("a = {"))
+	require.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	a := NewEntry("a.tf", "variable.a", []string{"type"})
+	b := NewEntry("b.tf", "variable.b", []string{"type"})
+	c := NewEntry("c.tf", "variable.c", []string{"type"})
+	base := New([]Entry{a, b, c})
+
+	fresh, fixed := base.Diff([]Entry{a, NewEntry("a.tf", "variable.z", nil)}, []string{"a.tf", "b.tf"})
+	require.Equal(t, []Entry{NewEntry("a.tf", "variable.z", nil)}, fresh)
+	require.Equal(t, []Entry{b}, fixed)
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	b := New([]Entry{NewEntry("b.tf", "variable.b", nil), NewEntry("a.tf", "variable.a", []string{"type"})})
+	require.Equal(t, "a.tf", b.Entries[0].File)
+	require.NoError(t, b.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, b.Entries[0], loaded.Entries[0])
+	require.Equal(t, Version, loaded.Version)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0o644))
+	_, err = Load(path)
+	require.ErrorContains(t, err, "unsupported version")
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+	_, err = Load(path)
+	require.ErrorContains(t, err, "parse baseline")
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "read baseline")
+}
+
+func TestRel(t *testing.T) {
+	dir := t.TempDir()
+	require.Equal(t, "mod/main.tf", Rel(dir, filepath.Join(dir, "mod", "main.tf")))
+}
@@ -0,0 +1,185 @@
+// internal/diag/diag.go
+package diag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+)
+
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+type Range struct {
+	Filename string `json:"filename"`
+	Start    Pos    `json:"start"`
+	End      Pos    `json:"end"`
+}
+
+const CodeParse = "parse"
+
+type Diagnostic struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+	Range    *Range `json:"range,omitempty"`
+}
+
+func FromHCL(path string, diags hcl.Diagnostics) []Diagnostic {
+	out := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		item := Diagnostic{Code: CodeParse, Severity: severity(d.Severity), Summary: d.Summary, Detail: d.Detail}
+		if d.Subject != nil {
+			item.Range = &Range{
+				Filename: path,
+				Start:    Pos{Line: d.Subject.Start.Line, Column: d.Subject.Start.Column, Byte: d.Subject.Start.Byte},
+				End:      Pos{Line: d.Subject.End.Line, Column: d.Subject.End.Column, Byte: d.Subject.End.Byte},
+			}
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func severity(s hcl.DiagnosticSeverity) string {
+	if s == hcl.DiagWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+type Error struct {
+	Path  string
+	Src   []byte
+	Diags hcl.Diagnostics
+	Color bool
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.Path == "stdin" || e.located() {
+		b.WriteString("parsing error:\n\n")
+	} else {
+		fmt.Fprintf(&b, "parsing error in file %s:\n\n", e.Path)
+	}
+	_ = Render(&b, e.Path, e.Src, e.Diags, e.Color)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (e *Error) located() bool {
+	for _, d := range e.Diags {
+		if d.Subject != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Error) Unwrap() error { return e.Diags }
+
+func (e *Error) Diagnostics() []Diagnostic { return FromHCL(e.Path, e.Diags) }
+
+func Render(w io.Writer, path string, src []byte, diags hcl.Diagnostics, color bool) error {
+	for i, d := range diags {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := render(w, path, src, d, color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func render(w io.Writer, path string, src []byte, d *hcl.Diagnostic, color bool) error {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+	label, tint := "Error", ansiRed
+	if d.Severity == hcl.DiagWarning {
+		label, tint = "Warning", ansiYellow
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", paint(ansiBold+tint, label+":"), paint(ansiBold, d.Summary))
+	if d.Subject != nil {
+		start := d.Subject.Start
+		fmt.Fprintf(&b, "\n  on %s:%d:%d:\n", path, start.Line, start.Column)
+		if line, prefix, width, ok := snippet(src, d.Subject); ok {
+			num := strconv.Itoa(start.Line)
+			gutter := strings.Repeat(" ", len(num))
+			fmt.Fprintf(&b, "  %s | %s\n", num, line)
+			fmt.Fprintf(&b, "  %s | %s%s\n", gutter, prefix, paint(tint, "^"+strings.Repeat("~", width-1)))
+		}
+	}
+	if d.Detail != "" {
+		fmt.Fprintf(&b, "\n%s\n", d.Detail)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func snippet(src []byte, rng *hcl.Range) (line, prefix string, width int, ok bool) {
+	startByte := rng.Start.Byte
+	if startByte < 0 || startByte > len(src) {
+		return "", "", 0, false
+	}
+	lineStart := bytes.LastIndexByte(src[:startByte], '\n') + 1
+	lineEnd := len(src)
+	if i := bytes.IndexByte(src[startByte:], '\n'); i >= 0 {
+		lineEnd = startByte + i
+	}
+	text := bytes.TrimRight(src[lineStart:lineEnd], "\r")
+	if startByte > lineStart+len(text) {
+		startByte = lineStart + len(text)
+	}
+	var pad strings.Builder
+	for _, r := range string(src[lineStart:startByte]) {
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	endByte := rng.End.Byte
+	if endByte > lineStart+len(text) {
+		endByte = lineStart + len(text)
+	}
+	width = 1
+	if endByte > startByte {
+		width = utf8.RuneCount(src[startByte:endByte])
+	}
+	return string(text), pad.String(), width, true
+}
+
+type colorKey struct{}
+
+func WithColor(ctx context.Context, on bool) context.Context {
+	return context.WithValue(ctx, colorKey{}, on)
+}
+
+func ColorFromContext(ctx context.Context) bool {
+	on, _ := ctx.Value(colorKey{}).(bool)
+	return on
+}
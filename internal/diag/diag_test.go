@@ -0,0 +1,150 @@
+// internal/diag/diag_test.go
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, src string) hcl.Diagnostics {
+	t.Helper()
+	_, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	require.True(t, diags.HasErrors())
+	return diags
+}
+
+func TestRender(t *testing.T) {
+	src := "variable \"a\" {\n"
+	var b strings.Builder
+	require.NoError(t, Render(&b, "main.tf", []byte(src), parse(t, src), false))
+	require.Equal(t, `Error: Unclosed configuration block
+
+  on main.tf:1:14:
+  1 | variable "a" {
+    |              ^
+
+There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.
+`, b.String())
+}
+
+func TestRenderUnderlineAndTabs(t *testing.T) {
+	src := []byte("a = 1\n\tb = @@ + 1\n")
+	rng := &hcl.Range{
+		Start: hcl.Pos{Line: 2, Column: 6, Byte: 11},
+		End:   hcl.Pos{Line: 2, Column: 8, Byte: 13},
+	}
+	diags := hcl.Diagnostics{{Severity: hcl.DiagWarning, Summary: "Odd token", Subject: rng}}
+	var b strings.Builder
+	require.NoError(t, Render(&b, "main.tf", src, diags, false))
+	require.Equal(t, "Warning: Odd token\n\n  on main.tf:2:6:\n  2 | \tb = @@ + 1\n    | \t    ^~\n", b.String())
+
+	b.Reset()
+	require.NoError(t, Render(&b, "main.tf", src, diags, true))
+	require.Contains(t, b.String(), ansiBold+ansiYellow+"Warning:"+ansiReset)
+	require.Contains(t, b.String(), ansiYellow+"^~"+ansiReset)
+}
+
+func TestRenderWithoutSubject(t *testing.T) {
+	diags := hcl.Diagnostics{{Severity: hcl.DiagError, Summary: "Broken", Detail: "More."}}
+	var b strings.Builder
+	require.NoError(t, Render(&b, "main.tf", nil, diags, false))
+	require.Equal(t, "Error: Broken\n\nMore.\n", b.String())
+}
+
+func TestError(t *testing.T) {
+	src := "variable \"a\" {\n"
+	diags := parse(t, src)
+	err := &Error{Path: "main.tf", Src: []byte(src), Diags: diags}
+	require.True(t, strings.HasPrefix(err.Error(), "parsing error:\n\nError: Unclosed configuration block\n\n  on main.tf:1:14:"))
+	require.Equal(t, 1, strings.Count(err.Error(), "main.tf"))
+	require.NotContains(t, err.Error(), "\x1b[")
+
+	var target hcl.Diagnostics
+	require.True(t, errors.As(err, &target))
+	require.Equal(t, diags, target)
+
+	stdin := &Error{Path: "stdin", Src: []byte(src), Diags: diags}
+	require.True(t, strings.HasPrefix(stdin.Error(), "parsing error:\n\n"))
+
+	unlocated := &Error{Path: "main.tf", Diags: hcl.Diagnostics{{Severity: hcl.DiagError, Summary: "Broken"}}}
+	require.Equal(t, "parsing error in file main.tf:\n\nError: Broken", unlocated.Error())
+}
+
+func TestDiagnosticsJSON(t *testing.T) {
+	src := "variable \"a\" {\n"
+	err := &Error{Path: "main.tf", Src: []byte(src), Diags: parse(t, src)}
+	data, jerr := json.Marshal(err.Diagnostics())
+	require.NoError(t, jerr)
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Len(t, got, 1)
+	require.Equal(t, "error", got[0]["severity"])
+	require.Equal(t, "Unclosed configuration block", got[0]["summary"])
+	require.NotEmpty(t, got[0]["detail"])
+	rng := got[0]["range"].(map[string]any)
+	require.Equal(t, "main.tf", rng["filename"])
+	require.Equal(t, map[string]any{"line": 1.0, "column": 14.0, "byte": 13.0}, rng["start"])
+}
+
+func TestColorContext(t *testing.T) {
+	require.False(t, ColorFromContext(context.Background()))
+	require.True(t, ColorFromContext(WithColor(context.Background(), true)))
+}
+
+func TestReport(t *testing.T) {
+	var nilReport *Report
+	nilReport.Add(Diagnostic{Summary: "ignored"})
+	require.Nil(t, ReportFromContext(context.Background()))
+
+	report := NewReport()
+	require.Same(t, report, ReportFromContext(WithReport(context.Background(), report)))
+	warning := Diagnostic{
+		Code:     "comment-moved",
+		Severity: "warning",
+		Summary:  "Comment changed owner",
+		Detail:   "moved",
+		Range:    &Range{Filename: "b.tf", Start: Pos{Line: 2, Column: 3, Byte: 17}, End: Pos{Line: 2, Column: 15, Byte: 29}},
+	}
+	report.Add(warning)
+	parsed := FromHCL("a.tf", parse(t, "variable \"a\" {\n"))
+	report.Add(parsed...)
+	require.Equal(t, append(parsed, warning), report.Diagnostics())
+
+	var out strings.Builder
+	require.NoError(t, report.Write(&out, "json"))
+	var doc struct {
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &doc))
+	require.Equal(t, report.Diagnostics(), doc.Diagnostics)
+
+	out.Reset()
+	require.NoError(t, NewReport().Write(&out, ""))
+	require.JSONEq(t, `{"diagnostics": []}`, out.String())
+
+	out.Reset()
+	require.NoError(t, report.Write(&out, "sarif"))
+	var sarif map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &sarif))
+	require.Equal(t, "2.1.0", sarif["version"])
+	run := sarif["runs"].([]any)[0].(map[string]any)
+	require.Equal(t, "hclalign", run["tool"].(map[string]any)["driver"].(map[string]any)["name"])
+	results := run["results"].([]any)
+	require.Len(t, results, 2)
+	result := results[1].(map[string]any)
+	require.Equal(t, "comment-moved", result["ruleId"])
+	require.Equal(t, "warning", result["level"])
+	require.Equal(t, "Comment changed owner\n\nmoved", result["message"].(map[string]any)["text"])
+	location := result["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)
+	require.Equal(t, map[string]any{"uri": "b.tf"}, location["artifactLocation"])
+	require.Equal(t, map[string]any{"startLine": 2.0, "startColumn": 3.0, "endLine": 2.0, "endColumn": 15.0}, location["region"])
+
+	require.ErrorContains(t, report.Write(&out, "xml"), `unknown report format "xml": must be json or sarif`)
+}
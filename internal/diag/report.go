@@ -0,0 +1,190 @@
+// internal/diag/report.go
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+)
+
+func ValidateFormat(format string) error {
+	switch Format(format) {
+	case "", FormatJSON, FormatSARIF:
+		return nil
+	default:
+		return fmt.Errorf("unknown report format %q: must be json or sarif", format)
+	}
+}
+
+type Report struct {
+	mu    sync.Mutex
+	diags []Diagnostic
+}
+
+func NewReport() *Report {
+	return &Report{}
+}
+
+func (r *Report) Add(diags ...Diagnostic) {
+	if r == nil || len(diags) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.diags = append(r.diags, diags...)
+}
+
+func (r *Report) Diagnostics() []Diagnostic {
+	r.mu.Lock()
+	out := append([]Diagnostic{}, r.diags...)
+	r.mu.Unlock()
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Range, out[j].Range
+		switch {
+		case a == nil || b == nil:
+			return a == nil && b != nil
+		case a.Filename != b.Filename:
+			return a.Filename < b.Filename
+		case a.Start.Line != b.Start.Line:
+			return a.Start.Line < b.Start.Line
+		default:
+			return a.Start.Column < b.Start.Column
+		}
+	})
+	return out
+}
+
+func (r *Report) Write(w io.Writer, format string) error {
+	if err := ValidateFormat(format); err != nil {
+		return err
+	}
+	var doc any = struct {
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}{Diagnostics: r.Diagnostics()}
+	if Format(format) == FormatSARIF {
+		doc = sarif(r.Diagnostics())
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func (r *Report) WriteFile(path, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create report: %w", err)
+	}
+	if err := r.Write(f, format); err != nil {
+		f.Close()
+		return fmt.Errorf("write report: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func sarif(diags []Diagnostic) sarifLog {
+	results := make([]sarifResult, 0, len(diags))
+	for _, d := range diags {
+		text := d.Summary
+		if d.Detail != "" {
+			text += "\n\n" + d.Detail
+		}
+		res := sarifResult{RuleID: d.Code, Level: d.Severity, Message: sarifMessage{Text: text}}
+		if d.Range != nil {
+			res.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.Range.Filename)},
+				Region: sarifRegion{
+					StartLine:   d.Range.Start.Line,
+					StartColumn: d.Range.Start.Column,
+					EndLine:     d.Range.End.Line,
+					EndColumn:   d.Range.End.Column,
+				},
+			}}}
+		}
+		results = append(results, res)
+	}
+	return sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "hclalign", InformationURI: "https://github.com/oferchen/hclalign"}},
+			Results: results,
+		}},
+	}
+}
+
+type reportKey struct{}
+
+func WithReport(ctx context.Context, r *Report) context.Context {
+	return context.WithValue(ctx, reportKey{}, r)
+}
+
+func ReportFromContext(ctx context.Context) *Report {
+	r, _ := ctx.Value(reportKey{}).(*Report)
+	return r
+}
@@ -27,8 +27,8 @@ func Unified(opts UnifiedOpts) (string, error) {
 		styled = append(append([]byte{}, bom...), styled...)
 	}
 	ud := difflib.UnifiedDiff{
-		A:        difflib.SplitLines(string(original)),
-		B:        difflib.SplitLines(string(styled)),
+		A:        splitLines(original),
+		B:        splitLines(styled),
 		FromFile: opts.FromFile,
 		ToFile:   opts.ToFile,
 		Context:  diffContext,
@@ -43,3 +43,10 @@ func Unified(opts UnifiedOpts) (string, error) {
 	}
 	return out, nil
 }
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	return difflib.SplitLines(string(b))
+}
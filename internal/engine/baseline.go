@@ -0,0 +1,73 @@
+// internal/engine/baseline.go
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/baseline"
+)
+
+type violationCollector struct {
+	baseDir string
+	mu      sync.Mutex
+	files   []string
+	entries []baseline.Entry
+}
+
+func (c *violationCollector) Verify(_ context.Context, res *Result) error {
+	entries, err := baseline.Violations(baseline.Rel(c.baseDir, res.Doc.Path), res.Parsed, res.Formatted)
+	if err != nil {
+		return fmt.Errorf("baseline: %w", err)
+	}
+	c.mu.Lock()
+	c.entries = append(c.entries, entries...)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *violationCollector) scanned() []string {
+	files := make([]string, len(c.files))
+	for i, f := range c.files {
+		files[i] = baseline.Rel(c.baseDir, f)
+	}
+	return files
+}
+
+func processWithBaseline(ctx context.Context, cfg *config.Config) (bool, error) {
+	b, err := baseline.Load(cfg.Baseline)
+	if err != nil {
+		return false, err
+	}
+	collector := &violationCollector{baseDir: filepath.Dir(cfg.Baseline)}
+	_, err = processFiles(ctx, cfg, collector)
+	fresh, fixed := b.Diff(baseline.New(collector.entries).Entries, collector.scanned())
+
+	warnMu.Lock()
+	defer warnMu.Unlock()
+	for _, e := range fixed {
+		fmt.Fprintf(warnOut, "baseline entry fixed, prune it: %s\n", e)
+	}
+	for _, e := range fresh {
+		fmt.Fprintf(warnOut, "new violation not in baseline: %s\n", e)
+	}
+	return len(fresh) > 0, err
+}
+
+func GenerateBaseline(ctx context.Context, cfg *config.Config, path string) (*baseline.Baseline, error) {
+	gen := *cfg
+	gen.Mode = config.ModeCheck
+	gen.Stdout = false
+	collector := &violationCollector{baseDir: filepath.Dir(path)}
+	if _, err := processFiles(ctx, &gen, collector); err != nil {
+		return nil, err
+	}
+	b := baseline.New(collector.entries)
+	if err := b.Save(path); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,61 @@
+// internal/engine/baseline_test.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaselineGateAndPrune(t *testing.T) {
+	var warnings bytes.Buffer
+	orig := warnOut
+	t.Cleanup(func() { warnOut = orig })
+	warnOut = &warnings
+
+	dir := t.TempDir()
+	misordered := "variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n\nvariable \"b\" {\n  type        = string\n  description = \"d\"\n}\n"
+	aPath := filepath.Join(dir, "a.tf")
+	bPath := filepath.Join(dir, "b.tf")
+	require.NoError(t, os.WriteFile(aPath, []byte(misordered), 0o644))
+	require.NoError(t, os.WriteFile(bPath, []byte("variable \"c\" {\n  description = \"d\"\n  type        = string\n}\n"), 0o644))
+
+	basePath := filepath.Join(dir, ".hclalign-baseline.json")
+	cfg := &config.Config{Target: dir, Mode: config.ModeCheck, Include: config.DefaultInclude, Concurrency: 1}
+	b, err := GenerateBaseline(context.Background(), cfg, basePath)
+	require.NoError(t, err)
+	require.Len(t, b.Entries, 2)
+	require.Equal(t, "a.tf", b.Entries[0].File)
+	require.Equal(t, "variable.a", b.Entries[0].Address)
+	require.Equal(t, "variable.b", b.Entries[1].Address)
+
+	cfg.Baseline = basePath
+	changed, err := Process(context.Background(), cfg)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Empty(t, warnings.String())
+
+	require.NoError(t, os.WriteFile(bPath, []byte("variable \"c\" {\n  type        = string\n  description = \"d\"\n}\n"), 0o644))
+	changed, err = Process(context.Background(), cfg)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "new violation not in baseline: b.tf: variable.c\n", warnings.String())
+
+	warnings.Reset()
+	require.NoError(t, os.WriteFile(bPath, []byte("variable \"c\" {\n  description = \"d\"\n  type        = string\n}\n"), 0o644))
+	fixedA := "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n\nvariable \"b\" {\n  type        = string\n  description = \"d\"\n}\n"
+	require.NoError(t, os.WriteFile(aPath, []byte(fixedA), 0o644))
+	changed, err = Process(context.Background(), cfg)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Equal(t, "baseline entry fixed, prune it: a.tf: variable.a\n", warnings.String())
+
+	cfg.Baseline = filepath.Join(dir, "missing.json")
+	_, err = Process(context.Background(), cfg)
+	require.ErrorContains(t, err, "read baseline")
+}
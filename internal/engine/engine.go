@@ -2,42 +2,86 @@
 package engine
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclwrite"
+	"path"
+	"path/filepath"
 
 	"github.com/oferchen/hclalign/config"
-	"github.com/oferchen/hclalign/internal/align"
-	"github.com/oferchen/hclalign/internal/diff"
-	terraformfmt "github.com/oferchen/hclalign/internal/fmt"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/trace"
 )
 
 var (
 	testHookAfterParse   func()
 	testHookAfterReorder func()
-	WriteFileAtomic      = internalfs.WriteFileAtomic
 )
 
 func Process(ctx context.Context, cfg *config.Config) (bool, error) {
 	if cfg.Stdin {
 		return processReader(ctx, os.Stdin, os.Stdout, cfg)
 	}
-	return processFiles(ctx, cfg)
+	if cfg.Baseline != "" && cfg.Mode != config.ModeWrite {
+		return processWithBaseline(ctx, cfg)
+	}
+	return processFiles(ctx, cfg, nil)
+}
+
+type workspace struct {
+	fsys internalfs.WritableFS
+	root string
+}
+
+func filesystem(cfg *config.Config) workspace {
+	if cfg.FS != nil {
+		return workspace{fsys: cfg.FS}
+	}
+	return hostWorkspace(cfg.Target)
+}
+
+func hostWorkspace(path string) workspace {
+	root, err := internalfs.HostRoot(path)
+	if err != nil {
+		root = string(filepath.Separator)
+	}
+	return workspace{fsys: internalfs.NewOSFS(root), root: root}
+}
+
+func (w workspace) name(p string) string {
+	if w.root == "" {
+		return path.Clean(filepath.ToSlash(p))
+	}
+	name, err := internalfs.HostName(w.root, p)
+	if err != nil {
+		return p
+	}
+	return name
+}
+
+func (w workspace) path(name string) string {
+	if w.root == "" {
+		return name
+	}
+	return filepath.Join(w.root, filepath.FromSlash(name))
 }
 
-func processFiles(ctx context.Context, cfg *config.Config) (bool, error) {
+func processFiles(ctx context.Context, cfg *config.Config, collector *violationCollector) (bool, error) {
+	done := trace.Start(ctx, "scan", cfg.Target)
 	files, err := scan(ctx, cfg)
+	done()
 	if err != nil {
 		return false, err
 	}
-	outs, changed, errs := runPipeline(ctx, cfg, files)
+	if cfg.Restructure {
+		return restructureFiles(ctx, cfg, files)
+	}
+	if collector != nil {
+		collector.files = files
+	}
+	outs, changed, errs := runPipeline(ctx, cfg, files, collector)
 
 	for i, f := range files {
 		if out, ok := outs[f]; ok && len(out) > 0 {
@@ -72,85 +116,36 @@ func processReader(ctx context.Context, r io.Reader, w io.Writer, cfg *config.Co
 		w = os.Stdout
 	}
 
-	data, hints, err := internalfs.ReadAllWithHints(r)
+	schemas, err := loadSchemas(ctx, cfg)
 	if err != nil {
 		return false, err
 	}
-
-	original := append([]byte(nil), data...)
-	originalStyled := internalfs.ApplyHints(internalfs.PrepareForParse(original, hints), hints)
-	hadNewline := len(data) > 0 && data[len(data)-1] == '\n'
-
-	formatted, _, err := terraformfmt.Run(ctx, data)
+	filter, err := addressFilter(cfg)
 	if err != nil {
-		return false, fmt.Errorf("parsing error: %w", err)
-	}
-
-	parseData := internalfs.PrepareForParse(formatted, hints)
-	file, diags := hclwrite.ParseConfig(parseData, "stdin", hcl.InitialPos)
-	if diags.HasErrors() {
-		return false, fmt.Errorf("parsing error: %v", diags.Errs())
-	}
-	if testHookAfterParse != nil {
-		testHookAfterParse()
+		return false, err
 	}
-	schemas, err := loadSchemas(ctx, cfg)
+	defer reportFilter(filter)
+	sorter, err := blockSort(cfg)
 	if err != nil {
 		return false, err
 	}
-	var typesMap map[string]struct{}
-	if cfg.Types != nil {
-		typesMap = make(map[string]struct{}, len(cfg.Types))
-		for _, t := range cfg.Types {
-			typesMap[t] = struct{}{}
-		}
-	}
-	if err := align.Apply(file, &align.Options{Schemas: schemas, Types: typesMap}); err != nil {
+
+	bin, err := toolBinary(cfg)
+	if err != nil {
 		return false, err
 	}
-	if testHookAfterReorder != nil {
-		testHookAfterReorder()
-	}
-
-	formatted, _, err = terraformfmt.Run(ctx, file.Bytes())
+	format, err := formatterFor(cfg, bin, nil)
 	if err != nil {
 		return false, err
 	}
-
-	if !hadNewline && len(formatted) > 0 && formatted[len(formatted)-1] == '\n' {
-		formatted = formatted[:len(formatted)-1]
+	p := &Processor{cfg: cfg, schemas: schemas, filter: filter, sorter: sorter, format: format}
+	changed, out, err := p.pipeline(false).Run(ctx, ReaderSource{Name: "stdin", Reader: r})
+	if err != nil {
+		return false, err
 	}
-
-	styled := internalfs.ApplyHints(append([]byte(nil), formatted...), hints)
-	changed := !bytes.Equal(originalStyled, styled)
-
-	switch cfg.Mode {
-	case config.ModeDiff:
-		if changed {
-			styledForDiff := styled
-			originalForDiff := originalStyled
-			if hints.HasBOM {
-				bom := hints.BOM()
-				if len(styledForDiff) >= len(bom) {
-					styledForDiff = styledForDiff[len(bom):]
-				}
-				if len(originalForDiff) >= len(bom) {
-					originalForDiff = originalForDiff[len(bom):]
-				}
-			}
-			text, err := diff.Unified(diff.UnifiedOpts{FromFile: "stdin", ToFile: "stdin", Original: originalForDiff, Styled: styledForDiff, Hints: hints})
-			if err != nil {
-				return false, err
-			}
-			if _, err := fmt.Fprint(w, text); err != nil {
-				return false, err
-			}
-		}
-	default:
-		if cfg.Stdout {
-			if err := internalfs.WriteAllWithHints(w, formatted, hints); err != nil {
-				return changed, err
-			}
+	if len(out) > 0 {
+		if _, err := w.Write(out); err != nil {
+			return changed, err
 		}
 	}
 	return changed, nil
@@ -11,7 +11,7 @@ import (
 	"testing"
 
 	"github.com/oferchen/hclalign/config"
-	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/diag"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,15 +27,17 @@ func TestProcessInvalidHCLFile(t *testing.T) {
 		Concurrency: 1,
 	}
 
-	origFmt := terraformFmtFormatFile
-	terraformFmtFormatFile = func(ctx context.Context, p string) ([]byte, internalfs.Hints, bool, error) {
-		return nil, internalfs.Hints{}, false, fmt.Errorf("boom")
-	}
-	defer func() { terraformFmtFormatFile = origFmt }()
-
-	changed, err := Process(context.Background(), cfg)
+	report := diag.NewReport()
+	changed, err := Process(diag.WithReport(context.Background(), report), cfg)
 	require.Error(t, err)
-	require.Contains(t, err.Error(), fmt.Sprintf("parsing error in file %s", path))
+	require.True(t, strings.HasPrefix(err.Error(), "parsing error:\n\n"))
+	require.Contains(t, err.Error(), fmt.Sprintf("on %s:1:14:", path))
+	require.Equal(t, 1, strings.Count(err.Error(), path))
+	require.Contains(t, err.Error(), "  1 | variable \"a\" {\n    |              ^")
+	var diagErr *diag.Error
+	require.ErrorAs(t, err, &diagErr)
+	require.Equal(t, "Unclosed configuration block", diagErr.Diagnostics()[0].Summary)
+	require.Equal(t, diagErr.Diagnostics(), report.Diagnostics())
 	require.False(t, changed)
 
 	data, err := os.ReadFile(path)
@@ -65,8 +67,8 @@ func TestProcessAggregatesErrors(t *testing.T) {
 	changed, err := Process(context.Background(), cfg)
 	require.Error(t, err)
 	require.False(t, changed)
-	require.Contains(t, err.Error(), "bad1.tf")
-	require.Contains(t, err.Error(), "bad2.tf")
+	require.Equal(t, 1, strings.Count(err.Error(), bad1))
+	require.Equal(t, 1, strings.Count(err.Error(), bad2))
 
 	data, readErr := os.ReadFile(goodPath)
 	require.NoError(t, readErr)
@@ -0,0 +1,92 @@
+// internal/engine/fmt_batch_test.go
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/stretchr/testify/require"
+)
+
+const countingTerraform = `#!/bin/sh
+echo "x $*" >> "$HCLALIGN_FAKE_LOG"
+for a; do last=$a; done
+if [ "$last" = "-" ]; then
+  cat
+fi
+`
+
+func countingBinary(tb testing.TB) (string, func() int) {
+	tb.Helper()
+	dir := tb.TempDir()
+	script := filepath.Join(dir, "terraform")
+	require.NoError(tb, os.WriteFile(script, []byte(countingTerraform), 0o755))
+	logPath := filepath.Join(dir, "calls.log")
+	tb.Setenv("HCLALIGN_FAKE_LOG", logPath)
+	return script, func() int {
+		data, err := os.ReadFile(logPath)
+		if os.IsNotExist(err) {
+			return 0
+		}
+		require.NoError(tb, err)
+		return strings.Count(string(data), "\n")
+	}
+}
+
+func batchTree(tb testing.TB, files int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	for i := 0; i < files; i++ {
+		src := fmt.Sprintf("variable \"v%d\" {\n  type = string\n  description = \"d\"\n}\n", i)
+		require.NoError(tb, os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.tf", i)), []byte(src), 0o644))
+	}
+	return dir
+}
+
+func defaultBatchConfig(dir, bin string) *config.Config {
+	return &config.Config{
+		Target:       dir,
+		Mode:         config.ModeCheck,
+		Include:      config.DefaultInclude,
+		Exclude:      config.DefaultExclude,
+		Concurrency:  runtime.GOMAXPROCS(0),
+		FmtWorkers:   runtime.NumCPU(),
+		Types:        []string{"variable"},
+		TerraformBin: bin,
+	}
+}
+
+func TestProcessBatchesFmtAcrossFiles(t *testing.T) {
+	bin, calls := countingBinary(t)
+	dir := batchTree(t, 16)
+	cfg := defaultBatchConfig(dir, bin)
+	cfg.Concurrency = 1
+
+	changed, err := Process(context.Background(), cfg)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Less(t, calls(), 16)
+}
+
+func BenchmarkProcess(b *testing.B) {
+	for _, n := range []int{1, 64, 512} {
+		b.Run(fmt.Sprintf("files=%d", n), func(b *testing.B) {
+			bin, calls := countingBinary(b)
+			cfg := defaultBatchConfig(batchTree(b, n), bin)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := Process(context.Background(), cfg); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(calls())/float64(b.N), "fmt-calls/op")
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// internal/engine/golden_test.go
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/tool"
+	"github.com/stretchr/testify/require"
+)
+
+func goldenConfig(mode config.Mode, mutate func(*config.Config)) *config.Config {
+	cfg := &config.Config{
+		Mode:             mode,
+		Stdout:           true,
+		Types:            []string{"variable"},
+		FmtStrategy:      "go",
+		VerifyIdempotent: true,
+	}
+	if mutate != nil {
+		mutate(cfg)
+	}
+	return cfg
+}
+
+func runFile(t *testing.T, cfg *config.Config, path string) (bool, []byte) {
+	t.Helper()
+	format, err := formatterFor(cfg, tool.Binary{}, nil)
+	require.NoError(t, err)
+	sorter, err := blockSort(cfg)
+	require.NoError(t, err)
+	p := &Processor{cfg: cfg, sorter: sorter, format: format}
+	changed, out, err := p.pipeline(true).Run(context.Background(), FileSource(path))
+	require.NoError(t, err)
+	return changed, out
+}
+
+func sortBlocks(modes ...string) func(*config.Config) {
+	return func(cfg *config.Config) {
+		cfg.SortBlocks = true
+		cfg.BlockSort = modes
+	}
+}
+
+func noFmt(cfg *config.Config) {
+	cfg.Types = nil
+	cfg.FmtStrategy = ""
+	cfg.VerifyIdempotent = false
+	cfg.NoFmt = true
+	cfg.Order = config.CanonicalOrder
+}
+
+func TestGolden(t *testing.T) {
+	heredocs := filepath.Join("..", "..", "tests", "cases", "heredocs")
+	normalizeHeredocs := func(cfg *config.Config) {
+		cfg.Types = nil
+		cfg.HeredocIndent = true
+		cfg.HeredocMarker = "EOT"
+		cfg.HeredocInline = true
+	}
+	cases := []struct {
+		name   string
+		dir    string
+		in     string
+		want   string
+		mutate func(*config.Config)
+	}{
+		{name: "lists", dir: filepath.Join("testdata", "lists"), mutate: func(cfg *config.Config) {
+			cfg.SortLists = true
+			cfg.ListAttrs = config.DefaultListAttrs
+		}},
+		{name: "objectkeys", dir: filepath.Join("testdata", "objectkeys"), mutate: func(cfg *config.Config) {
+			cfg.SortObjectKeys = true
+			cfg.ObjectKeyAttrs = []string{"tags", "labels"}
+		}},
+		{name: "groups", dir: filepath.Join("testdata", "groups"), mutate: func(cfg *config.Config) {
+			cfg.Types = []string{"resource", "data", "module"}
+			cfg.GroupSeparators = true
+		}},
+		{name: "spacing", dir: filepath.Join("testdata", "spacing"), mutate: func(cfg *config.Config) {
+			cfg.MaxBlankLines = 1
+			cfg.SeparateBlocks = true
+			cfg.TrimBraces = true
+		}},
+		{name: "heredocs/go", dir: heredocs, want: "normalized.tf", mutate: normalizeHeredocs},
+		{name: "heredocs/none", dir: heredocs, in: "out.tf", want: "normalized.tf", mutate: func(cfg *config.Config) {
+			normalizeHeredocs(cfg)
+			cfg.FmtStrategy = "none"
+		}},
+		{name: "sortblocks", dir: filepath.Join("testdata", "sortblocks"), mutate: sortBlocks("variable=required-first")},
+		{name: "sortblocks/separated", dir: filepath.Join("testdata", "sortblocks"), want: "separated.tf", mutate: func(cfg *config.Config) {
+			sortBlocks("variable=required-first")(cfg)
+			cfg.SeparateBlocks = true
+		}},
+		{name: "toposort", dir: filepath.Join("testdata", "toposort"), mutate: func(cfg *config.Config) { cfg.TopoSort = true }},
+		{name: "toposort/nofmt", dir: filepath.Join("testdata", "toposort"), mutate: func(cfg *config.Config) {
+			cfg.TopoSort = true
+			cfg.NoFmt = true
+		}},
+		{name: "nofmt", dir: filepath.Join("testdata", "nofmt"), mutate: noFmt},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in, want := tc.in, tc.want
+			if in == "" {
+				in = "in.tf"
+			}
+			if want == "" {
+				want = "out.tf"
+			}
+			src, err := os.ReadFile(filepath.Join(tc.dir, in))
+			require.NoError(t, err)
+			golden, err := os.ReadFile(filepath.Join(tc.dir, want))
+			require.NoError(t, err)
+
+			path := filepath.Join(t.TempDir(), "in.tf")
+			require.NoError(t, os.WriteFile(path, src, 0o644))
+			changed, out := runFile(t, goldenConfig(config.ModeCheck, tc.mutate), path)
+			require.True(t, changed)
+			require.Equal(t, string(golden), string(out))
+			untouched, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, string(src), string(untouched))
+
+			changed, _ = runFile(t, goldenConfig(config.ModeWrite, tc.mutate), path)
+			require.True(t, changed)
+			got, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, string(golden), string(got))
+
+			changed, out = runFile(t, goldenConfig(config.ModeWrite, tc.mutate), path)
+			require.False(t, changed)
+			require.Equal(t, string(golden), string(out))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// internal/engine/interrupt.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInterrupted = errors.New("interrupted")
+
+type PartialRunError struct {
+	Completed  []string
+	Failed     []string
+	NotStarted []string
+}
+
+type fileStatus uint8
+
+const (
+	filePending fileStatus = iota
+	fileCompleted
+	fileFailed
+)
+
+func partialRun(files []string, status []fileStatus, dispatched int) *PartialRunError {
+	e := &PartialRunError{NotStarted: append([]string(nil), files[dispatched:]...)}
+	for i, f := range files[:dispatched] {
+		switch status[i] {
+		case fileCompleted:
+			e.Completed = append(e.Completed, f)
+		case fileFailed:
+			e.Failed = append(e.Failed, f)
+		}
+	}
+	return e
+}
+
+func (e *PartialRunError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "interrupted: %d files completed, %d failed, %d not started", len(e.Completed), len(e.Failed), len(e.NotStarted))
+	writeList := func(title string, files []string) {
+		if len(files) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:", title)
+		for _, f := range files {
+			b.WriteString("\n  " + f)
+		}
+	}
+	writeList("completed", e.Completed)
+	writeList("failed", e.Failed)
+	writeList("not started", e.NotStarted)
+	return b.String()
+}
+
+func (e *PartialRunError) Is(target error) bool { return target == ErrInterrupted }
+
+type interruptKey struct{}
+
+func WithInterrupt(ctx context.Context, stop <-chan struct{}) context.Context {
+	return context.WithValue(ctx, interruptKey{}, stop)
+}
+
+func interrupted(ctx context.Context) <-chan struct{} {
+	stop, _ := ctx.Value(interruptKey{}).(<-chan struct{})
+	return stop
+}
@@ -0,0 +1,38 @@
+// internal/engine/nofmt_test.go
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoFmtOnlyMovesLines(t *testing.T) {
+	cases, err := filepath.Glob(filepath.Join("..", "..", "tests", "cases", "*", "in.tf"))
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+	sortedLines := func(b []byte) []string {
+		lines := strings.SplitAfter(string(b), "\n")
+		sort.Strings(lines)
+		return lines
+	}
+	for _, in := range cases {
+		t.Run(filepath.Base(filepath.Dir(in)), func(t *testing.T) {
+			src, err := os.ReadFile(in)
+			require.NoError(t, err)
+			path := filepath.Join(t.TempDir(), "in.tf")
+			require.NoError(t, os.WriteFile(path, src, 0o644))
+
+			changed, out := runFile(t, goldenConfig(config.ModeCheck, noFmt), path)
+			require.Equal(t, !bytes.Equal(src, out), changed)
+			require.Len(t, out, len(src))
+			require.Equal(t, sortedLines(src), sortedLines(out))
+		})
+	}
+}
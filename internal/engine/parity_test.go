@@ -0,0 +1,83 @@
+// internal/engine/parity_test.go
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/diff"
+	terraformfmt "github.com/oferchen/hclalign/internal/fmt"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModesAgreeForEveryCase(t *testing.T) {
+	cases, err := filepath.Glob(filepath.Join("..", "..", "tests", "cases", "*", "in.tf"))
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+
+	strategies := []terraformfmt.Strategy{terraformfmt.StrategyAuto, terraformfmt.StrategyGo, terraformfmt.StrategyNone}
+	if bin, _ := toolBinary(&config.Config{}); bin.Available() {
+		strategies = append(strategies, terraformfmt.StrategyBinary)
+	}
+
+	run := func(t *testing.T, mode config.Mode, strategy terraformfmt.Strategy, path string) (bool, []byte, error) {
+		cfg := &config.Config{Mode: mode, Stdout: true, FmtStrategy: string(strategy)}
+		bin, err := toolBinary(cfg)
+		require.NoError(t, err)
+		batcher := terraformfmt.NewBatcher(bin, 1, terraformfmt.DefaultBatchSize)
+		defer batcher.Close()
+		format, err := formatterFor(cfg, bin, batcher)
+		require.NoError(t, err)
+		p := &Processor{cfg: cfg, format: format}
+		return p.pipeline(true).Run(context.Background(), FileSource(path))
+	}
+
+	for _, in := range cases {
+		name := filepath.Base(filepath.Dir(in))
+		src, err := os.ReadFile(in)
+		require.NoError(t, err)
+		for _, strategy := range strategies {
+			t.Run(name+"/"+string(strategy), func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "in.tf")
+				require.NoError(t, os.WriteFile(path, src, 0o644))
+
+				checkChanged, checkOut, checkErr := run(t, config.ModeCheck, strategy, path)
+				diffChanged, diffOut, diffErr := run(t, config.ModeDiff, strategy, path)
+				writeChanged, writeOut, writeErr := run(t, config.ModeWrite, strategy, path)
+				if checkErr != nil {
+					require.Error(t, diffErr)
+					require.Error(t, writeErr)
+					return
+				}
+				require.NoError(t, diffErr)
+				require.NoError(t, writeErr)
+				require.Equal(t, checkChanged, diffChanged)
+				require.Equal(t, checkChanged, writeChanged)
+				require.Equal(t, checkOut, writeOut)
+
+				written, err := os.ReadFile(path)
+				require.NoError(t, err)
+				require.Equal(t, string(checkOut), string(written))
+				if !checkChanged {
+					require.Empty(t, diffOut)
+					return
+				}
+
+				hints := internalfs.DetectHintsFromBytes(src)
+				want, err := diff.Unified(diff.UnifiedOpts{
+					FromFile: path,
+					ToFile:   path,
+					Original: src[len(hints.BOM()):],
+					Styled:   written[len(hints.BOM()):],
+					Hints:    hints,
+				})
+				require.NoError(t, err)
+				require.Equal(t, want, string(diffOut))
+			})
+		}
+	}
+}
@@ -9,36 +9,156 @@ import (
 	"sync"
 	"sync/atomic"
 
-	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclwrite"
-
 	"github.com/oferchen/hclalign/config"
 	"github.com/oferchen/hclalign/internal/align"
-	"github.com/oferchen/hclalign/internal/diff"
+	"github.com/oferchen/hclalign/internal/diag"
 	terraformfmt "github.com/oferchen/hclalign/internal/fmt"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/heredoc"
+	"github.com/oferchen/hclalign/internal/tool"
+	"github.com/oferchen/hclalign/internal/trace"
 )
 
-var (
-	terraformFmtFormatFile = terraformfmt.FormatFile
-	terraformFmtRun        = terraformfmt.Run
-)
+type FormatterFunc func(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error)
+
+func (f FormatterFunc) Format(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+	return f(ctx, src)
+}
+
+func toolBinary(cfg *config.Config) (tool.Binary, error) {
+	return tool.Resolve(cfg.Tool, cfg.TerraformBin)
+}
+
+func defaultFormat(cfg *config.Config) FormatterFunc {
+	return func(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+		bin, err := toolBinary(cfg)
+		if err != nil {
+			return nil, internalfs.Hints{}, err
+		}
+		return terraformfmt.Run(ctx, bin, src)
+	}
+}
+
+type slotFormatter struct {
+	format Formatter
+	slots  chan struct{}
+}
+
+func (f slotFormatter) Format(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+	<-f.slots
+	defer func() { f.slots <- struct{}{} }()
+	return f.format.Format(ctx, src)
+}
+
+func fmtStrategy(cfg *config.Config) string {
+	if cfg.NoFmt {
+		return string(terraformfmt.StrategyNone)
+	}
+	return cfg.FmtStrategy
+}
+
+func batchesFmt(cfg *config.Config, bin tool.Binary) bool {
+	switch terraformfmt.Strategy(fmtStrategy(cfg)) {
+	case terraformfmt.StrategyAuto, terraformfmt.StrategyBinary, "":
+		return bin.Available()
+	default:
+		return false
+	}
+}
+
+func formatterFor(cfg *config.Config, bin tool.Binary, batched Formatter) (FormatterFunc, error) {
+	format, err := baseFormatter(cfg, bin, batched)
+	if err != nil {
+		return nil, err
+	}
+	opts := cfg.Heredocs()
+	if !opts.Enabled() {
+		return format, nil
+	}
+	return func(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+		formatted, hints, err := format(ctx, src)
+		if err != nil {
+			return nil, hints, err
+		}
+		normalized, err := heredoc.Normalize(formatted, opts)
+		if err != nil || bytes.Equal(normalized, formatted) {
+			return formatted, hints, err
+		}
+		formatted, _, err = format(ctx, normalized)
+		return formatted, hints, err
+	}, nil
+}
+
+func baseFormatter(cfg *config.Config, bin tool.Binary, batched Formatter) (FormatterFunc, error) {
+	strategy := fmtStrategy(cfg)
+	switch terraformfmt.Strategy(strategy) {
+	case terraformfmt.StrategyAuto, "":
+		if batched != nil && bin.Available() {
+			return batched.Format, nil
+		}
+	case terraformfmt.StrategyBinary:
+		if !bin.Available() {
+			return nil, fmt.Errorf("fmt strategy binary: %s binary not found", bin.Name())
+		}
+		if batched != nil {
+			return batched.Format, nil
+		}
+	default:
+		if err := terraformfmt.ValidateStrategy(strategy); err != nil {
+			return nil, err
+		}
+	}
+	return func(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+		return terraformfmt.Format(ctx, bin, src, "", strategy)
+	}, nil
+}
 
 type Processor struct {
-	cfg     *config.Config
-	schemas map[string]*align.Schema
+	cfg       *config.Config
+	schemas   map[string]*align.Schema
+	filter    *align.AddressFilter
+	sorter    *align.BlockSort
+	format    FormatterFunc
+	collector *violationCollector
 }
 
-func runPipeline(ctx context.Context, cfg *config.Config, files []string) (map[string][]byte, bool, []error) {
+func runPipeline(ctx context.Context, cfg *config.Config, files []string, collector *violationCollector) (map[string][]byte, bool, []error) {
 	outs := make(map[string][]byte, len(files))
 
 	schemas, err := loadSchemas(ctx, cfg)
 	if err != nil {
 		return outs, false, []error{err}
 	}
-	p := &Processor{cfg: cfg, schemas: schemas}
+	filter, err := addressFilter(cfg)
+	if err != nil {
+		return outs, false, []error{err}
+	}
+	defer reportFilter(filter)
+	sorter, err := blockSort(cfg)
+	if err != nil {
+		return outs, false, []error{err}
+	}
+	bin, err := toolBinary(cfg)
+	if err != nil {
+		return outs, false, []error{err}
+	}
+	workers := cfg.Concurrency
+	slots := make(chan struct{}, cfg.Concurrency)
+	var batcher *terraformfmt.Batcher
+	var batched Formatter
+	if batchesFmt(cfg, bin) {
+		batcher = terraformfmt.NewBatcher(bin, cfg.FmtWorkers, terraformfmt.DefaultBatchSize)
+		defer batcher.Close()
+		batched = slotFormatter{format: batcher, slots: slots}
+		workers = max(workers, min(len(files), terraformfmt.DefaultBatchSize))
+	}
+	format, err := formatterFor(cfg, bin, batched)
+	if err != nil {
+		return outs, false, []error{err}
+	}
+	p := &Processor{cfg: cfg, schemas: schemas, filter: filter, sorter: sorter, format: format, collector: collector}
 
-	fileCh := make(chan string)
+	fileCh := make(chan int)
 	results := make(chan struct {
 		path string
 		data []byte
@@ -48,40 +168,57 @@ func runPipeline(ctx context.Context, cfg *config.Config, files []string) (map[s
 	var wg sync.WaitGroup
 	var errMu sync.Mutex
 	var errs []error
+	var dispatched atomic.Int64
+	status := make([]fileStatus, len(files))
+	stop := interrupted(ctx)
 
 	go func() {
 		defer close(fileCh)
-		for _, f := range files {
+		for i := range files {
+			select {
+			case <-stop:
+				return
+			default:
+			}
 			select {
-			case fileCh <- f:
+			case fileCh <- i:
+				dispatched.Add(1)
 			case <-ctx.Done():
 				return
+			case <-stop:
+				return
 			}
 		}
 	}()
 
-	for i := 0; i < cfg.Concurrency; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
+		ctx := trace.WithThread(ctx, i+1, fmt.Sprintf("worker %d", i+1))
+		release := batcher.Hold()
 		go func() {
 			defer wg.Done()
+			defer release()
 			for {
 				select {
 				case <-ctx.Done():
 					return
-				case f, ok := <-fileCh:
+				case idx, ok := <-fileCh:
 					if !ok {
 						return
 					}
-					ch, out, err := p.processFile(ctx, f)
+					f := files[idx]
+					ch, out, err := p.processSlot(ctx, f, slots)
 					if err != nil {
 						if errors.Is(err, context.Canceled) {
 							return
 						}
+						status[idx] = fileFailed
 						errMu.Lock()
-						errs = append(errs, fmt.Errorf("%s: %w", f, err))
+						errs = append(errs, fileError(f, err))
 						errMu.Unlock()
 						continue
 					}
+					status[idx] = fileCompleted
 					if ch {
 						changed.Store(true)
 					}
@@ -106,6 +243,10 @@ func runPipeline(ctx context.Context, cfg *config.Config, files []string) (map[s
 		outs[r.path] = r.data
 	}
 
+	if n := int(dispatched.Load()); n < len(files) && ctx.Err() == nil {
+		errs = append(errs, partialRun(files, status, n))
+	}
+
 	if len(errs) > 0 {
 		return outs, changed.Load(), errs
 	}
@@ -115,120 +256,40 @@ func runPipeline(ctx context.Context, cfg *config.Config, files []string) (map[s
 	return outs, changed.Load(), nil
 }
 
-func (p *Processor) processFile(ctx context.Context, filePath string) (bool, []byte, error) {
-	if err := ctx.Err(); err != nil {
-		return false, nil, err
-	}
-	data, perm, hints, err := internalfs.ReadFileWithHints(ctx, filePath)
-	if err != nil {
-		return false, nil, fmt.Errorf("error reading file %s: %w", filePath, err)
-	}
-	if err := ctx.Err(); err != nil {
-		return false, nil, err
-	}
-
-	original := append([]byte(nil), data...)
-	originalWithHints := append(append([]byte(nil), hints.BOM()...), original...)
-	hadNewline := len(data) > 0 && data[len(data)-1] == '\n'
-
-	ranFmt := false
-	if p.cfg.Mode == config.ModeWrite {
-		formattedBytes, _, ran, err := terraformFmtFormatFile(ctx, filePath)
-		if err != nil {
-			return false, nil, fmt.Errorf("parsing error in file %s: %w", filePath, err)
-		}
-		if err := ctx.Err(); err != nil {
-			return false, nil, err
-		}
-		ranFmt = ran
-		if ranFmt {
-			data = formattedBytes
-		}
+func fileError(path string, err error) error {
+	var located *diag.Error
+	if errors.As(err, &located) {
+		return err
 	}
+	return fmt.Errorf("%s: %w", path, err)
+}
 
-	var formatted []byte
-	if ranFmt {
-		formatted = data
-	} else {
-		formatted, _, err = terraformFmtRun(ctx, data)
-		if err != nil {
-			return false, nil, fmt.Errorf("parsing error in file %s: %w", filePath, err)
-		}
+func (p *Processor) processSlot(ctx context.Context, filePath string, slots chan struct{}) (bool, []byte, error) {
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return false, nil, ctx.Err()
 	}
+	defer func() { <-slots }()
+	return p.processFile(ctx, filePath)
+}
 
-	parseData := internalfs.PrepareForParse(formatted, hints)
-	file, diags := hclwrite.ParseConfig(parseData, filePath, hcl.InitialPos)
-	if diags.HasErrors() {
-		return false, nil, fmt.Errorf("parsing error in file %s: %v", filePath, diags.Errs())
-	}
-	if testHookAfterParse != nil {
-		testHookAfterParse()
-	}
-	var typesMap map[string]struct{}
-	if p.cfg.Types != nil {
-		typesMap = make(map[string]struct{}, len(p.cfg.Types))
-		for _, t := range p.cfg.Types {
-			typesMap[t] = struct{}{}
-		}
-	}
-	if err := align.Apply(file, &align.Options{Schemas: p.schemas, Types: typesMap}); err != nil {
-		return false, nil, err
-	}
-	if testHookAfterReorder != nil {
-		testHookAfterReorder()
-	}
+func (p *Processor) processFile(ctx context.Context, filePath string) (bool, []byte, error) {
+	ws := filesystem(p.cfg)
+	return p.pipeline(true).Run(ctx, FSSource{FS: ws.fsys, Name: ws.name(filePath), Path: filePath})
+}
 
-	formatted, _, err = terraformFmtRun(ctx, file.Bytes())
-	if err != nil {
-		return false, nil, err
+func (p *Processor) pipeline(writeFiles bool) *Pipeline {
+	format := p.format
+	if format == nil {
+		format = defaultFormat(p.cfg)
 	}
-
-	if !hadNewline && len(formatted) > 0 && formatted[len(formatted)-1] == '\n' {
-		formatted = formatted[:len(formatted)-1]
+	options := func(path string) *align.Options {
+		return alignOptions(p.cfg, path, p.schemas, p.filter, p.sorter)
 	}
-
-	styled := internalfs.ApplyHints(append([]byte(nil), formatted...), hints)
-	changed := !bytes.Equal(originalWithHints, styled)
-
-	var out []byte
-	switch p.cfg.Mode {
-	case config.ModeWrite:
-		if !changed {
-			if p.cfg.Stdout {
-				out = styled
-			}
-			return false, out, nil
-		}
-		if err := WriteFileAtomic(ctx, internalfs.WriteOpts{Path: filePath, Data: formatted, Perm: perm, Hints: hints}); err != nil {
-			return false, nil, fmt.Errorf("error writing file %s with original permissions: %w", filePath, err)
-		}
-		if p.cfg.Stdout {
-			out = styled
-		}
-	case config.ModeCheck:
-		if p.cfg.Stdout {
-			out = styled
-		}
-	case config.ModeDiff:
-		if changed {
-			styledForDiff := styled
-			originalForDiff := originalWithHints
-			if hints.HasBOM {
-				bom := hints.BOM()
-				if len(styledForDiff) >= len(bom) {
-					styledForDiff = styledForDiff[len(bom):]
-				}
-				if len(originalForDiff) >= len(bom) {
-					originalForDiff = originalForDiff[len(bom):]
-				}
-			}
-			text, err := diff.Unified(diff.UnifiedOpts{FromFile: filePath, ToFile: filePath, Original: originalForDiff, Styled: styledForDiff, Hints: hints})
-			if err != nil {
-				return false, nil, err
-			}
-			out = []byte(text)
-		}
+	pipeline := NewPipeline(p.cfg, format, options, writeFiles)
+	if p.collector != nil {
+		pipeline.Verifiers = append(pipeline.Verifiers, p.collector)
 	}
-
-	return changed, out, nil
+	return pipeline
 }
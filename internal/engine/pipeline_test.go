@@ -2,42 +2,50 @@
 package engine
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/formatter"
+	"github.com/oferchen/hclalign/internal/diag"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/trace"
+	"github.com/oferchen/hclalign/internal/verify"
 	"github.com/stretchr/testify/require"
 )
 
 func TestProcessFileTerraformFmt(t *testing.T) {
-	dir := t.TempDir()
-	file := filepath.Join(dir, "a.tf")
-	require.NoError(t, os.WriteFile(file, []byte("variable \"a\" { type = string }"), 0o644))
-
-	var formatCalls, runCalls int
-	origFormat := terraformFmtFormatFile
-	origRun := terraformFmtRun
-	terraformFmtFormatFile = func(ctx context.Context, path string) ([]byte, internalfs.Hints, bool, error) {
-		formatCalls++
-		return nil, internalfs.Hints{}, false, nil
+	cases := []struct {
+		name  string
+		src   string
+		calls int
+	}{
+		{name: "aligned", src: "variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n", calls: 2},
+		{name: "unchanged", src: "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n", calls: 1},
 	}
-	terraformFmtRun = func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
-		runCalls++
-		return b, internalfs.Hints{}, nil
-	}
-	t.Cleanup(func() {
-		terraformFmtFormatFile = origFormat
-		terraformFmtRun = origRun
-	})
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "a.tf")
+			require.NoError(t, os.WriteFile(file, []byte(tc.src), 0o644))
 
-	p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}}
-	_, _, err := p.processFile(context.Background(), file)
-	require.NoError(t, err)
-	require.Equal(t, 1, formatCalls)
-	require.Equal(t, 2, runCalls)
+			var calls int
+			format := func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+				calls++
+				return b, internalfs.Hints{}, nil
+			}
+
+			p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}, format: format}
+			_, _, err := p.processFile(context.Background(), file)
+			require.NoError(t, err)
+			require.Equal(t, tc.calls, calls)
+		})
+	}
 }
 
 func TestProcessFileTerraformFmtPreservesCRLF(t *testing.T) {
@@ -47,21 +55,11 @@ func TestProcessFileTerraformFmtPreservesCRLF(t *testing.T) {
 	content := internalfs.ApplyHints([]byte("variable \"a\" {type=string}\n"), hints)
 	require.NoError(t, os.WriteFile(file, content, 0o644))
 
-	origFormat := terraformFmtFormatFile
-	origRun := terraformFmtRun
-	terraformFmtFormatFile = func(ctx context.Context, path string) ([]byte, internalfs.Hints, bool, error) {
-		formatted := []byte("variable \"a\" {\n  type = string\n}\n")
-		return formatted, internalfs.Hints{}, true, nil
+	format := func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+		return []byte("variable \"a\" {\n  type = string\n}\n"), internalfs.Hints{}, nil
 	}
-	terraformFmtRun = func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
-		return b, internalfs.Hints{}, nil
-	}
-	t.Cleanup(func() {
-		terraformFmtFormatFile = origFormat
-		terraformFmtRun = origRun
-	})
 
-	p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}}
+	p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}, format: format}
 	changed, _, err := p.processFile(context.Background(), file)
 	require.NoError(t, err)
 	require.True(t, changed)
@@ -80,21 +78,11 @@ func TestProcessFileTerraformFmtPreservesBOM(t *testing.T) {
 	content := internalfs.ApplyHints([]byte("variable \"a\" {type=string}\n"), hints)
 	require.NoError(t, os.WriteFile(file, content, 0o644))
 
-	origFormat := terraformFmtFormatFile
-	origRun := terraformFmtRun
-	terraformFmtFormatFile = func(ctx context.Context, path string) ([]byte, internalfs.Hints, bool, error) {
-		formatted := []byte("variable \"a\" {\n  type = string\n}\n")
-		return formatted, internalfs.Hints{}, true, nil
+	format := func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+		return []byte("variable \"a\" {\n  type = string\n}\n"), internalfs.Hints{}, nil
 	}
-	terraformFmtRun = func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
-		return b, internalfs.Hints{}, nil
-	}
-	t.Cleanup(func() {
-		terraformFmtFormatFile = origFormat
-		terraformFmtRun = origRun
-	})
 
-	p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}}
+	p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}, format: format}
 	changed, _, err := p.processFile(context.Background(), file)
 	require.NoError(t, err)
 	require.True(t, changed)
@@ -105,3 +93,294 @@ func TestProcessFileTerraformFmtPreservesBOM(t *testing.T) {
 	require.Equal(t, "\r\n", h.Newline)
 	require.True(t, h.HasBOM)
 }
+
+func TestProcessFileVerifyBlocksWrite(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	orig := verifyEquivalent
+	t.Cleanup(func() { verifyEquivalent = orig })
+	verifyEquivalent = func(before, after []byte, _ verify.Options) error {
+		return &verify.Error{Problems: []string{"comment \"# c\" dropped"}}
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.tf")
+	src := []byte("variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n")
+	require.NoError(t, os.WriteFile(file, src, 0o644))
+
+	format := func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+		return b, internalfs.Hints{}, nil
+	}
+	p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}, format: format}
+	_, _, err := p.processFile(context.Background(), file)
+	require.ErrorContains(t, err, "internal error")
+	require.ErrorContains(t, err, "reproducer saved to")
+
+	out, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, src, out)
+	repros, err := filepath.Glob(filepath.Join(os.Getenv("TMPDIR"), "hclalign-repro-*.tf"))
+	require.NoError(t, err)
+	require.Len(t, repros, 1)
+	repro, err := os.ReadFile(repros[0])
+	require.NoError(t, err)
+	require.Equal(t, src, repro)
+
+	p.cfg.NoVerify = true
+	changed, _, err := p.processFile(context.Background(), file)
+	require.NoError(t, err)
+	require.True(t, changed)
+}
+
+func TestProcessFileVerifyChecksPreFormat(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.tf")
+	src := []byte("variable \"a\" {\n  type        = \"string\"\n  description = \"d\"\n}\n")
+	require.NoError(t, os.WriteFile(file, src, 0o644))
+
+	format := func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+		return bytes.Replace(b, []byte(`"d"`), []byte(`"e"`), 1), internalfs.Hints{}, nil
+	}
+	p := &Processor{cfg: &config.Config{Mode: config.ModeWrite}, format: format}
+	_, _, err := p.processFile(context.Background(), file)
+	require.ErrorContains(t, err, `attribute "variable \"a\".description = \"d\"" dropped`)
+	out, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, src, out)
+
+	p.format = func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+		return formatter.Format(b, "")
+	}
+	changed, _, err := p.processFile(context.Background(), file)
+	require.NoError(t, err)
+	require.True(t, changed)
+	out, err = os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n", string(out))
+}
+
+func TestProcessFileVerifyIdempotent(t *testing.T) {
+	cases := []struct {
+		name   string
+		format FormatterFunc
+		err    string
+	}{
+		{
+			name: "stable",
+			format: func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+				return b, internalfs.Hints{}, nil
+			},
+		},
+		{
+			name: "unstable",
+			format: func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+				return append(append([]byte(nil), b...), "# pass\n"...), internalfs.Hints{}, nil
+			},
+			err: "not idempotent",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "a.tf")
+			src := []byte("variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n")
+			require.NoError(t, os.WriteFile(file, src, 0o644))
+
+			p := &Processor{cfg: &config.Config{Mode: config.ModeCheck, VerifyIdempotent: true}, format: tc.format}
+			_, _, err := p.processFile(context.Background(), file)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.err)
+			require.ErrorContains(t, err, file+" (pass 1)")
+			require.ErrorContains(t, err, "+# pass")
+		})
+	}
+}
+
+func TestProcessFileCommentAudit(t *testing.T) {
+	src := []byte("variable \"a\" {\n  # networking\n  type        = string\n  description = \"d\"\n}\n")
+	cases := []struct {
+		name     string
+		cfg      config.Config
+		warning  bool
+		severity string
+		err      string
+	}{
+		{name: "check warns", cfg: config.Config{Mode: config.ModeCheck}, warning: true, severity: "warning"},
+		{name: "write ignores", cfg: config.Config{Mode: config.ModeWrite}},
+		{name: "strict check", cfg: config.Config{Mode: config.ModeCheck, StrictComments: true}, severity: "error", err: "comment audit failed"},
+		{name: "strict write", cfg: config.Config{Mode: config.ModeWrite, StrictComments: true}, severity: "error", err: "comment audit failed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			orig := warnOut
+			t.Cleanup(func() { warnOut = orig })
+			warnOut = &buf
+
+			file := filepath.Join(t.TempDir(), "a.tf")
+			require.NoError(t, os.WriteFile(file, src, 0o644))
+			cfg := tc.cfg
+			calls := 0
+			format := func(ctx context.Context, b []byte) ([]byte, internalfs.Hints, error) {
+				calls++
+				if calls > 1 {
+					b = bytes.Replace(b, []byte("  # networking\n"), nil, 1)
+					b = bytes.Replace(b, []byte("  description"), []byte("  # networking\n  description"), 1)
+				}
+				return b, internalfs.Hints{}, nil
+			}
+			p := &Processor{cfg: &cfg, format: format}
+			report := diag.NewReport()
+			_, _, err := p.processFile(diag.WithReport(context.Background(), report), file)
+			if tc.severity == "" {
+				require.Empty(t, report.Diagnostics())
+			} else {
+				require.Equal(t, []diag.Diagnostic{{
+					Code:     "comment-moved",
+					Severity: tc.severity,
+					Summary:  "Comment changed owner",
+					Detail:   `comment "# networking" (line 2) moved from before attribute "type" in block variable "a" to before attribute "description" in block variable "a"`,
+					Range:    &diag.Range{Filename: file, Start: diag.Pos{Line: 2, Column: 3, Byte: 17}, End: diag.Pos{Line: 2, Column: 15, Byte: 29}},
+				}}, report.Diagnostics())
+			}
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				var cerr *verify.CommentError
+				require.ErrorAs(t, err, &cerr)
+				require.Len(t, cerr.Findings, 1)
+				out, err := os.ReadFile(file)
+				require.NoError(t, err)
+				require.Equal(t, src, out)
+				return
+			}
+			require.NoError(t, err)
+			if tc.warning {
+				require.Equal(t, "warning: "+file+`: comment "# networking" (line 2) moved from before attribute "type" in block variable "a" to before attribute "description" in block variable "a"`+"\n", buf.String())
+			} else {
+				require.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func TestProcessFileStrictCommentsAnchored(t *testing.T) {
+	cases := []struct {
+		name   string
+		src    string
+		mutate func(*config.Config)
+	}{
+		{name: "variable lead", src: "variable \"b\" {\n  # about default\n  default = 1\n  description = \"x\"\n}\n"},
+		{name: "sort blocks header", src: "# file header\n\nvariable \"z\" {}\n\nlocals {\n  a = 1\n}\n\nvariable \"a\" {}\n", mutate: func(cfg *config.Config) {
+			cfg.SortBlocks = true
+			cfg.BlockOrder = config.DefaultBlockOrder
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "a.tf")
+			require.NoError(t, os.WriteFile(file, []byte(tc.src), 0o644))
+			cfg := goldenConfig(config.ModeCheck, func(cfg *config.Config) {
+				cfg.StrictComments = true
+				if tc.mutate != nil {
+					tc.mutate(cfg)
+				}
+			})
+			changed, _ := runFile(t, cfg, file)
+			require.True(t, changed)
+		})
+	}
+}
+
+func TestProcessFilesTrace(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.tf")
+	require.NoError(t, os.WriteFile(file, []byte("variable \"a\" {\n  type = string\n  description = \"d\"\n}\n"), 0o644))
+
+	tr := trace.New()
+	ctx := trace.WithTracer(context.Background(), tr)
+	cfg := &config.Config{Target: dir, Mode: config.ModeWrite, Include: config.DefaultInclude, Concurrency: 1, Types: []string{"variable"}}
+	changed, err := Process(ctx, cfg)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	names := map[string]int{}
+	for _, ev := range tr.Events() {
+		if ev.Ph == "M" {
+			names[ev.Args["name"]] = ev.Tid
+			continue
+		}
+		names[ev.Name] = ev.Tid
+	}
+	require.Equal(t, 0, names["main"])
+	require.Equal(t, 1, names["worker 1"])
+	require.Equal(t, 0, names["scan"])
+	for _, phase := range []string{"file", "read", "pre-fmt", "parse", "align", "align:variable", "post-fmt", "write"} {
+		tid, ok := names[phase]
+		require.True(t, ok, phase)
+		require.Equal(t, 1, tid, phase)
+	}
+}
+
+func TestProcessFilesAddressFilterSummary(t *testing.T) {
+	var buf bytes.Buffer
+	orig := warnOut
+	t.Cleanup(func() { warnOut = orig })
+	warnOut = &buf
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.tf")
+	src := "module \"legacy_vpc\" {\n  version = \"1.0.0\"\n  source  = \"./vpc\"\n}\n\nmodule \"network\" {\n  version = \"1.0.0\"\n  source  = \"./net\"\n}\n"
+	require.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+
+	cfg := &config.Config{Target: dir, Mode: config.ModeWrite, Include: config.DefaultInclude, Concurrency: 1, VerifyIdempotent: true, Skip: []string{"module.legacy_*"}}
+	changed, err := Process(context.Background(), cfg)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "summary: 1 blocks matched address filters, 1 unmatched\n", buf.String())
+
+	out, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, "module \"legacy_vpc\" {\n  version = \"1.0.0\"\n  source  = \"./vpc\"\n}\n\nmodule \"network\" {\n  source  = \"./net\"\n  version = \"1.0.0\"\n}\n", string(out))
+}
+
+func TestRunPipelineInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	src := "variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n"
+	files := make([]string, 4)
+	for i := range files {
+		files[i] = filepath.Join(dir, fmt.Sprintf("%d.tf", i))
+		require.NoError(t, os.WriteFile(files[i], []byte(src), 0o644))
+	}
+	require.NoError(t, os.WriteFile(files[0], []byte("variable \"a\" {\n"), 0o644))
+
+	stop := make(chan struct{})
+	var once sync.Once
+	testHookAfterParse = func() { once.Do(func() { close(stop) }) }
+	t.Cleanup(func() { testHookAfterParse = nil })
+
+	ctx := WithInterrupt(context.Background(), stop)
+	cfg := &config.Config{Mode: config.ModeWrite, Concurrency: 1}
+	_, changed, errs := runPipeline(ctx, cfg, files, nil)
+	require.True(t, changed)
+	require.Len(t, errs, 2)
+	require.ErrorContains(t, errs[0], files[0])
+	require.ErrorIs(t, errs[1], ErrInterrupted)
+	var partial *PartialRunError
+	require.ErrorAs(t, errs[1], &partial)
+	require.Equal(t, files[1:2], partial.Completed)
+	require.Equal(t, files[:1], partial.Failed)
+	require.Equal(t, files[2:], partial.NotStarted)
+	require.Equal(t, fmt.Sprintf("interrupted: 1 files completed, 1 failed, 2 not started\ncompleted:\n  %s\nfailed:\n  %s\nnot started:\n  %s\n  %s", files[1], files[0], files[2], files[3]), partial.Error())
+
+	written, err := os.ReadFile(files[1])
+	require.NoError(t, err)
+	require.NotEqual(t, src, string(written))
+	for _, f := range files[2:] {
+		untouched, err := os.ReadFile(f)
+		require.NoError(t, err)
+		require.Equal(t, src, string(untouched))
+	}
+}
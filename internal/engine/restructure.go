@@ -0,0 +1,259 @@
+// internal/engine/restructure.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/diff"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/restructure"
+	"github.com/oferchen/hclalign/internal/trace"
+)
+
+type styledSink struct{}
+
+func (styledSink) Emit(_ context.Context, res *Result) ([]byte, error) {
+	return res.Styled, nil
+}
+
+func restructureFiles(ctx context.Context, cfg *config.Config, files []string) (bool, error) {
+	rules, err := restructure.NewRules(cfg.RestructureRules)
+	if err != nil {
+		return false, err
+	}
+	schemas, err := loadSchemas(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+	filter, err := addressFilter(cfg)
+	if err != nil {
+		return false, err
+	}
+	defer reportFilter(filter)
+	sorter, err := blockSort(cfg)
+	if err != nil {
+		return false, err
+	}
+	bin, err := toolBinary(cfg)
+	if err != nil {
+		return false, err
+	}
+	format, err := formatterFor(cfg, bin, nil)
+	if err != nil {
+		return false, err
+	}
+	p := &Processor{cfg: cfg, schemas: schemas, filter: filter, sorter: sorter, format: format}
+
+	ws := filesystem(cfg)
+	dirOf, join := filepath.Dir, filepath.Join
+	if cfg.FS != nil {
+		dirOf, join = path.Dir, path.Join
+	}
+	var dirs []string
+	groups := map[string][]string{}
+	for _, f := range files {
+		dir := dirOf(f)
+		if _, ok := groups[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], f)
+	}
+	ordered := make([]string, 0, len(files))
+	for _, dir := range dirs {
+		ordered = append(ordered, groups[dir]...)
+	}
+	status := make([]fileStatus, len(ordered))
+	dispatched := 0
+	stop := interrupted(ctx)
+
+	changed := false
+	var errs []error
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return changed, err
+		}
+		select {
+		case <-stop:
+			errs = append(errs, partialRun(ordered, status, dispatched))
+			return changed, errors.Join(errs...)
+		default:
+		}
+		n := len(groups[dir])
+		first := dispatched
+		dispatched += n
+		for _, name := range rules.Targets() {
+			target := join(dir, name)
+			if containsPath(groups[dir], target) {
+				continue
+			}
+			if _, err := iofs.Stat(ws.fsys, ws.name(target)); err == nil {
+				groups[dir] = append(groups[dir], target)
+			}
+		}
+		ch, err := p.restructureDir(ctx, rules, dir, groups[dir], join)
+		result := fileCompleted
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return changed, err
+			}
+			errs = append(errs, err)
+			result = fileFailed
+		}
+		for i := first; i < first+n; i++ {
+			status[i] = result
+		}
+		changed = changed || ch
+	}
+	return changed, errors.Join(errs...)
+}
+
+func (p *Processor) restructureDir(ctx context.Context, rules *restructure.Rules, dir string, files []string, join func(...string) string) (bool, error) {
+	defer trace.Start(ctx, "restructure", dir)()
+	ws := filesystem(p.cfg)
+	docs := map[string]*Document{}
+	var inputs []restructure.File
+	var hints internalfs.Hints
+	perm := iofs.FileMode(0o644)
+	for _, f := range files {
+		doc, err := FSSource{FS: ws.fsys, Name: ws.name(f), Path: f}.Read(ctx)
+		if err != nil {
+			return false, fileError(f, err)
+		}
+		name := path.Base(filepath.ToSlash(f))
+		docs[name] = doc
+		if !strings.HasSuffix(name, ".tf") {
+			continue
+		}
+		if len(inputs) == 0 {
+			hints, perm = doc.Hints, doc.Perm
+		}
+		inputs = append(inputs, restructure.File{Name: name, Data: internalfs.PrepareForParse(doc.Data, doc.Hints)})
+	}
+	outputs, err := restructure.Apply(inputs, rules)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", dir, err)
+	}
+	contents := map[string][]byte{}
+	removed := map[string]bool{}
+	order := append([]string(nil), files...)
+	for _, out := range outputs {
+		if out.Remove {
+			removed[out.Name] = true
+			continue
+		}
+		h := hints
+		if doc, ok := docs[out.Name]; ok {
+			h = doc.Hints
+		} else {
+			order = append(order, join(dir, out.Name))
+		}
+		contents[out.Name] = internalfs.ApplyHints(out.Data, internalfs.Hints{Newline: h.Newline})
+	}
+
+	var changes []internalfs.Change
+	var text bytes.Buffer
+	var results [][]byte
+	for _, f := range order {
+		name := path.Base(filepath.ToSlash(f))
+		doc, exists := docs[name]
+		var original, styled []byte
+		if exists {
+			original = doc.Original()
+		}
+		if !removed[name] {
+			data, ok := contents[name]
+			if !ok {
+				data = doc.Data
+			}
+			if exists {
+				data = append(append([]byte(nil), doc.Hints.BOM()...), data...)
+			}
+			pipeline := p.pipeline(false)
+			pipeline.Sink = styledSink{}
+			_, out, err := pipeline.Run(ctx, BytesSource{Name: f, Data: data})
+			if err != nil {
+				return false, fileError(f, err)
+			}
+			styled = out
+			if strings.HasSuffix(name, ".tf") {
+				results = append(results, internalfs.PrepareForParse(styled, internalfs.DetectHintsFromBytes(styled)))
+			}
+			if exists && bytes.Equal(styled, original) {
+				continue
+			}
+		}
+		change := internalfs.Change{Path: ws.name(f), Data: styled, Perm: perm, Remove: removed[name]}
+		if exists {
+			change.Perm = doc.Perm
+		}
+		changes = append(changes, change)
+		if p.cfg.Mode != config.ModeWrite {
+			if err := restructureDiff(&text, f, exists, removed[name], original, styled); err != nil {
+				return false, fileError(f, err)
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return false, nil
+	}
+	if p.cfg.Mode == config.ModeWrite {
+		if !p.cfg.NoVerify {
+			sources := make([][]byte, len(inputs))
+			for i, in := range inputs {
+				sources[i] = in.Data
+			}
+			if err := verifyRestructure(sources, results, verifyOptions(p.cfg)); err != nil {
+				return false, fmt.Errorf("%s: %w", dir, err)
+			}
+		}
+		done := trace.Start(ctx, "write", dir)
+		err := internalfs.WriteBatch(ctx, ws.fsys, changes)
+		done()
+		if err != nil {
+			return true, fmt.Errorf("%s: %w", dir, err)
+		}
+		return true, nil
+	}
+	_, err = os.Stdout.Write(text.Bytes())
+	return true, err
+}
+
+func restructureDiff(w *bytes.Buffer, file string, exists, removed bool, original, styled []byte) error {
+	from, to := file, file
+	if !exists {
+		from = "/dev/null"
+	}
+	if removed {
+		to = "/dev/null"
+	}
+	hints := internalfs.DetectHintsFromBytes(original)
+	if !exists {
+		hints = internalfs.DetectHintsFromBytes(styled)
+	}
+	original = bytes.TrimPrefix(original, hints.BOM())
+	styled = bytes.TrimPrefix(styled, hints.BOM())
+	text, err := diff.Unified(diff.UnifiedOpts{FromFile: from, ToFile: to, Original: original, Styled: styled, Hints: hints})
+	if err != nil {
+		return err
+	}
+	w.WriteString(text)
+	return nil
+}
+
+func containsPath(files []string, target string) bool {
+	for _, f := range files {
+		if f == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+// internal/engine/restructure_test.go
+package engine
+
+import (
+	"context"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/verify"
+	"github.com/stretchr/testify/require"
+)
+
+func restructureConfig(mem *internalfs.MemFS, mode config.Mode) *config.Config {
+	return &config.Config{
+		Target:      ".",
+		Mode:        mode,
+		Include:     config.DefaultInclude,
+		Exclude:     config.DefaultExclude,
+		Order:       config.CanonicalOrder,
+		Types:       []string{"variable"},
+		FmtStrategy: "go",
+		Concurrency: 1,
+		Restructure: true,
+		FS:          mem,
+	}
+}
+
+func readDir(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		files[e.Name()] = data
+	}
+	return files
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	fn()
+	require.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestRestructureGolden(t *testing.T) {
+	in := readDir(t, filepath.Join("testdata", "restructure", "in"))
+	want := readDir(t, filepath.Join("testdata", "restructure", "out"))
+	mem := internalfs.NewMemFS(in)
+
+	var changed bool
+	out := captureStdout(t, func() {
+		var err error
+		changed, err = Process(context.Background(), restructureConfig(mem, config.ModeCheck))
+		require.NoError(t, err)
+	})
+	require.True(t, changed)
+	require.Contains(t, out, "--- /dev/null\n+++ variables.tf\n")
+	require.Contains(t, out, "--- providers.tf\n+++ /dev/null\n")
+	require.Contains(t, out, "--- main.tf\n+++ main.tf\n")
+	require.ElementsMatch(t, []string{"main.tf", "outputs.tf", "providers.tf", "versions.tf"}, mem.Files())
+
+	changed, err := Process(context.Background(), restructureConfig(mem, config.ModeWrite))
+	require.NoError(t, err)
+	require.True(t, changed)
+	got := map[string][]byte{}
+	for _, name := range mem.Files() {
+		data, err := iofs.ReadFile(mem, name)
+		require.NoError(t, err)
+		got[name] = data
+	}
+	for name := range want {
+		require.Equal(t, string(want[name]), string(got[name]), name)
+	}
+	require.Len(t, got, len(want))
+
+	changed, err = Process(context.Background(), restructureConfig(mem, config.ModeCheck))
+	require.NoError(t, err)
+	require.False(t, changed)
+}
+
+func TestRestructureInterrupted(t *testing.T) {
+	src := []byte("variable \"a\" {}\n\noutput \"o\" {\n  value = 1\n}\n")
+	mem := internalfs.NewMemFS(map[string][]byte{"a/main.tf": src, "b/main.tf": src})
+
+	stop := make(chan struct{})
+	var once sync.Once
+	testHookAfterParse = func() { once.Do(func() { close(stop) }) }
+	t.Cleanup(func() { testHookAfterParse = nil })
+
+	ctx := WithInterrupt(context.Background(), stop)
+	changed, err := Process(ctx, restructureConfig(mem, config.ModeWrite))
+	require.True(t, changed)
+	var partial *PartialRunError
+	require.ErrorAs(t, err, &partial)
+	require.Equal(t, []string{"a/main.tf"}, partial.Completed)
+	require.Empty(t, partial.Failed)
+	require.Equal(t, []string{"b/main.tf"}, partial.NotStarted)
+
+	require.ElementsMatch(t, []string{"a/outputs.tf", "a/variables.tf", "b/main.tf"}, mem.Files())
+	data, err := iofs.ReadFile(mem, "b/main.tf")
+	require.NoError(t, err)
+	require.Equal(t, string(src), string(data))
+}
+
+func TestRestructureVerifyBlocksWrite(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	orig := verifyFiles
+	t.Cleanup(func() { verifyFiles = orig })
+	var inputs, outputs int
+	verifyFiles = func(before, after [][]byte, _ verify.Options) error {
+		inputs, outputs = len(before), len(after)
+		return &verify.Error{Problems: []string{`block "output \"o\"" dropped`}}
+	}
+
+	src := []byte("variable \"a\" {}\n\noutput \"o\" {\n  value = 1\n}\n")
+	mem := internalfs.NewMemFS(map[string][]byte{"main.tf": src})
+	_, err := Process(context.Background(), restructureConfig(mem, config.ModeWrite))
+	require.ErrorContains(t, err, "files left unchanged; reproducer saved to")
+	require.Equal(t, 1, inputs)
+	require.Equal(t, 2, outputs)
+	require.Equal(t, []string{"main.tf"}, mem.Files())
+	data, err := iofs.ReadFile(mem, "main.tf")
+	require.NoError(t, err)
+	require.Equal(t, string(src), string(data))
+}
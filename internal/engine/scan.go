@@ -3,69 +3,103 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	iofs "io/fs"
+	"path"
 	"sort"
 	"strings"
 
 	"github.com/oferchen/hclalign/config"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
 	"github.com/oferchen/hclalign/patternmatching"
 )
 
 func scan(ctx context.Context, cfg *config.Config) ([]string, error) {
-	if _, err := os.Stat(cfg.Target); err != nil {
-		if os.IsNotExist(err) {
+	ws := filesystem(cfg)
+	root := ws.name(cfg.Target)
+	links, _ := ws.fsys.(internalfs.LinkFS)
+
+	var info iofs.FileInfo
+	var err error
+	if links != nil && !cfg.FollowSymlinks {
+		info, err = links.Lstat(root)
+	} else {
+		info, err = iofs.Stat(ws.fsys, root)
+	}
+	if err != nil {
+		if errors.Is(err, iofs.ErrNotExist) {
 			return nil, fmt.Errorf("target %q does not exist", cfg.Target)
 		}
 		return nil, err
 	}
-	matcher, err := patternmatching.NewMatcher(cfg.Include, cfg.Exclude, cfg.Target)
+	if info.Mode()&iofs.ModeSymlink != 0 {
+		return nil, nil
+	}
+	matcher, err := patternmatching.NewMatcher(cfg.Include, cfg.Exclude, "")
 	if err != nil {
 		return nil, err
 	}
+	if !info.IsDir() {
+		if !matcher.MatchesRel(path.Base(root), false) {
+			return nil, nil
+		}
+		if ws.root == "" {
+			return []string{root}, nil
+		}
+		return []string{cfg.Target}, nil
+	}
 
-	rootAbs, err := filepath.Abs(cfg.Target)
-	if err != nil {
-		return nil, err
+	realRoot := root
+	if links != nil {
+		if realRoot, err = links.EvalSymlinks(root); err != nil {
+			return nil, err
+		}
 	}
-	rootEval, err := filepath.EvalSymlinks(rootAbs)
-	if err != nil {
-		rootEval = rootAbs
+	relTo := func(real string) (string, bool) {
+		switch {
+		case real == realRoot:
+			return "", true
+		case realRoot == ".":
+			return real, true
+		case strings.HasPrefix(real, realRoot+"/"):
+			return strings.TrimPrefix(real, realRoot+"/"), true
+		}
+		return "", false
+	}
+	resolve := func(name string) (string, bool, error) {
+		if links == nil {
+			return name, true, nil
+		}
+		real, err := links.EvalSymlinks(name)
+		if err != nil {
+			if errors.Is(err, iofs.ErrNotExist) || errors.Is(err, internalfs.ErrOutsideRoot) {
+				return "", false, nil
+			}
+			return "", false, err
+		}
+		_, ok := relTo(real)
+		return real, ok, nil
 	}
 
 	var files []string
 	visited := make(map[string]struct{})
 
-	var walk func(context.Context, string) error
-	walk = func(ctx context.Context, dir string) error {
-		if !matcher.Matches(dir) {
-			return nil
-		}
+	var walk func(dir, rel string) error
+	walk = func(dir, rel string) error {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
-		absDir, err := filepath.Abs(dir)
-		if err != nil {
-			return err
-		}
-		realDir, err := filepath.EvalSymlinks(absDir)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return nil
-			}
+		real, ok, err := resolve(dir)
+		if err != nil || !ok {
 			return err
 		}
-		relDir, err := filepath.Rel(rootEval, realDir)
-		if err != nil || relDir == ".." || strings.HasPrefix(relDir, ".."+string(os.PathSeparator)) {
-			return nil
-		}
-		if _, ok := visited[realDir]; ok {
+		if _, ok := visited[real]; ok {
 			return nil
 		}
-		visited[realDir] = struct{}{}
+		visited[real] = struct{}{}
 
-		entries, err := os.ReadDir(dir)
+		entries, err := iofs.ReadDir(ws.fsys, dir)
 		if err != nil {
 			return err
 		}
@@ -73,77 +107,48 @@ func scan(ctx context.Context, cfg *config.Config) ([]string, error) {
 			if err := ctx.Err(); err != nil {
 				return err
 			}
-			path := filepath.Join(dir, entry.Name())
-			absPath, err := filepath.Abs(path)
-			if err != nil {
-				return err
-			}
-			path = absPath
-			info, err := os.Lstat(path)
-			if err != nil {
-				if os.IsNotExist(err) {
+			name := path.Join(dir, entry.Name())
+			entryRel := path.Join(rel, entry.Name())
+			isDir := entry.IsDir()
+			if entry.Type()&iofs.ModeSymlink != 0 {
+				if !cfg.FollowSymlinks || links == nil {
 					continue
 				}
-				return err
-			}
-			if info.Mode()&os.ModeSymlink != 0 {
-				if !cfg.FollowSymlinks {
-					continue
-				}
-				realPath, err := filepath.EvalSymlinks(path)
+				real, ok, err := resolve(name)
 				if err != nil {
-					if os.IsNotExist(err) {
-						continue
-					}
 					return err
 				}
-				rel, err := filepath.Rel(rootEval, realPath)
-				if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+				if !ok {
 					continue
 				}
-				info, err = os.Stat(realPath)
+				info, err := iofs.Stat(ws.fsys, real)
 				if err != nil {
-					if os.IsNotExist(err) {
+					if errors.Is(err, iofs.ErrNotExist) {
 						continue
 					}
 					return err
 				}
-				path = realPath
+				name, isDir = real, info.IsDir()
+				entryRel, _ = relTo(real)
 			}
-			if info.IsDir() {
-				if err := walk(ctx, path); err != nil {
+			if isDir {
+				if !matcher.MatchesRel(entryRel, true) {
+					continue
+				}
+				if err := walk(name, entryRel); err != nil {
 					return err
 				}
 				continue
 			}
-			if matcher.Matches(path) {
-				files = append(files, path)
+			if matcher.MatchesRel(entryRel, false) {
+				files = append(files, ws.path(name))
 			}
 		}
 		return nil
 	}
-
-	var info os.FileInfo
-	if cfg.FollowSymlinks {
-		info, err = os.Stat(cfg.Target)
-	} else {
-		info, err = os.Lstat(cfg.Target)
-	}
-	if err != nil {
+	if err := walk(root, ""); err != nil {
 		return nil, err
 	}
-	if info.Mode()&os.ModeSymlink != 0 && !cfg.FollowSymlinks {
-		return nil, nil
-	}
-	if info.IsDir() {
-		if err := walk(ctx, cfg.Target); err != nil {
-			return nil, err
-		}
-	} else {
-		if matcher.Matches(cfg.Target) {
-			files = append(files, cfg.Target)
-		}
-	}
 
 	sort.Strings(files)
 	return files, nil
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/oferchen/hclalign/config"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
 	"github.com/stretchr/testify/require"
 )
 
@@ -58,3 +59,99 @@ func TestScanFollowSymlinks(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []string{target, target}, files)
 }
+
+func TestScanFS(t *testing.T) {
+	t.Parallel()
+
+	mem := internalfs.NewMemFS(map[string][]byte{
+		"main.tf":                   nil,
+		"notes.txt":                 nil,
+		".terraform/modules/a.tf":   nil,
+		"modules/net/main.tf":       nil,
+		"modules/net/vendor/b.tf":   nil,
+		"vendor/github.com/c/d.tf":  nil,
+		"modules/skip/ignored.tf":   nil,
+		"modules/net/variables.tf":  nil,
+		"modules/net/README.md":     nil,
+		"modules/net/examples/e.tf": nil,
+	})
+	cfg := &config.Config{
+		Target:  ".",
+		Include: config.DefaultInclude,
+		Exclude: append([]string{"modules/skip/**"}, config.DefaultExclude...),
+		FS:      mem,
+	}
+	files, err := scan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"main.tf",
+		"modules/net/examples/e.tf",
+		"modules/net/main.tf",
+		"modules/net/variables.tf",
+		"modules/net/vendor/b.tf",
+	}, files)
+
+	cfg.Target = "modules/net"
+	files, err = scan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"modules/net/examples/e.tf",
+		"modules/net/main.tf",
+		"modules/net/variables.tf",
+	}, files)
+
+	cfg.Target = "main.tf"
+	files, err = scan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{"main.tf"}, files)
+
+	cfg.Target = "missing"
+	_, err = scan(context.Background(), cfg)
+	require.ErrorContains(t, err, `target "missing" does not exist`)
+}
+
+func TestScanFollowSymlinkDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	real := filepath.Join(dir, "modules", "net")
+	require.NoError(t, os.MkdirAll(real, 0o755))
+	main := filepath.Join(real, "main.tf")
+	require.NoError(t, os.WriteFile(main, []byte(""), 0o644))
+	require.NoError(t, os.Symlink(real, filepath.Join(dir, "net")))
+	require.NoError(t, os.Symlink(dir, filepath.Join(real, "loop")))
+
+	cfg := &config.Config{Target: dir, Include: config.DefaultInclude}
+	files, err := scan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{main}, files)
+
+	cfg.FollowSymlinks = true
+	files, err = scan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{main}, files)
+
+	cfg.Target = filepath.Join(dir, "net")
+	cfg.FollowSymlinks = false
+	files, err = scan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestScanRootedOSFS(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "modules", "net"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(""), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "modules", "net", "main.tf"), []byte(""), 0o644))
+
+	cfg := &config.Config{Target: ".", Include: config.DefaultInclude, FS: config.OSFS(dir)}
+	files, err := scan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{"main.tf", "modules/net/main.tf"}, files)
+
+	cfg.Target = dir
+	_, err = scan(context.Background(), cfg)
+	require.Error(t, err)
+}
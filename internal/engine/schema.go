@@ -5,6 +5,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/oferchen/hclalign/config"
 	"github.com/oferchen/hclalign/internal/align"
@@ -16,7 +18,11 @@ func loadSchemas(ctx context.Context, cfg *config.Config) (map[string]*align.Sch
 		return nil, nil
 	}
 	if cfg.ProvidersSchema != "" {
-		return alignschema.LoadFile(cfg.ProvidersSchema)
+		schemas, err := alignschema.LoadFile(cfg.ProvidersSchema)
+		if err != nil {
+			return nil, err
+		}
+		return indexByType(schemas), nil
 	}
 	modulePath := cfg.Target
 	if modulePath == "" {
@@ -29,5 +35,34 @@ func loadSchemas(ctx context.Context, cfg *config.Config) (map[string]*align.Sch
 	if cacheDir == "" {
 		cacheDir = filepath.Join(modulePath, ".terraform", "schema-cache")
 	}
-	return alignschema.FromTerraform(ctx, cacheDir, modulePath, cfg.NoSchemaCache)
+	bin, err := toolBinary(cfg)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := alignschema.FromTool(ctx, bin, cacheDir, modulePath, cfg.NoSchemaCache)
+	if err != nil {
+		return nil, err
+	}
+	return indexByType(schemas), nil
+}
+
+func indexByType(schemas map[string]*align.Schema) map[string]*align.Schema {
+	out := make(map[string]*align.Schema, len(schemas))
+	keys := make([]string, 0, len(schemas))
+	for k, s := range schemas {
+		out[k] = s
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		i := strings.LastIndex(k, "/")
+		if i < 0 {
+			continue
+		}
+		typ := k[i+1:]
+		if _, ok := out[typ]; !ok {
+			out[typ] = schemas[k]
+		}
+	}
+	return out
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/align"
 	"github.com/stretchr/testify/require"
 )
 
@@ -114,3 +115,12 @@ func TestLoadSchemasMissingFile(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, schemas)
 }
+
+func TestIndexByTypeKeepsInput(t *testing.T) {
+	t.Parallel()
+	s := &align.Schema{}
+	schemas := map[string]*align.Schema{"registry.terraform.io/hashicorp/aws/aws_s3_bucket": s}
+	indexed := indexByType(schemas)
+	require.Same(t, s, indexed["aws_s3_bucket"])
+	require.Len(t, schemas, 1)
+}
@@ -0,0 +1,92 @@
+// internal/engine/sort_test.go
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortBlocksIdempotentOnCases(t *testing.T) {
+	cases, err := filepath.Glob(filepath.Join("..", "..", "tests", "cases", "*", "in.tf"))
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+	for _, in := range cases {
+		name := filepath.Base(filepath.Dir(in))
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(in)
+			require.NoError(t, err)
+			path := filepath.Join(t.TempDir(), "in.tf")
+			require.NoError(t, os.WriteFile(path, src, 0o644))
+			_, first := runFile(t, goldenConfig(config.ModeWrite, sortBlocks()), path)
+			changed, second := runFile(t, goldenConfig(config.ModeWrite, sortBlocks()), path)
+			require.False(t, changed)
+			require.Equal(t, string(first), string(second))
+		})
+	}
+}
+
+func TestSortBlocksStress(t *testing.T) {
+	kinds := []string{"output", "module", "resource", "data", "locals", "variable", "provider", "terraform"}
+	rng := rand.New(rand.NewSource(1))
+	var b strings.Builder
+	const n = 400
+	for i := 0; i < n; i++ {
+		kind := kinds[rng.Intn(len(kinds))]
+		if i%7 == 0 {
+			fmt.Fprintf(&b, "# block %d\n", i)
+		}
+		switch kind {
+		case "locals", "terraform":
+			fmt.Fprintf(&b, "%s {\n  n%d = %d\n}\n", kind, i, i)
+		case "resource", "data":
+			fmt.Fprintf(&b, "%s \"t%d\" \"n%d\" {\n  v = %d\n}\n", kind, rng.Intn(5), i, i)
+		default:
+			fmt.Fprintf(&b, "%s \"n%d\" {\n  default = %d\n}\n", kind, i, i)
+		}
+		if rng.Intn(3) == 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "stress.tf")
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0o644))
+	changed, first := runFile(t, goldenConfig(config.ModeWrite, sortBlocks()), path)
+	require.True(t, changed)
+	require.Equal(t, n, strings.Count(string(first), "{\n"))
+	require.Equal(t, strings.Count(b.String(), "# block"), strings.Count(string(first), "# block"))
+
+	changed, second := runFile(t, goldenConfig(config.ModeWrite, sortBlocks()), path)
+	require.False(t, changed)
+	require.Equal(t, string(first), string(second))
+
+	lines := strings.Split(string(first), "\n")
+	for i, line := range lines {
+		var id int
+		if _, err := fmt.Sscanf(line, "# block %d", &id); err == nil {
+			next := lines[i+1] + "\n" + lines[i+2]
+			require.True(t, strings.Contains(next, fmt.Sprintf("\"n%d\"", id)) || strings.Contains(next, fmt.Sprintf("n%d =", id)), next)
+		}
+	}
+
+	rank := map[string]int{}
+	for i, kind := range config.DefaultBlockOrder {
+		rank[kind] = i
+	}
+	last := -1
+	for _, line := range lines {
+		kind, _, _ := strings.Cut(line, " ")
+		r, ok := rank[kind]
+		if !ok || strings.HasPrefix(line, " ") {
+			continue
+		}
+		require.GreaterOrEqual(t, r, last, line)
+		last = r
+	}
+}
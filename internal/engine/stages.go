@@ -0,0 +1,274 @@
+// internal/engine/stages.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/align"
+	"github.com/oferchen/hclalign/internal/diff"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
+	internalhcl "github.com/oferchen/hclalign/internal/hcl"
+	"github.com/oferchen/hclalign/internal/trace"
+	"github.com/oferchen/hclalign/internal/verify"
+)
+
+type Document struct {
+	Path  string
+	Data  []byte
+	Hints internalfs.Hints
+	Perm  iofs.FileMode
+}
+
+func (d *Document) Original() []byte {
+	return append(append([]byte(nil), d.Hints.BOM()...), d.Data...)
+}
+
+type Result struct {
+	Doc       *Document
+	Parsed    []byte
+	Formatted []byte
+	Styled    []byte
+	Changed   bool
+}
+
+type Source interface {
+	Read(ctx context.Context) (*Document, error)
+}
+
+type Formatter interface {
+	Format(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error)
+}
+
+type Aligner interface {
+	Align(ctx context.Context, doc *Document, format Formatter) (*Result, error)
+}
+
+type Verifier interface {
+	Verify(ctx context.Context, res *Result) error
+}
+
+type Sink interface {
+	Emit(ctx context.Context, res *Result) ([]byte, error)
+}
+
+type Pipeline struct {
+	Formatter Formatter
+	Aligner   Aligner
+	Verifiers []Verifier
+	Sink      Sink
+}
+
+func (p *Pipeline) Run(ctx context.Context, src Source) (bool, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return false, nil, err
+	}
+	doc, err := src.Read(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+	defer trace.Start(ctx, "file", doc.Path)()
+	if err := ctx.Err(); err != nil {
+		return false, nil, err
+	}
+	res, err := p.Aligner.Align(ctx, doc, p.Formatter)
+	if err != nil {
+		return false, nil, err
+	}
+	if res.Changed {
+		for _, v := range p.Verifiers {
+			if err := v.Verify(ctx, res); err != nil {
+				return false, nil, err
+			}
+		}
+	}
+	out, err := p.Sink.Emit(ctx, res)
+	if err != nil {
+		return false, nil, err
+	}
+	return res.Changed, out, nil
+}
+
+func NewPipeline(cfg *config.Config, format Formatter, options func(path string) *align.Options, writeFiles bool) *Pipeline {
+	p := &Pipeline{
+		Formatter: format,
+		Aligner:   &sourceAligner{options: options, idempotent: cfg.VerifyIdempotent, noFmt: cfg.NoFmt},
+		Verifiers: []Verifier{commentAuditor{cfg: cfg}},
+	}
+	if cfg.Mode == config.ModeWrite && !cfg.NoVerify {
+		p.Verifiers = append(p.Verifiers, equivalenceVerifier{opts: verifyOptions(cfg)})
+	}
+	switch {
+	case cfg.Mode == config.ModeDiff:
+		p.Sink = diffSink{}
+	case cfg.Mode == config.ModeWrite && writeFiles:
+		p.Sink = fileSink{ws: filesystem(cfg), stdout: cfg.Stdout}
+	default:
+		p.Sink = outputSink{stdout: cfg.Stdout}
+	}
+	return p
+}
+
+type FileSource string
+
+func (s FileSource) Read(ctx context.Context) (*Document, error) {
+	ws := hostWorkspace(string(s))
+	return FSSource{FS: ws.fsys, Name: ws.name(string(s)), Path: string(s)}.Read(ctx)
+}
+
+type FSSource struct {
+	FS   iofs.FS
+	Name string
+	Path string
+}
+
+func (s FSSource) Read(ctx context.Context) (*Document, error) {
+	defer trace.Start(ctx, "read", s.Path)()
+	name := s.Name
+	if name == "" {
+		name = s.Path
+	}
+	data, perm, hints, err := internalfs.ReadFSWithHints(ctx, s.FS, name)
+	if err != nil {
+		var pathErr *iofs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return &Document{Path: s.Path, Data: data, Hints: hints, Perm: perm}, nil
+}
+
+type ReaderSource struct {
+	Name   string
+	Reader io.Reader
+}
+
+func (s ReaderSource) Read(ctx context.Context) (*Document, error) {
+	defer trace.Start(ctx, "read", s.Name)()
+	data, hints, err := internalfs.ReadAllWithHints(s.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Path: s.Name, Data: data, Hints: hints}, nil
+}
+
+type BytesSource struct {
+	Name string
+	Data []byte
+}
+
+func (s BytesSource) Read(ctx context.Context) (*Document, error) {
+	return ReaderSource{Name: s.Name, Reader: bytes.NewReader(s.Data)}.Read(ctx)
+}
+
+type sourceAligner struct {
+	options    func(path string) *align.Options
+	idempotent bool
+	noFmt      bool
+}
+
+func (a *sourceAligner) run(ctx context.Context, format Formatter, opts *align.Options, data []byte, hints internalfs.Hints) ([]byte, []byte, error) {
+	formatted, parsed, err := alignSource(ctx, format, opts, data, hints)
+	if err != nil || !a.noFmt {
+		return formatted, parsed, err
+	}
+	formatted, err = internalhcl.ReorderSpans(parsed, formatted)
+	return formatted, parsed, err
+}
+
+func (a *sourceAligner) Align(ctx context.Context, doc *Document, format Formatter) (*Result, error) {
+	opts := a.options(doc.Path)
+	formatted, parsed, err := a.run(ctx, format, opts, doc.Data, doc.Hints)
+	if err != nil {
+		return nil, err
+	}
+	if a.idempotent {
+		rerun := func(ctx context.Context, opts *align.Options, data []byte, hints internalfs.Hints) ([]byte, []byte, error) {
+			return a.run(ctx, format, opts, data, hints)
+		}
+		if err := checkIdempotent(ctx, rerun, opts, formatted); err != nil {
+			return nil, err
+		}
+	}
+
+	hadNewline := len(doc.Data) > 0 && doc.Data[len(doc.Data)-1] == '\n'
+	if !hadNewline && len(formatted) > 0 && formatted[len(formatted)-1] == '\n' {
+		formatted = formatted[:len(formatted)-1]
+	}
+	styled := internalfs.ApplyHints(append([]byte(nil), formatted...), doc.Hints)
+	return &Result{
+		Doc:       doc,
+		Parsed:    parsed,
+		Formatted: formatted,
+		Styled:    styled,
+		Changed:   !bytes.Equal(doc.Original(), styled),
+	}, nil
+}
+
+type commentAuditor struct {
+	cfg *config.Config
+}
+
+func (v commentAuditor) Verify(ctx context.Context, res *Result) error {
+	return auditComments(ctx, v.cfg, res.Doc.Path, res.Parsed, res.Formatted)
+}
+
+type equivalenceVerifier struct {
+	opts verify.Options
+}
+
+func (v equivalenceVerifier) Verify(_ context.Context, res *Result) error {
+	doc := res.Doc
+	return verifyOutput(doc.Original(), internalfs.PrepareForParse(doc.Data, doc.Hints), res.Formatted, v.opts)
+}
+
+type outputSink struct {
+	stdout bool
+}
+
+func (s outputSink) Emit(_ context.Context, res *Result) ([]byte, error) {
+	if s.stdout {
+		return res.Styled, nil
+	}
+	return nil, nil
+}
+
+type fileSink struct {
+	ws     workspace
+	stdout bool
+}
+
+func (s fileSink) Emit(ctx context.Context, res *Result) ([]byte, error) {
+	if res.Changed {
+		doc := res.Doc
+		done := trace.Start(ctx, "write", doc.Path)
+		err := s.ws.fsys.WriteFile(ctx, s.ws.name(doc.Path), res.Styled, doc.Perm)
+		done()
+		if err != nil {
+			return nil, fmt.Errorf("error writing file with original permissions: %w", err)
+		}
+	}
+	return outputSink{stdout: s.stdout}.Emit(ctx, res)
+}
+
+type diffSink struct{}
+
+func (diffSink) Emit(ctx context.Context, res *Result) ([]byte, error) {
+	if !res.Changed {
+		return nil, nil
+	}
+	doc := res.Doc
+	styled := res.Styled[len(doc.Hints.BOM()):]
+	defer trace.Start(ctx, "diff", doc.Path)()
+	text, err := diff.Unified(diff.UnifiedOpts{FromFile: doc.Path, ToFile: doc.Path, Original: doc.Data, Styled: styled, Hints: doc.Hints})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(text), nil
+}
@@ -0,0 +1,157 @@
+// internal/engine/stages_test.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/formatter"
+	"github.com/oferchen/hclalign/internal/align"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineSourcesProduceSameOutput(t *testing.T) {
+	base := filepath.Join("..", "..", "tests", "cases")
+	names := []string{"simple", "comments", "crlf_bom", "heredocs", "trailing_commas", "module", "output", "unicode", "whitespace", "idempotency"}
+	modes := []struct {
+		name string
+		mode config.Mode
+	}{
+		{name: "write", mode: config.ModeWrite},
+		{name: "check", mode: config.ModeCheck},
+		{name: "diff", mode: config.ModeDiff},
+	}
+	for _, name := range names {
+		src, err := os.ReadFile(filepath.Join(base, name, "in.tf"))
+		require.NoError(t, err)
+		for _, m := range modes {
+			t.Run(name+"/"+m.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "in.tf")
+				require.NoError(t, os.WriteFile(path, src, 0o644))
+
+				cfg := &config.Config{Mode: m.mode, Stdout: true}
+				options := func(p string) *align.Options { return alignOptions(cfg, p, nil, nil, nil) }
+				sources := map[string]Source{
+					"file":   FileSource(path),
+					"reader": ReaderSource{Name: path, Reader: bytes.NewReader(src)},
+					"bytes":  BytesSource{Name: path, Data: src},
+				}
+				type outcome struct {
+					changed bool
+					out     string
+				}
+				results := map[string]outcome{}
+				for kind, source := range sources {
+					p := NewPipeline(cfg, defaultFormat(cfg), options, kind == "file")
+					changed, out, err := p.Run(context.Background(), source)
+					require.NoError(t, err, kind)
+					results[kind] = outcome{changed: changed, out: string(out)}
+				}
+				require.Equal(t, results["file"], results["reader"])
+				require.Equal(t, results["file"], results["bytes"])
+
+				written, err := os.ReadFile(path)
+				require.NoError(t, err)
+				if m.mode == config.ModeWrite {
+					require.Equal(t, results["file"].out, string(written))
+				} else {
+					require.Equal(t, src, written)
+				}
+			})
+		}
+	}
+}
+
+func TestPipelineStages(t *testing.T) {
+	cfg := &config.Config{Mode: config.ModeWrite}
+	p := NewPipeline(cfg, defaultFormat(cfg), func(string) *align.Options { return &align.Options{} }, true)
+	require.IsType(t, fileSink{}, p.Sink)
+	require.Len(t, p.Verifiers, 2)
+
+	cfg.NoVerify = true
+	p = NewPipeline(cfg, defaultFormat(cfg), func(string) *align.Options { return &align.Options{} }, false)
+	require.IsType(t, outputSink{}, p.Sink)
+	require.Len(t, p.Verifiers, 1)
+
+	cfg.Mode = config.ModeDiff
+	p = NewPipeline(cfg, defaultFormat(cfg), func(string) *align.Options { return &align.Options{} }, true)
+	require.IsType(t, diffSink{}, p.Sink)
+
+	_, _, err := p.Run(context.Background(), FileSource(filepath.Join(t.TempDir(), "missing.tf")))
+	require.ErrorContains(t, err, "error reading file: ")
+	require.NotContains(t, err.Error(), "missing.tf")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = p.Run(ctx, BytesSource{Name: "mem", Data: []byte("a = 1\n")})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPipelineFormatterStage(t *testing.T) {
+	cfg := &config.Config{Mode: config.ModeCheck, Stdout: true}
+	p := NewPipeline(cfg, defaultFormat(cfg), func(string) *align.Options { return alignOptions(cfg, "mem", nil, nil, nil) }, false)
+	var calls []string
+	p.Formatter = FormatterFunc(func(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+		calls = append(calls, string(src))
+		return formatter.Format(src, "")
+	})
+
+	src := "variable \"a\" {\n  type = string\n  description = \"d\"\n}\n"
+	changed, out, err := p.Run(context.Background(), BytesSource{Name: "mem", Data: []byte(src)})
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n", string(out))
+	require.Len(t, calls, 2)
+	require.Equal(t, src, calls[0])
+	require.Equal(t, "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n", calls[1])
+
+	calls = nil
+	_, _, err = p.Run(context.Background(), BytesSource{Name: "mem", Data: out})
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+}
+
+func TestProcessMemFS(t *testing.T) {
+	src := []byte("variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n")
+	want := "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n"
+	newCfg := func(mem *internalfs.MemFS, mode config.Mode) *config.Config {
+		return &config.Config{
+			Target:      ".",
+			Mode:        mode,
+			Include:     config.DefaultInclude,
+			Exclude:     config.DefaultExclude,
+			Order:       config.CanonicalOrder,
+			Concurrency: 1,
+			FS:          mem,
+		}
+	}
+
+	mem := internalfs.NewMemFS(map[string][]byte{"main.tf": src, "vendor/x.tf": src})
+	changed, err := Process(context.Background(), newCfg(mem, config.ModeCheck))
+	require.NoError(t, err)
+	require.True(t, changed)
+	got, err := iofs.ReadFile(mem, "main.tf")
+	require.NoError(t, err)
+	require.Equal(t, src, got)
+
+	changed, err = Process(context.Background(), newCfg(mem, config.ModeWrite))
+	require.NoError(t, err)
+	require.True(t, changed)
+	got, err = iofs.ReadFile(mem, "main.tf")
+	require.NoError(t, err)
+	require.Equal(t, want, string(got))
+	got, err = iofs.ReadFile(mem, "vendor/x.tf")
+	require.NoError(t, err)
+	require.Equal(t, src, got)
+
+	changed, err = Process(context.Background(), newCfg(mem, config.ModeCheck))
+	require.NoError(t, err)
+	require.False(t, changed)
+}
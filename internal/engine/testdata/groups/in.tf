@@ -0,0 +1,30 @@
+resource "aws_instance" "web" {
+  depends_on    = [aws_vpc.main]
+  ami           = "ami-123"
+  instance_type = "t3.micro"
+  lifecycle {
+    create_before_destroy = true
+  }
+  count = 2
+  ebs_block_device {
+    device_name = "/dev/sdb"
+  }
+}
+
+module "network" {
+  depends_on = [aws_vpc.main]
+  cidr       = "10.0.0.0/16"
+  source     = "./network"
+  for_each   = toset(["a", "b"])
+}
+
+data "aws_ami" "ubuntu" {
+  lifecycle {
+    postcondition {
+      condition     = self.id != ""
+      error_message = "No AMI."
+    }
+  }
+  most_recent = true
+  provider    = aws.west
+}
@@ -0,0 +1,38 @@
+resource "aws_instance" "web" {
+  count = 2
+
+  ami           = "ami-123"
+  instance_type = "t3.micro"
+
+  ebs_block_device {
+    device_name = "/dev/sdb"
+  }
+
+  lifecycle {
+    create_before_destroy = true
+  }
+
+  depends_on = [aws_vpc.main]
+}
+
+module "network" {
+  source   = "./network"
+  for_each = toset(["a", "b"])
+
+  cidr = "10.0.0.0/16"
+
+  depends_on = [aws_vpc.main]
+}
+
+data "aws_ami" "ubuntu" {
+  provider = aws.west
+
+  most_recent = true
+
+  lifecycle {
+    postcondition {
+      condition     = self.id != ""
+      error_message = "No AMI."
+    }
+  }
+}
@@ -0,0 +1,22 @@
+resource "aws_instance" "web" {
+  ami                    = "ami-123"
+  vpc_security_group_ids = [aws_security_group.base.id, aws_security_group.web.id]
+  subnet_ids             = [aws_subnet.b.id, aws_subnet.a.id]
+
+  depends_on = [
+    aws_iam_role.web,
+    aws_iam_role_policy.web,
+  ]
+
+  lifecycle {
+    ignore_changes = [ami, tags]
+  }
+}
+
+resource "aws_security_group_rule" "ingress" {
+  cidr_blocks = [
+    "10.0.1.0/24",
+    "10.0.2.0/24"
+  ]
+  ipv6_cidr_blocks = [cidrsubnet(var.v6, 8, 2), cidrsubnet(var.v6, 8, 1)]
+}
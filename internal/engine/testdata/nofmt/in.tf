@@ -0,0 +1,33 @@
+locals {
+  a    =   1
+  bb = 2
+
+
+}
+
+# keep with variable
+variable "region" {
+  default =    "us-east-1"
+  type = string   # trailing
+
+
+  # lead for description
+  description   = "deployment region"
+
+  validation {
+     error_message = "must be a region"
+     condition = length(var.region) > 0
+  }
+}
+
+resource "aws_instance"   "web" {
+  ami           = "ami-123"
+    instance_type = "t3.micro"
+}
+
+variable "one_line" { type = string }
+
+variable "unchanged" {
+	description = "tabs stay"
+	type        = number
+}
@@ -0,0 +1,23 @@
+variable "tags" {
+  default = {
+    team = "platform"
+    # Shown in the console.
+    Name = "web"
+    env  = "prod"
+  }
+}
+
+resource "aws_instance" "web" {
+  ami = "ami-123"
+
+  tags = merge(var.tags, {
+    role                 = "web" # primary role
+    "kubernetes.io/role" = "node"
+    (local.cost_key)     = "shared"
+  })
+
+  labels = {
+    zone   = "a"
+    region = "eu"
+  }
+}
@@ -0,0 +1,25 @@
+# Core resources.
+
+# Name prefix for every resource.
+variable "prefix" {
+  type = string
+}
+
+resource "null_resource" "this" {
+  triggers = {
+    name = var.prefix
+  }
+}
+
+# Pin the null provider.
+terraform {
+  required_providers {
+    null = {
+      source = "hashicorp/null"
+    }
+  }
+}
+
+output "id" {
+  value = null_resource.this.id
+}
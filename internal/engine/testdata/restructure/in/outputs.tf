@@ -0,0 +1,4 @@
+variable "count_hint" {
+  default = 1
+  type    = number
+}
@@ -0,0 +1,7 @@
+# Core resources.
+
+resource "null_resource" "this" {
+  triggers = {
+    name = var.prefix
+  }
+}
@@ -0,0 +1,9 @@
+# Name prefix for every resource.
+variable "prefix" {
+  type = string
+}
+
+variable "count_hint" {
+  type    = number
+  default = 1
+}
@@ -0,0 +1,13 @@
+# Pin the null provider.
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    null = {
+      source = "hashicorp/null"
+    }
+    random = {
+      source = "hashicorp/random"
+    }
+  }
+}
@@ -0,0 +1,35 @@
+# File header.
+
+output "b" {
+  value = 1
+}
+# about vpc10
+resource "aws_vpc" "vpc10" {}
+
+resource "aws_vpc" "vpc2" {}
+
+# ---- variables ----
+
+variable "with_default" {
+  default = 1
+}
+variable "b_required" {}
+variable "a_required" {
+  type = string
+}
+moved {
+  from = a.b
+  to   = a.c
+}
+terraform {
+  required_version = ">= 1.5"
+}
+locals {
+  x = 1
+}
+provider "aws" {}
+data "aws_ami" "x" {}
+module "m" {
+  source = "./m"
+}
+# trailing comment
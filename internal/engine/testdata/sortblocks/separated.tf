@@ -0,0 +1,45 @@
+# File header.
+
+terraform {
+  required_version = ">= 1.5"
+}
+
+provider "aws" {}
+
+variable "a_required" {
+  type = string
+}
+
+variable "b_required" {}
+
+# ---- variables ----
+
+variable "with_default" {
+  default = 1
+}
+
+locals {
+  x = 1
+}
+
+data "aws_ami" "x" {}
+
+resource "aws_vpc" "vpc2" {}
+
+# about vpc10
+resource "aws_vpc" "vpc10" {}
+
+module "m" {
+  source = "./m"
+}
+
+output "b" {
+  value = 1
+}
+
+moved {
+  from = a.b
+  to   = a.c
+}
+
+# trailing comment
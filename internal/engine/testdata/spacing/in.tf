@@ -0,0 +1,42 @@
+# header comment
+
+
+terraform {
+
+  required_version = ">= 1.0"
+
+
+
+  backend "s3" {
+    bucket = "x"
+
+  }
+
+}
+variable "a" {
+  type = string
+}
+# attached to b
+variable "b" {
+  type = string
+}
+
+
+
+# ---- section ----
+
+
+
+locals {
+  a = 1
+
+
+  b = <<EOT
+x
+
+
+y
+EOT
+} # trailing
+output "o" { value = 1 }
+output "p" { value = 2 }
@@ -0,0 +1,33 @@
+# Network module wiring.
+
+output "subnet_ids" {
+  value = module.network.subnet_ids
+}
+
+module "network" {
+  source = "./network"
+  cidr   = local.cidr
+  zones  = data.aws_availability_zones.all.names
+}
+
+# Zones come from the provider region.
+data "aws_availability_zones" "all" {
+  provider = aws.west
+}
+
+locals {
+  cidr = cidrsubnet(var.base_cidr, 4, 1)
+}
+
+provider "aws" {
+  alias  = "west"
+  region = var.region
+}
+
+variable "region" {
+  type = string
+}
+
+variable "base_cidr" {
+  type = string
+}
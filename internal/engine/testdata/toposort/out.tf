@@ -0,0 +1,33 @@
+# Network module wiring.
+
+variable "region" {
+  type = string
+}
+
+provider "aws" {
+  alias  = "west"
+  region = var.region
+}
+
+# Zones come from the provider region.
+data "aws_availability_zones" "all" {
+  provider = aws.west
+}
+
+variable "base_cidr" {
+  type = string
+}
+
+locals {
+  cidr = cidrsubnet(var.base_cidr, 4, 1)
+}
+
+module "network" {
+  source = "./network"
+  cidr   = local.cidr
+  zones  = data.aws_availability_zones.all.names
+}
+
+output "subnet_ids" {
+  value = module.network.subnet_ids
+}
@@ -0,0 +1,151 @@
+// internal/engine/transform.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/align"
+	"github.com/oferchen/hclalign/internal/diag"
+	"github.com/oferchen/hclalign/internal/diff"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
+	internalhcl "github.com/oferchen/hclalign/internal/hcl"
+	"github.com/oferchen/hclalign/internal/trace"
+)
+
+func alignOptions(cfg *config.Config, path string, schemas map[string]*align.Schema, filter *align.AddressFilter, sorter *align.BlockSort) *align.Options {
+	var typesMap map[string]struct{}
+	if cfg.Types != nil {
+		typesMap = make(map[string]struct{}, len(cfg.Types))
+		for _, t := range cfg.Types {
+			typesMap[t] = struct{}{}
+		}
+	}
+	return &align.Options{
+		Path:            path,
+		Schemas:         schemas,
+		Types:           typesMap,
+		Filter:          filter,
+		Spacing:         cfg.Spacing(),
+		GroupSeparators: cfg.GroupSeparators,
+		BlockSort:       sorter,
+		TopoSort:        cfg.TopoSort,
+		SortObjectKeys:  cfg.SortObjectKeys,
+		ObjectKeyAttrs:  cfg.ObjectKeyAttrs,
+		SortLists:       cfg.SortLists,
+		ListAttrs:       cfg.ListAttrs,
+	}
+}
+
+func addressFilter(cfg *config.Config) (*align.AddressFilter, error) {
+	if len(cfg.Only) == 0 && len(cfg.Skip) == 0 {
+		return nil, nil
+	}
+	return align.NewAddressFilter(cfg.Only, cfg.Skip)
+}
+
+func blockSort(cfg *config.Config) (*align.BlockSort, error) {
+	if !cfg.SortBlocks {
+		return nil, nil
+	}
+	return align.NewBlockSort(cfg.BlockOrder, cfg.BlockSort)
+}
+
+func reportFilter(filter *align.AddressFilter) {
+	if !filter.Active() {
+		return
+	}
+	matched, unmatched := filter.Counts()
+	warnMu.Lock()
+	defer warnMu.Unlock()
+	fmt.Fprintf(warnOut, "summary: %d blocks matched address filters, %d unmatched\n", matched, unmatched)
+}
+
+func alignSource(ctx context.Context, format Formatter, opts *align.Options, data []byte, hints internalfs.Hints) ([]byte, []byte, error) {
+	done := trace.Start(ctx, "pre-fmt", opts.Path)
+	formatted, _, err := format.Format(ctx, data)
+	done()
+	if err != nil {
+		return nil, nil, parseError(ctx, opts.Path, data, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	parseData := internalfs.PrepareForParse(formatted, hints)
+	done = trace.Start(ctx, "parse", opts.Path)
+	file, diags := hclwrite.ParseConfig(parseData, opts.Path, hcl.InitialPos)
+	done()
+	if diags.HasErrors() {
+		return nil, nil, parseError(ctx, opts.Path, parseData, diags)
+	}
+	if testHookAfterParse != nil {
+		testHookAfterParse()
+	}
+	before := file.Bytes()
+	if trace.FromContext(ctx) != nil {
+		traced := *opts
+		traced.Trace = func(strategy string) func() {
+			return trace.Start(ctx, "align:"+strategy, opts.Path)
+		}
+		opts = &traced
+	}
+	done = trace.Start(ctx, "align", opts.Path)
+	err = align.Apply(file, opts)
+	done()
+	if err != nil {
+		return nil, nil, err
+	}
+	if testHookAfterReorder != nil {
+		testHookAfterReorder()
+	}
+
+	after := file.Bytes()
+	if opts.Spacing.Enabled() {
+		done = trace.Start(ctx, "spacing", opts.Path)
+		after = internalhcl.ApplySpacing(file.BuildTokens(nil), opts.Spacing).Bytes()
+		done()
+	}
+	if !bytes.Equal(before, after) {
+		done = trace.Start(ctx, "post-fmt", opts.Path)
+		formatted, _, err = format.Format(ctx, after)
+		done()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return formatted, parseData, nil
+}
+
+func parseError(ctx context.Context, path string, src []byte, err error) error {
+	var diags hcl.Diagnostics
+	if errors.As(err, &diags) {
+		e := &diag.Error{Path: path, Src: src, Diags: diags, Color: diag.ColorFromContext(ctx)}
+		diag.ReportFromContext(ctx).Add(e.Diagnostics()...)
+		return e
+	}
+	return fmt.Errorf("parsing error: %w", err)
+}
+
+func checkIdempotent(ctx context.Context, run func(context.Context, *align.Options, []byte, internalfs.Hints) ([]byte, []byte, error), opts *align.Options, first []byte) error {
+	rerun := *opts
+	rerun.Filter = opts.Filter.Clone()
+	second, _, err := run(ctx, &rerun, first, internalfs.Hints{})
+	if err != nil {
+		return fmt.Errorf("idempotency check: %w", err)
+	}
+	if bytes.Equal(first, second) {
+		return nil
+	}
+	text, err := diff.Unified(diff.UnifiedOpts{FromFile: opts.Path + " (pass 1)", ToFile: opts.Path + " (pass 2)", Original: first, Styled: second})
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("not idempotent: second pass differs from first:\n%s", text)
+}
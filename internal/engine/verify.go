@@ -0,0 +1,125 @@
+// internal/engine/verify.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/diag"
+	"github.com/oferchen/hclalign/internal/verify"
+)
+
+var (
+	verifyEquivalent = verify.Equivalent
+	verifyFiles      = verify.EquivalentFiles
+
+	warnOut io.Writer = os.Stderr
+	warnMu  sync.Mutex
+)
+
+func verifyOptions(cfg *config.Config) verify.Options {
+	lists := cfg.ListAttrs
+	if len(lists) == 0 {
+		lists = config.DefaultListAttrs
+	}
+	return verify.Options{
+		ObjectKeys:     cfg.SortObjectKeys,
+		ObjectKeyAttrs: cfg.ObjectKeyAttrs,
+		Lists:          cfg.SortLists,
+		ListAttrs:      lists,
+	}
+}
+
+func verifyOutput(input, before, after []byte, opts verify.Options) error {
+	err := verifyEquivalent(before, after, opts)
+	if err == nil {
+		return nil
+	}
+	repro, saveErr := saveReproducer(input)
+	if saveErr != nil {
+		return fmt.Errorf("internal error: %w (file left unchanged; saving reproducer failed: %v)", err, saveErr)
+	}
+	return fmt.Errorf("internal error: %w (file left unchanged; reproducer saved to %s, please report it)", err, repro)
+}
+
+func verifyRestructure(inputs, outputs [][]byte, opts verify.Options) error {
+	err := verifyFiles(inputs, outputs, opts)
+	if err == nil {
+		return nil
+	}
+	repro, saveErr := saveReproducer(bytes.Join(inputs, []byte("\n")))
+	if saveErr != nil {
+		return fmt.Errorf("internal error: %w (files left unchanged; saving reproducer failed: %v)", err, saveErr)
+	}
+	return fmt.Errorf("internal error: %w (files left unchanged; reproducer saved to %s, please report it)", err, repro)
+}
+
+func saveReproducer(data []byte) (string, error) {
+	f, err := os.CreateTemp("", "hclalign-repro-*.tf")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func auditComments(ctx context.Context, cfg *config.Config, path string, before, after []byte) error {
+	if cfg.Mode != config.ModeCheck && !cfg.StrictComments {
+		return nil
+	}
+	findings, err := verify.AuditComments(before, after)
+	if err != nil {
+		return fmt.Errorf("comment audit: %w", err)
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+	diag.ReportFromContext(ctx).Add(commentDiagnostics(path, findings, cfg.StrictComments)...)
+	if cfg.StrictComments {
+		return &verify.CommentError{Findings: findings}
+	}
+	warnMu.Lock()
+	defer warnMu.Unlock()
+	for _, f := range findings {
+		fmt.Fprintf(warnOut, "warning: %s: %s\n", path, f)
+	}
+	return nil
+}
+
+func commentDiagnostics(path string, findings []verify.CommentFinding, strict bool) []diag.Diagnostic {
+	severity := "warning"
+	if strict {
+		severity = "error"
+	}
+	out := make([]diag.Diagnostic, len(findings))
+	for i, f := range findings {
+		code, summary := "comment-moved", "Comment changed owner"
+		if f.Dropped {
+			code, summary = "comment-dropped", "Comment dropped"
+		}
+		rng := f.Range
+		out[i] = diag.Diagnostic{
+			Code:     code,
+			Severity: severity,
+			Summary:  summary,
+			Detail:   f.String(),
+			Range: &diag.Range{
+				Filename: path,
+				Start:    diag.Pos{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+				End:      diag.Pos{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+			},
+		}
+	}
+	return out
+}
@@ -0,0 +1,291 @@
+// internal/fmt/batch.go
+package terraformfmt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oferchen/hclalign/formatter"
+	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/tool"
+)
+
+const (
+	DefaultBatchSize = 64
+	DefaultLinger    = 2 * time.Millisecond
+)
+
+func DefaultWorkers() int {
+	return runtime.NumCPU()
+}
+
+type batchResult struct {
+	data []byte
+	err  error
+}
+
+type batchRequest struct {
+	ctx  context.Context
+	src  []byte
+	done chan batchResult
+}
+
+type Batcher struct {
+	bin     tool.Binary
+	size    int
+	linger  time.Duration
+	sem     chan struct{}
+	reqs    chan *batchRequest
+	wake    chan struct{}
+	active  atomic.Int64
+	running atomic.Int64
+	wg      sync.WaitGroup
+	once    sync.Once
+	closed  chan struct{}
+}
+
+func NewBatcher(bin tool.Binary, workers, size int) *Batcher {
+	return newBatcher(bin, workers, size, DefaultLinger)
+}
+
+func newBatcher(bin tool.Binary, workers, size int, linger time.Duration) *Batcher {
+	if workers < 1 {
+		workers = DefaultWorkers()
+	}
+	if size < 1 {
+		size = DefaultBatchSize
+	}
+	b := &Batcher{
+		bin:    bin,
+		size:   size,
+		linger: linger,
+		sem:    make(chan struct{}, workers),
+		reqs:   make(chan *batchRequest),
+		wake:   make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.collect()
+	return b
+}
+
+func (b *Batcher) Format(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, internalfs.Hints{}, err
+	}
+	if !b.bin.Available() {
+		warnFallback(b.bin)
+		return formatter.Format(src, "")
+	}
+	hints := internalfs.DetectHintsFromBytes(src)
+	prepared := internalfs.PrepareForParse(src, hints)
+	if len(prepared) > 0 && !utf8.Valid(prepared) {
+		return nil, hints, fmt.Errorf("input is not valid UTF-8")
+	}
+	req := &batchRequest{ctx: ctx, src: prepared, done: make(chan batchResult, 1)}
+	select {
+	case b.reqs <- req:
+	case <-b.closed:
+		return nil, hints, fmt.Errorf("fmt batcher closed")
+	case <-ctx.Done():
+		return nil, hints, ctx.Err()
+	}
+	select {
+	case res := <-req.done:
+		return res.data, hints, res.err
+	case <-ctx.Done():
+		return nil, hints, ctx.Err()
+	}
+}
+
+func (b *Batcher) Hold() func() {
+	if b == nil {
+		return func() {}
+	}
+	b.active.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.active.Add(-1)
+			select {
+			case b.wake <- struct{}{}:
+			default:
+			}
+		})
+	}
+}
+
+func (b *Batcher) ready(pending int) bool {
+	return int64(pending)+b.running.Load() >= b.active.Load()
+}
+
+func (b *Batcher) Close() {
+	b.once.Do(func() {
+		close(b.closed)
+		b.wg.Wait()
+	})
+}
+
+func (b *Batcher) collect() {
+	defer b.wg.Done()
+	var pending []*batchRequest
+	timer := time.NewTimer(b.linger)
+	timer.Stop()
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		batch := pending
+		pending = nil
+		b.running.Add(int64(len(batch)))
+		b.sem <- struct{}{}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			defer func() { <-b.sem }()
+			b.runBatch(batch)
+		}()
+	}
+	for {
+		select {
+		case req := <-b.reqs:
+			if len(pending) == 0 {
+				timer.Reset(b.linger)
+			}
+			pending = append(pending, req)
+			if len(pending) >= b.size || b.ready(len(pending)) {
+				timer.Stop()
+				flush()
+			}
+		case <-b.wake:
+			if len(pending) > 0 && b.ready(len(pending)) {
+				timer.Stop()
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		case <-b.closed:
+			timer.Stop()
+			flush()
+			return
+		}
+	}
+}
+
+func (b *Batcher) reply(req *batchRequest, res batchResult) {
+	b.running.Add(-1)
+	req.done <- res
+}
+
+func (b *Batcher) runBatch(batch []*batchRequest) {
+	bin := b.bin.Path
+	live := batch[:0:0]
+	for _, req := range batch {
+		if err := req.ctx.Err(); err != nil {
+			b.reply(req, batchResult{err: err})
+			continue
+		}
+		live = append(live, req)
+	}
+	if len(live) == 0 {
+		return
+	}
+	if len(live) == 1 {
+		data, err := runBinary(live[0].ctx, bin, live[0].src)
+		b.reply(live[0], batchResult{data: data, err: err})
+		return
+	}
+	ctx, cancel := batchContext(live)
+	defer cancel()
+	outs, err := formatDir(ctx, bin, live)
+	if err != nil {
+		for _, req := range live {
+			data, err := runBinary(req.ctx, bin, req.src)
+			b.reply(req, batchResult{data: data, err: err})
+		}
+		return
+	}
+	for i, req := range live {
+		b.reply(req, batchResult{data: outs[i]})
+	}
+}
+
+func batchContext(batch []*batchRequest) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var remaining atomic.Int64
+	remaining.Store(int64(len(batch)))
+	stops := make([]func() bool, len(batch))
+	for i, req := range batch {
+		stops[i] = context.AfterFunc(req.ctx, func() {
+			if remaining.Add(-1) == 0 {
+				cancel()
+			}
+		})
+	}
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel()
+	}
+}
+
+func formatDir(ctx context.Context, bin string, batch []*batchRequest) ([][]byte, error) {
+	dir, err := os.MkdirTemp("", "hclalign-fmt-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	paths := make([]string, len(batch))
+	for i, req := range batch {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%05d.tf", i))
+		if err := os.WriteFile(paths[i], req.src, 0o600); err != nil {
+			return nil, err
+		}
+	}
+	cmd := exec.CommandContext(ctx, bin, "fmt", "-no-color", "-list=false", "-write=true", dir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmtError(bin, err, stderr.String())
+	}
+	outs := make([][]byte, len(batch))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		outs[i] = trimFormatted(data)
+	}
+	return outs, nil
+}
+
+func runBinary(ctx context.Context, bin string, src []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, bin, "fmt", "-no-color", "-list=false", "-write=false", "-")
+	cmd.Stdin = bytes.NewReader(src)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmtError(bin, err, stderr.String())
+	}
+	return trimFormatted(stdout.Bytes()), nil
+}
+
+func trimFormatted(formatted []byte) []byte {
+	if len(formatted) > 0 {
+		formatted = bytes.TrimRight(formatted, "\n")
+		formatted = append(formatted, '\n')
+	}
+	return formatted
+}
@@ -0,0 +1,186 @@
+// internal/fmt/batch_test.go
+package terraformfmt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oferchen/hclalign/internal/tool"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeTerraform = `#!/bin/sh
+echo x >> "$HCLALIGN_FAKE_LOG"
+for a; do last=$a; done
+if [ "$last" = "-" ]; then
+  input=$(cat)
+  case "$input" in *invalid*) echo "invalid" >&2; exit 1;; esac
+  printf '%s\n' "$input"
+  exit 0
+fi
+for f in "$last"/*.tf; do
+  if grep -q invalid "$f"; then echo "invalid" >&2; exit 1; fi
+done
+`
+
+func installFakeTerraform(tb testing.TB) (tool.Binary, string) {
+	tb.Helper()
+	dir := tb.TempDir()
+	script := filepath.Join(dir, "terraform")
+	require.NoError(tb, os.WriteFile(script, []byte(fakeTerraform), 0o755))
+	logPath := filepath.Join(dir, "calls.log")
+	tb.Setenv("HCLALIGN_FAKE_LOG", logPath)
+	return tool.Binary{Kind: tool.Terraform, Path: script}, logPath
+}
+
+func countCalls(tb testing.TB, logPath string) int {
+	tb.Helper()
+	data, err := os.ReadFile(logPath)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	require.NoError(tb, err)
+	return strings.Count(string(data), "\n")
+}
+
+func formatConcurrently(tb testing.TB, format func(context.Context, []byte) ([]byte, error), n int) [][]byte {
+	tb.Helper()
+	outs := make([][]byte, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src := []byte(fmt.Sprintf("variable \"v%d\" {\n  type = string\n}\n", i))
+			outs[i], errs[i] = format(context.Background(), src)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(tb, err)
+	}
+	return outs
+}
+
+func TestBatcherSingleInvocationPerBatch(t *testing.T) {
+	bin, logPath := installFakeTerraform(t)
+	b := newBatcher(bin, 2, 16, time.Hour)
+	defer b.Close()
+
+	releases := make(chan func(), 16)
+	for i := 0; i < 16; i++ {
+		releases <- b.Hold()
+	}
+	outs := formatConcurrently(t, func(ctx context.Context, src []byte) ([]byte, error) {
+		defer (<-releases)()
+		out, _, err := b.Format(ctx, src)
+		return out, err
+	}, 16)
+	for i, out := range outs {
+		require.Equal(t, fmt.Sprintf("variable \"v%d\" {\n  type = string\n}\n", i), string(out))
+	}
+	require.Less(t, countCalls(t, logPath), 16)
+}
+
+func TestBatcherSingleCallerSkipsLinger(t *testing.T) {
+	bin, logPath := installFakeTerraform(t)
+	b := newBatcher(bin, 1, 16, time.Hour)
+	defer b.Close()
+
+	out, _, err := b.Format(context.Background(), []byte("a = 1\n"))
+	require.NoError(t, err)
+	require.Equal(t, "a = 1\n", string(out))
+
+	release := b.Hold()
+	defer release()
+	out, _, err = b.Format(context.Background(), []byte("b = 2\n"))
+	require.NoError(t, err)
+	require.Equal(t, "b = 2\n", string(out))
+	require.Equal(t, 2, countCalls(t, logPath))
+}
+
+func TestBatcherFallsBackPerFileOnError(t *testing.T) {
+	bin, _ := installFakeTerraform(t)
+	b := NewBatcher(bin, 1, 4)
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src := "a = 1\n"
+			if i == 2 {
+				src = "invalid\n"
+			}
+			_, _, errs[i] = b.Format(context.Background(), []byte(src))
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if i == 2 {
+			require.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestBatcherWithoutBinaryUsesGoFormatter(t *testing.T) {
+	b := NewBatcher(tool.Binary{}, 1, 1)
+	defer b.Close()
+	out, _, err := b.Format(context.Background(), []byte("a=1\n"))
+	require.NoError(t, err)
+	require.Equal(t, "a = 1\n", string(out))
+}
+
+func TestBatcherClosedAndCanceled(t *testing.T) {
+	bin, _ := installFakeTerraform(t)
+	b := NewBatcher(bin, 0, 0)
+	b.Close()
+	b.Close()
+	_, _, err := b.Format(context.Background(), []byte("a = 1\n"))
+	require.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b = NewBatcher(bin, 1, 1)
+	defer b.Close()
+	_, _, err = b.Format(ctx, []byte("a = 1\n"))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBatcherCancelStopsBinary(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 30\n"), 0o755))
+	b := NewBatcher(tool.Binary{Kind: tool.Terraform, Path: script}, 1, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = b.Format(ctx, []byte("a = 1\n"))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+
+	start := time.Now()
+	b.Close()
+	require.Less(t, time.Since(start), 10*time.Second)
+}
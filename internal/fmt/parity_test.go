@@ -8,22 +8,55 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/oferchen/hclalign/internal/tool"
 	"github.com/stretchr/testify/require"
 )
 
-func TestFixturesMatchTerraform(t *testing.T) {
-	if _, err := exec.LookPath("terraform"); err != nil {
-		t.Skip("terraform binary not found")
+func TestGoldensMatchGoFormatter(t *testing.T) {
+	ins, err := filepath.Glob(filepath.Join("testdata", "parity", "*", "in.tf"))
+	require.NoError(t, err)
+	require.NotEmpty(t, ins)
+	var bin tool.Binary
+	if path, err := exec.LookPath("terraform"); err == nil {
+		bin = tool.Binary{Kind: tool.Terraform, Path: path}
 	}
+	for _, in := range ins {
+		name := filepath.Base(filepath.Dir(in))
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(in)
+			require.NoError(t, err)
+			want, err := os.ReadFile(filepath.Join(filepath.Dir(in), "out.tf"))
+			require.NoError(t, err)
+
+			got, _, err := Format(context.Background(), bin, src, in, string(StrategyGo))
+			require.NoError(t, err)
+			require.Equal(t, string(want), string(got))
+
+			again, _, err := Format(context.Background(), bin, got, in, string(StrategyGo))
+			require.NoError(t, err)
+			require.Equal(t, string(got), string(again))
+
+			if !bin.Available() {
+				return
+			}
+			binFmt, _, err := Format(context.Background(), bin, src, in, string(StrategyBinary))
+			require.NoError(t, err)
+			require.Equal(t, string(want), string(binFmt))
+		})
+	}
+}
+
+func TestFixturesMatchTerraform(t *testing.T) {
+	bin := terraformBin(t)
 	casesDir := filepath.Join("..", "..", "tests", "cases")
 	globs, err := filepath.Glob(filepath.Join(casesDir, "*", "in.tf"))
 	require.NoError(t, err)
 	for _, in := range globs {
 		src, err := os.ReadFile(in)
 		require.NoError(t, err)
-		goFmt, _, err := Format(context.Background(), src, in, string(StrategyGo))
+		goFmt, _, err := Format(context.Background(), bin, src, in, string(StrategyGo))
 		require.NoError(t, err)
-		binFmt, _, err := Format(context.Background(), src, in, string(StrategyBinary))
+		binFmt, _, err := Format(context.Background(), bin, src, in, string(StrategyBinary))
 		require.NoError(t, err)
 		require.Equalf(t, binFmt, goFmt, "fixture %s", in)
 	}
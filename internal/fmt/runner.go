@@ -4,30 +4,28 @@ package terraformfmt
 import (
 	"context"
 	"log"
-	"os/exec"
 	"sync"
 
 	"github.com/oferchen/hclalign/formatter"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/tool"
 )
 
-var terraformPath string
-var terraformPathOnce sync.Once
-
-func terraformBinary() string {
-	terraformPathOnce.Do(func() {
-		terraformPath, _ = exec.LookPath("terraform")
-	})
-	return terraformPath
-}
-
-func Run(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+func Run(ctx context.Context, bin tool.Binary, src []byte) ([]byte, internalfs.Hints, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, internalfs.Hints{}, err
 	}
-	if terraformBinary() != "" {
-		return formatBinary(ctx, src)
+	if bin.Available() {
+		return formatBinary(ctx, bin, src)
 	}
-	log.Printf("terraform binary not found; using Go formatter")
+	warnFallback(bin)
 	return formatter.Format(src, "")
 }
+
+var fallbackOnce sync.Once
+
+func warnFallback(bin tool.Binary) {
+	fallbackOnce.Do(func() {
+		log.Printf("%s binary not found; using Go formatter", bin.Name())
+	})
+}
@@ -2,14 +2,15 @@
 package terraformfmt
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"os/exec"
+	"path/filepath"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/oferchen/hclalign/formatter"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/tool"
 )
 
 type Strategy string
@@ -18,9 +19,19 @@ const (
 	StrategyAuto   Strategy = "auto"
 	StrategyBinary Strategy = "binary"
 	StrategyGo     Strategy = "go"
+	StrategyNone   Strategy = "none"
 )
 
-func Format(ctx context.Context, src []byte, filename, strategy string) ([]byte, internalfs.Hints, error) {
+func ValidateStrategy(strategy string) error {
+	switch Strategy(strategy) {
+	case StrategyAuto, StrategyBinary, StrategyGo, StrategyNone, "":
+		return nil
+	default:
+		return fmt.Errorf("unknown fmt strategy %q: must be auto, binary, go or none", strategy)
+	}
+}
+
+func Format(ctx context.Context, bin tool.Binary, src []byte, filename, strategy string) ([]byte, internalfs.Hints, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, internalfs.Hints{}, err
 	}
@@ -28,75 +39,45 @@ func Format(ctx context.Context, src []byte, filename, strategy string) ([]byte,
 	case StrategyGo:
 		return formatter.Format(src, filename)
 	case StrategyBinary:
-		return formatBinary(ctx, src)
+		return formatBinary(ctx, bin, src)
+	case StrategyNone:
+		return formatNone(src)
 	case StrategyAuto, "":
-		return Run(ctx, src)
+		return Run(ctx, bin, src)
 	default:
-		return nil, internalfs.Hints{}, fmt.Errorf("unknown fmt strategy %q", strategy)
+		return nil, internalfs.Hints{}, ValidateStrategy(strategy)
 	}
 }
 
-func FormatFile(ctx context.Context, path string) ([]byte, internalfs.Hints, bool, error) {
-	if err := ctx.Err(); err != nil {
-		return nil, internalfs.Hints{}, false, err
-	}
-	if _, err := exec.LookPath("terraform"); err != nil {
-		return nil, internalfs.Hints{}, false, nil
-	}
-	data, _, hints, err := internalfs.ReadFileWithHints(ctx, path)
-	if err != nil {
-		return nil, hints, false, err
-	}
-	prepared := internalfs.PrepareForParse(data, hints)
-	if len(prepared) > 0 && !utf8.Valid(prepared) {
-		return nil, hints, false, fmt.Errorf("input is not valid UTF-8")
-	}
-	cmd := exec.CommandContext(ctx, "terraform", "fmt", "-no-color", "-list=false", "-write=false", path)
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		if stderrStr := stderr.String(); stderrStr != "" {
-			return nil, hints, false, fmt.Errorf("terraform fmt failed: %w: %s", err, stderrStr)
-		}
-		return nil, hints, false, fmt.Errorf("terraform fmt failed: %w", err)
-	}
-	formatted := stdout.Bytes()
-	if len(formatted) > 0 {
-		formatted = bytes.TrimRight(formatted, "\n")
-		formatted = append(formatted, '\n')
+func formatNone(src []byte) ([]byte, internalfs.Hints, error) {
+	hints := internalfs.DetectHintsFromBytes(src)
+	src = internalfs.PrepareForParse(src, hints)
+	if len(src) > 0 && !utf8.Valid(src) {
+		return nil, hints, fmt.Errorf("input is not valid UTF-8")
 	}
-	return formatted, hints, true, nil
+	return src, hints, nil
 }
 
-func formatBinary(ctx context.Context, src []byte) ([]byte, internalfs.Hints, error) {
+func formatBinary(ctx context.Context, bin tool.Binary, src []byte) ([]byte, internalfs.Hints, error) {
 	hints := internalfs.DetectHintsFromBytes(src)
 	src = internalfs.PrepareForParse(src, hints)
 	if len(src) > 0 && !utf8.Valid(src) {
 		return nil, hints, fmt.Errorf("input is not valid UTF-8")
 	}
-	path := terraformBinary()
-	if path == "" {
-		return nil, hints, fmt.Errorf("terraform binary not found")
+	if !bin.Available() {
+		return nil, hints, fmt.Errorf("%s binary not found", bin.Name())
 	}
-	cmd := exec.CommandContext(ctx, path, "fmt", "-no-color", "-list=false", "-write=false", "-")
-	cmd.Stdin = bytes.NewReader(src)
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		if stderrStr := stderr.String(); stderrStr != "" {
-			return nil, hints, fmt.Errorf("terraform fmt failed: %w: %s", err, stderrStr)
-		}
-		return nil, hints, fmt.Errorf("terraform fmt failed: %w", err)
+	formatted, err := runBinary(ctx, bin.Path, src)
+	if err != nil {
+		return nil, hints, err
 	}
-	formatted := stdout.Bytes()
+	return formatted, hints, nil
+}
 
-	if len(formatted) > 0 {
-		formatted = bytes.TrimRight(formatted, "\n")
-		formatted = append(formatted, '\n')
+func fmtError(bin string, err error, stderr string) error {
+	name := strings.TrimSuffix(filepath.Base(bin), filepath.Ext(bin))
+	if stderr != "" {
+		return fmt.Errorf("%s fmt failed: %w: %s", name, err, stderr)
 	}
-	return formatted, hints, nil
+	return fmt.Errorf("%s fmt failed: %w", name, err)
 }
@@ -7,55 +7,62 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sync"
 	"testing"
 
 	"github.com/oferchen/hclalign/formatter"
 	internalfs "github.com/oferchen/hclalign/internal/fs"
+	"github.com/oferchen/hclalign/internal/tool"
 	"github.com/stretchr/testify/require"
 )
 
-func resetTerraformPath() {
-	terraformPath = ""
-	terraformPathOnce = sync.Once{}
+func terraformBin(t *testing.T) tool.Binary {
+	t.Helper()
+	path, err := exec.LookPath("terraform")
+	if err != nil {
+		t.Skip("terraform binary not found")
+	}
+	return tool.Binary{Kind: tool.Terraform, Path: path}
+}
+
+func fakeBinary(t *testing.T, kind tool.Kind, script string) tool.Binary {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), string(kind))
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return tool.Binary{Kind: kind, Path: path}
 }
 
 func TestGoMatchesBinary(t *testing.T) {
-	if _, err := exec.LookPath("terraform"); err != nil {
-		t.Skip("terraform binary not found")
-	}
+	bin := terraformBin(t)
 	src := []byte("variable \"a\" {\n  type = string\n}\n")
-	goFmt, _, err := Format(context.Background(), src, "test.tf", string(StrategyGo))
+	goFmt, _, err := Format(context.Background(), bin, src, "test.tf", string(StrategyGo))
 	require.NoError(t, err)
-	binFmt, _, err := Format(context.Background(), src, "test.tf", string(StrategyBinary))
+	binFmt, _, err := Format(context.Background(), bin, src, "test.tf", string(StrategyBinary))
 	require.NoError(t, err)
 	require.Equal(t, goFmt, binFmt)
 }
 
 func TestAutoUsesGoFormatter(t *testing.T) {
 	src := []byte("variable \"a\" {\n  type = string\n}\n")
-	autoFmt, _, err := Format(context.Background(), src, "test.tf", string(StrategyAuto))
+	autoFmt, _, err := Format(context.Background(), tool.Binary{}, src, "test.tf", string(StrategyAuto))
 	require.NoError(t, err)
-	goFmt, _, err := Format(context.Background(), src, "test.tf", string(StrategyGo))
+	goFmt, _, err := Format(context.Background(), tool.Binary{}, src, "test.tf", string(StrategyGo))
 	require.NoError(t, err)
 	require.Equal(t, goFmt, autoFmt)
 }
 
 func TestIdempotent(t *testing.T) {
 	src := []byte("variable \"a\" {\n  type = string\n}\n")
-	first, _, err := Format(context.Background(), src, "test.tf", string(StrategyGo))
+	first, _, err := Format(context.Background(), tool.Binary{}, src, "test.tf", string(StrategyGo))
 	require.NoError(t, err)
-	second, _, err := Format(context.Background(), first, "test.tf", string(StrategyGo))
+	second, _, err := Format(context.Background(), tool.Binary{}, first, "test.tf", string(StrategyGo))
 	require.NoError(t, err)
 	require.Equal(t, first, second)
 }
 
 func TestBinaryPreservesHints(t *testing.T) {
-	if _, err := exec.LookPath("terraform"); err != nil {
-		t.Skip("terraform binary not found")
-	}
+	bin := terraformBin(t)
 	src := append([]byte{0xef, 0xbb, 0xbf}, []byte("variable \"a\" {\r\n  type = string\r\n}\r\n")...)
-	formatted, hints, err := Format(context.Background(), src, "test.tf", string(StrategyBinary))
+	formatted, hints, err := Format(context.Background(), bin, src, "test.tf", string(StrategyBinary))
 	require.NoError(t, err)
 	require.True(t, hints.HasBOM)
 	require.Equal(t, "\r\n", hints.Newline)
@@ -66,68 +73,51 @@ func TestBinaryPreservesHints(t *testing.T) {
 }
 
 func TestRunPreservesHints(t *testing.T) {
-	resetTerraformPath()
 	src := append([]byte{0xef, 0xbb, 0xbf}, []byte("variable \"a\" {\r\n  type = string\r\n}\r\n")...)
-	_, hints, err := Run(context.Background(), src)
+	_, hints, err := Run(context.Background(), tool.Binary{}, src)
 	require.NoError(t, err)
 	require.True(t, hints.HasBOM)
 	require.Equal(t, "\r\n", hints.Newline)
 }
 
 func TestUnknownStrategy(t *testing.T) {
-	_, _, err := Format(context.Background(), []byte("{}"), "test.tf", "bogus")
+	_, _, err := Format(context.Background(), tool.Binary{}, []byte("{}"), "test.tf", "bogus")
 	require.Error(t, err)
 }
 
 func TestBinaryInvalidUTF8(t *testing.T) {
-	_, _, err := Format(context.Background(), []byte{0xff, 0xfe}, "test.tf", string(StrategyBinary))
+	_, _, err := Format(context.Background(), tool.Binary{}, []byte{0xff, 0xfe}, "test.tf", string(StrategyBinary))
 	require.Error(t, err)
 }
 
-func TestBinaryMissingTerraform(t *testing.T) {
-	resetTerraformPath()
-	oldPath := os.Getenv("PATH")
-	defer os.Setenv("PATH", oldPath)
-	os.Setenv("PATH", "")
-	_, _, err := Format(context.Background(), []byte("variable \"a\" {}\n"), "test.tf", string(StrategyBinary))
-	require.Error(t, err)
+func TestBinaryMissing(t *testing.T) {
+	_, _, err := Format(context.Background(), tool.Binary{}, []byte("variable \"a\" {}\n"), "test.tf", string(StrategyBinary))
+	require.EqualError(t, err, "terraform binary not found")
+	_, _, err = Format(context.Background(), tool.Binary{Kind: tool.Tofu}, []byte("variable \"a\" {}\n"), "test.tf", string(StrategyBinary))
+	require.EqualError(t, err, "tofu binary not found")
 }
 
-func TestRunUsesTerraformCLI(t *testing.T) {
-	resetTerraformPath()
-	dir := t.TempDir()
-	bin := filepath.Join(dir, "terraform")
-	script := []byte("#!/bin/sh\ncat >/dev/null\nprintf 'bin\\n'")
-	if err := os.WriteFile(bin, script, 0o755); err != nil {
-		t.Fatalf("write fake terraform: %v", err)
-	}
-	oldPath := os.Getenv("PATH")
-	defer os.Setenv("PATH", oldPath)
-	os.Setenv("PATH", dir)
-	out, hints, err := Run(context.Background(), []byte("input\n"))
+func TestRunUsesCLI(t *testing.T) {
+	bin := fakeBinary(t, tool.Tofu, "#!/bin/sh\ncat >/dev/null\nprintf 'bin\\n'")
+	out, hints, err := Run(context.Background(), bin, []byte("input\n"))
 	require.NoError(t, err)
 	require.Equal(t, "bin\n", string(out))
 	require.Equal(t, internalfs.Hints{Newline: "\n"}, hints)
 }
 
 func TestRunFallsBackToGoFormatter(t *testing.T) {
-	resetTerraformPath()
-	oldPath := os.Getenv("PATH")
-	defer os.Setenv("PATH", oldPath)
-	os.Setenv("PATH", "")
 	src := []byte("variable \"a\" {\n  type = string\n}\n")
 	want, wantHints, err := formatter.Format(src, "test.tf")
 	require.NoError(t, err)
-	got, gotHints, err := Run(context.Background(), src)
+	got, gotHints, err := Run(context.Background(), tool.Binary{}, src)
 	require.NoError(t, err)
 	require.Equal(t, want, got)
 	require.Equal(t, wantHints, gotHints)
 }
 
 func TestRunPropagatesHints(t *testing.T) {
-	resetTerraformPath()
 	src := append([]byte{0xef, 0xbb, 0xbf}, []byte("variable \"a\" {}\r\n")...)
-	formatted, hints, err := Run(context.Background(), src)
+	formatted, hints, err := Run(context.Background(), tool.Binary{}, src)
 	require.NoError(t, err)
 	require.True(t, hints.HasBOM)
 	require.Equal(t, "\r\n", hints.Newline)
@@ -138,9 +128,8 @@ func TestRunPropagatesHints(t *testing.T) {
 }
 
 func TestRunContextCanceled(t *testing.T) {
-	resetTerraformPath()
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	_, _, err := Run(ctx, []byte("variable \"a\" {}\n"))
+	_, _, err := Run(ctx, tool.Binary{}, []byte("variable \"a\" {}\n"))
 	require.ErrorIs(t, err, context.Canceled)
 }
@@ -0,0 +1,4 @@
+locals {
+  args    = max(var.values ...)
+  grouped = {for k, v in var.m: v => k ...}
+}
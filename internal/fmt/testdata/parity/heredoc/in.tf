@@ -0,0 +1,10 @@
+locals {
+  wrapped = "${<<EOT
+hello
+EOT
+}"
+  indented = <<-EOT
+    ${var.name}
+  EOT
+  kept = "${var.a}"
+}
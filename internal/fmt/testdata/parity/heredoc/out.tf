@@ -0,0 +1,9 @@
+locals {
+  wrapped  = <<EOT
+hello
+EOT
+  indented = <<-EOT
+    ${var.name}
+  EOT
+  kept     = var.a
+}
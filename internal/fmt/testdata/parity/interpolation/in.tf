@@ -0,0 +1,14 @@
+locals {
+  simple   = "${var.foo}"
+  call     = "${foo("${bar}")}"
+  template = "${var.a}-x"
+  pair     = "${var.a}${var.b}"
+  literal  = "plain"
+  multi = "${
+    var.x
+  }"
+  grouped = "${
+    (var.x
+    + 1)
+  }"
+}
@@ -0,0 +1,27 @@
+variable "a" {
+  type = "string"
+}
+
+variable "b" {
+  type = "list"
+}
+
+variable "c" {
+  type = "map"
+}
+
+variable "d" {
+  type = list
+}
+
+variable "e" {
+  type = map
+}
+
+variable "f" {
+  type = list(number)
+}
+
+locals {
+  type = "string"
+}
@@ -0,0 +1,27 @@
+variable "a" {
+  type = string
+}
+
+variable "b" {
+  type = list(string)
+}
+
+variable "c" {
+  type = map(string)
+}
+
+variable "d" {
+  type = list(any)
+}
+
+variable "e" {
+  type = map(any)
+}
+
+variable "f" {
+  type = list(number)
+}
+
+locals {
+  type = "string"
+}
@@ -71,26 +71,11 @@ func IsStdout(w io.Writer) bool {
 }
 
 func ReadFileWithHints(ctx context.Context, path string) (data []byte, perm iofs.FileMode, hints Hints, err error) {
-	if err := ctx.Err(); err != nil {
-		return nil, 0, hints, err
-	}
-	info, err := os.Stat(path)
-	if err != nil {
-		return nil, 0, hints, err
-	}
-	perm = info.Mode()
-	if err := ctx.Err(); err != nil {
-		return nil, 0, hints, err
-	}
-	raw, err := os.ReadFile(path)
+	fsys, name, err := Host(path)
 	if err != nil {
 		return nil, 0, hints, err
 	}
-	hints = DetectHintsFromBytes(raw)
-	if hints.HasBOM {
-		raw = raw[len(utf8BOM):]
-	}
-	return raw, perm, hints, nil
+	return ReadFSWithHints(ctx, fsys, name)
 }
 
 func ApplyHints(data []byte, hints Hints) []byte {
@@ -0,0 +1,80 @@
+// internal/fs/batch.go
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+)
+
+type RemovableFS interface {
+	WritableFS
+	Remove(name string) error
+}
+
+type Change struct {
+	Path   string
+	Data   []byte
+	Perm   iofs.FileMode
+	Remove bool
+}
+
+type snapshot struct {
+	exists bool
+	data   []byte
+	perm   iofs.FileMode
+}
+
+func WriteBatch(ctx context.Context, fsys WritableFS, changes []Change) error {
+	remover, canRemove := fsys.(RemovableFS)
+	saved := make([]snapshot, len(changes))
+	for i, c := range changes {
+		if c.Remove && !canRemove {
+			return fmt.Errorf("error removing file %s: filesystem does not support removal", c.Path)
+		}
+		info, err := iofs.Stat(fsys, c.Path)
+		if errors.Is(err, iofs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		data, err := iofs.ReadFile(fsys, c.Path)
+		if err != nil {
+			return err
+		}
+		saved[i] = snapshot{exists: true, data: data, perm: info.Mode().Perm()}
+	}
+
+	for i, c := range changes {
+		var err error
+		if c.Remove {
+			err = remover.Remove(c.Path)
+		} else {
+			err = fsys.WriteFile(ctx, c.Path, c.Data, c.Perm)
+		}
+		if err != nil {
+			err = fmt.Errorf("error writing file %s: %w", c.Path, err)
+			return errors.Join(err, rollback(fsys, remover, changes[:i], saved[:i]))
+		}
+	}
+	return nil
+}
+
+func rollback(fsys WritableFS, remover RemovableFS, changes []Change, saved []snapshot) error {
+	var errs []error
+	for i := len(changes) - 1; i >= 0; i-- {
+		var err error
+		switch {
+		case saved[i].exists:
+			err = fsys.WriteFile(context.Background(), changes[i].Path, saved[i].data, saved[i].perm)
+		case remover != nil:
+			err = remover.Remove(changes[i].Path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error restoring file %s: %w", changes[i].Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+// internal/fs/batch_test.go
+package fs
+
+import (
+	"context"
+	"errors"
+	iofs "io/fs"
+	"reflect"
+	"testing"
+)
+
+type failingFS struct {
+	*MemFS
+	fail string
+}
+
+func (f failingFS) WriteFile(ctx context.Context, name string, data []byte, perm iofs.FileMode) error {
+	if name == f.fail {
+		return errors.New("disk full")
+	}
+	return f.MemFS.WriteFile(ctx, name, data, perm)
+}
+
+func TestWriteBatch(t *testing.T) {
+	m := NewMemFS(map[string][]byte{"main.tf": []byte("a\n"), "old.tf": []byte("b\n")})
+	err := WriteBatch(context.Background(), m, []Change{
+		{Path: "main.tf", Data: []byte("c\n"), Perm: 0o644},
+		{Path: "new.tf", Data: []byte("d\n"), Perm: 0o644},
+		{Path: "old.tf", Remove: true},
+	})
+	if err != nil {
+		t.Fatalf("write batch: %v", err)
+	}
+	if got := m.Files(); !reflect.DeepEqual(got, []string{"main.tf", "new.tf"}) {
+		t.Fatalf("unexpected files %v", got)
+	}
+	if got, _ := iofs.ReadFile(m, "main.tf"); string(got) != "c\n" {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
+
+func TestWriteBatchRollback(t *testing.T) {
+	m := NewMemFS(map[string][]byte{"main.tf": []byte("a\n"), "old.tf": []byte("b\n")})
+	fsys := failingFS{MemFS: m, fail: "z.tf"}
+	err := WriteBatch(context.Background(), fsys, []Change{
+		{Path: "main.tf", Data: []byte("c\n"), Perm: 0o644},
+		{Path: "new.tf", Data: []byte("d\n"), Perm: 0o644},
+		{Path: "old.tf", Remove: true},
+		{Path: "z.tf", Data: []byte("e\n"), Perm: 0o644},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if got := m.Files(); !reflect.DeepEqual(got, []string{"main.tf", "old.tf"}) {
+		t.Fatalf("unexpected files %v", got)
+	}
+	for name, want := range map[string]string{"main.tf": "a\n", "old.tf": "b\n"} {
+		if got, _ := iofs.ReadFile(m, name); string(got) != want {
+			t.Fatalf("%s not restored: %q", name, got)
+		}
+	}
+}
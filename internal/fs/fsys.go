@@ -0,0 +1,222 @@
+// internal/fs/fsys.go
+package fs
+
+import (
+	"context"
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"time"
+)
+
+type WritableFS interface {
+	iofs.FS
+	WriteFile(ctx context.Context, name string, data []byte, perm iofs.FileMode) error
+}
+
+var ErrOutsideRoot = errors.New("path resolves outside the filesystem root")
+
+type LinkFS interface {
+	iofs.FS
+	Lstat(name string) (iofs.FileInfo, error)
+	EvalSymlinks(name string) (string, error)
+}
+
+type osFS struct {
+	root string
+	dir  iofs.FS
+}
+
+func NewOSFS(root string) WritableFS {
+	return osFS{root: root, dir: os.DirFS(root)}
+}
+
+func HostRoot(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.VolumeName(abs) + string(filepath.Separator), nil
+}
+
+func HostName(root, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func Host(path string) (WritableFS, string, error) {
+	root, err := HostRoot(path)
+	if err != nil {
+		return nil, "", err
+	}
+	name, err := HostName(root, path)
+	if err != nil {
+		return nil, "", err
+	}
+	return NewOSFS(root), name, nil
+}
+
+func (f osFS) Open(name string) (iofs.File, error) { return f.dir.Open(name) }
+
+func (f osFS) Stat(name string) (iofs.FileInfo, error) { return iofs.Stat(f.dir, name) }
+
+func (f osFS) ReadFile(name string) ([]byte, error) { return iofs.ReadFile(f.dir, name) }
+
+func (f osFS) ReadDir(name string) ([]iofs.DirEntry, error) { return iofs.ReadDir(f.dir, name) }
+
+func (f osFS) WriteFile(ctx context.Context, name string, data []byte, perm iofs.FileMode) error {
+	path, err := f.join("write", name)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(ctx, WriteOpts{Path: path, Data: data, Perm: perm})
+}
+
+func (f osFS) Remove(name string) error {
+	path, err := f.join("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (f osFS) Lstat(name string) (iofs.FileInfo, error) {
+	path, err := f.join("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Lstat(path)
+}
+
+func (f osFS) EvalSymlinks(name string) (string, error) {
+	path, err := f.join("evalsymlinks", name)
+	if err != nil {
+		return "", err
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(f.root)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &iofs.PathError{Op: "evalsymlinks", Path: name, Err: ErrOutsideRoot}
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (f osFS) join(op, name string) (string, error) {
+	if !iofs.ValidPath(name) {
+		return "", &iofs.PathError{Op: op, Path: name, Err: iofs.ErrInvalid}
+	}
+	return filepath.Join(f.root, filepath.FromSlash(name)), nil
+}
+
+type MemFS struct {
+	mu    sync.RWMutex
+	files fstest.MapFS
+}
+
+func NewMemFS(files map[string][]byte) *MemFS {
+	m := &MemFS{files: make(fstest.MapFS, len(files))}
+	for name, data := range files {
+		m.files[name] = &fstest.MapFile{Data: append([]byte(nil), data...), Mode: 0o644}
+	}
+	return m
+}
+
+func (m *MemFS) Open(name string) (iofs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.Open(name)
+}
+
+func (m *MemFS) Stat(name string) (iofs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.Stat(name)
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.ReadFile(name)
+}
+
+func (m *MemFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.files.ReadDir(name)
+}
+
+func (m *MemFS) WriteFile(ctx context.Context, name string, data []byte, perm iofs.FileMode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !iofs.ValidPath(name) {
+		return &iofs.PathError{Op: "write", Path: name, Err: iofs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = &fstest.MapFile{Data: append([]byte(nil), data...), Mode: perm.Perm(), ModTime: time.Now()}
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; !ok {
+		return &iofs.PathError{Op: "remove", Path: name, Err: iofs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *MemFS) Files() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ReadFSWithHints(ctx context.Context, fsys iofs.FS, name string) (data []byte, perm iofs.FileMode, hints Hints, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, hints, err
+	}
+	info, err := iofs.Stat(fsys, name)
+	if err != nil {
+		return nil, 0, hints, err
+	}
+	perm = info.Mode()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, hints, err
+	}
+	raw, err := iofs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, 0, hints, err
+	}
+	hints = DetectHintsFromBytes(raw)
+	if hints.HasBOM {
+		raw = raw[len(utf8BOM):]
+	}
+	return raw, perm, hints, nil
+}
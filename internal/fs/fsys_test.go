@@ -0,0 +1,186 @@
+// internal/fs/fsys_test.go
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemFS(t *testing.T) {
+	src := []byte("a = 1\n")
+	m := NewMemFS(map[string][]byte{"main.tf": src, "mod/vars.tf": []byte("b = 2\n")})
+	src[0] = 'z'
+
+	if err := fstest.TestFS(m, "main.tf", "mod/vars.tf"); err != nil {
+		t.Fatalf("TestFS: %v", err)
+	}
+	got, err := iofs.ReadFile(m, "main.tf")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(got) != "a = 1\n" {
+		t.Fatalf("unexpected content %q", got)
+	}
+
+	ctx := context.Background()
+	if err := m.WriteFile(ctx, "mod/new.tf", []byte("c = 3\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	info, err := iofs.Stat(m, "mod/new.tf")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected mode %v", info.Mode())
+	}
+	want := []string{"main.tf", "mod/new.tf", "mod/vars.tf"}
+	if files := m.Files(); !reflect.DeepEqual(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+
+	var pathErr *iofs.PathError
+	if err := m.WriteFile(ctx, "../escape.tf", nil, 0o644); !errors.As(err, &pathErr) {
+		t.Fatalf("expected path error, got %v", err)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := m.WriteFile(canceled, "main.tf", nil, 0o644); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestReadFSWithHints(t *testing.T) {
+	raw := append(append([]byte{}, utf8BOM...), []byte("a = 1\r\n")...)
+	m := NewMemFS(map[string][]byte{"crlf.tf": raw})
+	data, perm, hints, err := ReadFSWithHints(context.Background(), m, "crlf.tf")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.Equal(data, []byte("a = 1\r\n")) {
+		t.Fatalf("unexpected data %q", data)
+	}
+	if perm != 0o644 {
+		t.Fatalf("unexpected perm %v", perm)
+	}
+	if !hints.HasBOM || hints.Newline != "\r\n" {
+		t.Fatalf("unexpected hints %+v", hints)
+	}
+	if _, _, _, err := ReadFSWithHints(context.Background(), m, "missing.tf"); !errors.Is(err, iofs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestOSWriteFilePreservesMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf")
+	if err := os.WriteFile(path, []byte("a = 1\n"), 0o600); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	fsys := NewOSFS(filepath.Dir(path))
+	data, perm, _, err := ReadFSWithHints(context.Background(), fsys, "main.tf")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if err := fsys.WriteFile(context.Background(), "main.tf", append(data, "b = 2\n"...), perm); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	if string(got) != "a = 1\nb = 2\n" {
+		t.Fatalf("unexpected content %q", got)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected mode %v", info.Mode())
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("readdir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected no temp files, got %d entries", len(entries))
+	}
+}
+
+func TestOSFSContract(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "mod"), 0o755); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	for name, data := range map[string]string{"main.tf": "a = 1\n", "mod/vars.tf": "b = 2\n"} {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(data), 0o644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	fsys := NewOSFS(dir)
+	if err := fstest.TestFS(fsys, "main.tf", "mod/vars.tf"); err != nil {
+		t.Fatal(err)
+	}
+
+	abs := filepath.Join(dir, "main.tf")
+	if _, err := fsys.Open(abs); !errors.Is(err, iofs.ErrInvalid) {
+		t.Fatalf("open %s: expected ErrInvalid, got %v", abs, err)
+	}
+	for _, name := range []string{abs, "../main.tf", "./main.tf"} {
+		if err := fsys.WriteFile(context.Background(), name, nil, 0o644); !errors.Is(err, iofs.ErrInvalid) {
+			t.Fatalf("write %s: expected ErrInvalid, got %v", name, err)
+		}
+		if err := fsys.(RemovableFS).Remove(name); !errors.Is(err, iofs.ErrInvalid) {
+			t.Fatalf("remove %s: expected ErrInvalid, got %v", name, err)
+		}
+	}
+}
+
+func TestOSFSSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), nil, 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "main.tf"), filepath.Join(dir, "inside.tf")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "outside")); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	links := NewOSFS(dir).(LinkFS)
+	info, err := links.Lstat("inside.tf")
+	if err != nil || info.Mode()&iofs.ModeSymlink == 0 {
+		t.Fatalf("lstat: %v %v", info, err)
+	}
+	if real, err := links.EvalSymlinks("inside.tf"); err != nil || real != "main.tf" {
+		t.Fatalf("evalsymlinks: %q %v", real, err)
+	}
+	if _, err := links.EvalSymlinks("outside"); !errors.Is(err, ErrOutsideRoot) {
+		t.Fatalf("expected ErrOutsideRoot, got %v", err)
+	}
+}
+
+func TestHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf")
+	if err := os.WriteFile(path, []byte("a = 1\n"), 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	fsys, name, err := Host(path)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	if !iofs.ValidPath(name) {
+		t.Fatalf("invalid name %q", name)
+	}
+	data, err := iofs.ReadFile(fsys, name)
+	if err != nil || string(data) != "a = 1\n" {
+		t.Fatalf("read: %q %v", data, err)
+	}
+}
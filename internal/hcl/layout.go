@@ -0,0 +1,242 @@
+// internal/hcl/layout.go
+package hcl
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type ItemKind int
+
+const (
+	ItemAttribute ItemKind = iota
+	ItemBlock
+)
+
+type Item struct {
+	Kind   ItemKind
+	Name   string
+	Attr   *hclwrite.Attribute
+	Block  *hclwrite.Block
+	Before hclwrite.Tokens
+	Lead   hclwrite.Tokens
+	Expr   hclwrite.Tokens
+}
+
+type Layout struct {
+	Tokens        hclwrite.Tokens
+	Newline       []byte
+	TrailingComma bool
+	Items         []Item
+	Tail          hclwrite.Tokens
+}
+
+func NewLayout(body *hclwrite.Body) *Layout {
+	tokens := body.BuildTokens(nil)
+	attrs := body.Attributes()
+	blocks := body.Blocks()
+	l := &Layout{
+		Tokens:        tokens,
+		Newline:       DetectLineEnding(tokens),
+		TrailingComma: HasTrailingComma(tokens),
+		Items:         make([]Item, 0, len(attrs)+len(blocks)),
+	}
+	cursor := 0
+	bi := 0
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Type != hclsyntax.TokenIdent {
+			continue
+		}
+		name := string(tok.Bytes)
+		var item Item
+		var itemToks hclwrite.Tokens
+		if attr, ok := attrs[name]; ok && i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenEqual {
+			itemToks = attr.BuildTokens(nil)
+			item = Item{Kind: ItemAttribute, Name: name, Attr: attr}
+		} else if bi < len(blocks) {
+			itemToks = blocks[bi].BuildTokens(nil)
+			item = Item{Kind: ItemBlock, Name: blocks[bi].Type(), Block: blocks[bi]}
+			bi++
+		} else {
+			continue
+		}
+		leadCount := 0
+		for leadCount < len(itemToks) && itemToks[leadCount].Type == hclsyntax.TokenComment {
+			leadCount++
+		}
+		start := i - leadCount
+		item.Before = tokens[cursor:start]
+		item.Lead = itemToks[:leadCount]
+		if item.Kind == ItemAttribute {
+			item.Expr = attrExpr(itemToks[leadCount+2:])
+		}
+		l.Items = append(l.Items, item)
+		cursor = start + len(itemToks)
+		i = cursor - 1
+	}
+	l.Tail = tokens[cursor:]
+	return l
+}
+
+func attrExpr(toks hclwrite.Tokens) hclwrite.Tokens {
+	n := len(toks)
+	if n == 0 {
+		return nil
+	}
+	expr := append(hclwrite.Tokens{}, toks[:n-1]...)
+	switch last := toks[n-1]; last.Type {
+	case hclsyntax.TokenNewline:
+	case hclsyntax.TokenComment:
+		b := last.Bytes
+		if len(b) > 0 && b[len(b)-1] == '\n' {
+			b = b[:len(b)-1]
+		}
+		expr = append(expr, &hclwrite.Token{Type: hclsyntax.TokenComment, Bytes: b})
+	default:
+		expr = append(expr, last)
+	}
+	return expr
+}
+
+func (l *Layout) AttrNames() []string {
+	names := make([]string, 0, len(l.Items))
+	for _, it := range l.Items {
+		if it.Kind == ItemAttribute {
+			names = append(names, it.Name)
+		}
+	}
+	return names
+}
+
+func (l *Layout) Blocks() []*hclwrite.Block {
+	var blocks []*hclwrite.Block
+	for _, it := range l.Items {
+		if it.Kind == ItemBlock {
+			blocks = append(blocks, it.Block)
+		}
+	}
+	return blocks
+}
+
+func (l *Layout) AttrTokens() (hclwrite.Tokens, map[string]AttrTokens) {
+	res := make(map[string]AttrTokens, len(l.Items))
+	var start hclwrite.Tokens
+	prev := ""
+	afterBlock := false
+	seenBlock := false
+	flush := func(toks hclwrite.Tokens) {
+		if afterBlock {
+			afterBlock = false
+			return
+		}
+		if prev != "" {
+			at := res[prev]
+			at.PostTokens = append(at.PostTokens, toks...)
+			res[prev] = at
+		} else {
+			start = append(start, toks...)
+		}
+	}
+	for _, it := range l.Items {
+		if it.Kind == ItemBlock {
+			if prev == "" && !seenBlock {
+				start = append(start, it.Before...)
+			}
+			seenBlock = true
+			afterBlock = true
+			continue
+		}
+		split := len(it.Before)
+		for split > 0 && it.Before[split-1].Type == hclsyntax.TokenNewline {
+			split--
+		}
+		prevPost, pre := it.Before[:split], it.Before[split:]
+		if split == 0 {
+			prevPost, pre = it.Before, nil
+		}
+		flush(prevPost)
+		res[it.Name] = AttrTokens{PreTokens: pre, LeadTokens: it.Lead, ExprTokens: it.Expr}
+		prev = it.Name
+	}
+	flush(l.Tail)
+	return start, res
+}
+
+type Writer struct {
+	body    *hclwrite.Body
+	newline []byte
+	last    *hclwrite.Token
+	prev    hclsyntax.TokenType
+}
+
+func NewWriter(body *hclwrite.Body, newline []byte) *Writer {
+	body.Clear()
+	return &Writer{body: body, newline: newline, prev: hclsyntax.TokenNil}
+}
+
+func (w *Writer) Tokens(toks hclwrite.Tokens) {
+	w.body.AppendUnstructuredTokens(toks)
+	for _, tok := range toks {
+		w.push(tok)
+	}
+}
+
+func (w *Writer) Attr(name string, expr hclwrite.Tokens) {
+	w.body.SetAttributeRaw(name, expr)
+	if n := len(expr); n > 0 {
+		w.push(expr[n-1])
+	} else {
+		w.push(&hclwrite.Token{Type: hclsyntax.TokenEqual})
+	}
+	w.push(&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}})
+}
+
+func (w *Writer) Block(block *hclwrite.Block) {
+	w.body.AppendBlock(block)
+	w.push(&hclwrite.Token{Type: hclsyntax.TokenCBrace})
+	w.push(&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}})
+}
+
+func (w *Writer) Newline() {
+	w.Tokens(hclwrite.Tokens{&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: w.newline}})
+}
+
+func (w *Writer) EndsWithBlankLine() bool {
+	return w.last != nil && w.last.Type == hclsyntax.TokenNewline && w.prev == hclsyntax.TokenNewline
+}
+
+func (w *Writer) TrimNewline() {
+	toks := w.body.BuildTokens(nil)
+	n := len(toks)
+	if n == 0 || toks[n-1].Type != hclsyntax.TokenNewline {
+		return
+	}
+	w.body.Clear()
+	w.body.AppendUnstructuredTokens(toks[:n-1])
+	w.last, w.prev = nil, hclsyntax.TokenNil
+	for _, tok := range toks[:n-1] {
+		w.push(tok)
+	}
+}
+
+func (w *Writer) Finish(trailingComma bool) {
+	if trailingComma {
+		w.Tokens(hclwrite.Tokens{&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte{','}}})
+	}
+	last := w.last
+	if last == nil || last.Type == hclsyntax.TokenNewline {
+		return
+	}
+	if last.Type == hclsyntax.TokenComment && (len(last.Bytes) == 0 || last.Bytes[len(last.Bytes)-1] == '\n') {
+		return
+	}
+	w.Newline()
+}
+
+func (w *Writer) push(tok *hclwrite.Token) {
+	if w.last != nil {
+		w.prev = w.last.Type
+	}
+	w.last = tok
+}
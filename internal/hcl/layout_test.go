@@ -0,0 +1,80 @@
+// internal/hcl/layout_test.go
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	hcl2 "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLayout(t *testing.T) {
+	src := []byte("# head\n\na = 1 # trail\n\n// lead\nb = length(x)\nnested {\n  c = 1\n}\n# tail\n")
+	f, diags := hclwrite.ParseConfig(src, "t.tf", hcl2.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	l := NewLayout(f.Body())
+	require.Len(t, l.Items, 3)
+	require.Equal(t, []string{"a", "b"}, l.AttrNames())
+	require.Len(t, l.Blocks(), 1)
+
+	a := l.Items[0]
+	require.Equal(t, ItemAttribute, a.Kind)
+	require.Equal(t, "# head\n\n", string(a.Before.Bytes()))
+	require.Empty(t, a.Lead)
+	require.Len(t, a.Expr, 2)
+	require.Equal(t, "# trail", string(a.Expr[1].Bytes))
+
+	b := l.Items[1]
+	require.Equal(t, "\n", string(b.Before.Bytes()))
+	require.Equal(t, "// lead\n", string(b.Lead.Bytes()))
+	require.Equal(t, " length(x)", string(b.Expr.Bytes()))
+
+	n := l.Items[2]
+	require.Equal(t, ItemBlock, n.Kind)
+	require.Equal(t, "nested", n.Name)
+	require.Equal(t, "# tail\n", string(l.Tail.Bytes()))
+	require.Equal(t, []byte("\n"), l.Newline)
+	require.False(t, l.TrailingComma)
+}
+
+func TestLayoutAttrTokens(t *testing.T) {
+	src := []byte("a = 1\n# detached\n\nb = 2\nnested {\n}\nc = 3\n")
+	f, diags := hclwrite.ParseConfig(src, "t.tf", hcl2.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	start, attrs := NewLayout(f.Body()).AttrTokens()
+	require.Empty(t, start)
+	require.Equal(t, "# detached\n", string(attrs["a"].PostTokens.Bytes()))
+	require.Equal(t, "\n", string(attrs["b"].PreTokens.Bytes()))
+	require.Equal(t, " 3", string(attrs["c"].ExprTokens.Bytes()))
+}
+
+func TestWriter(t *testing.T) {
+	f := hclwrite.NewEmptyFile()
+	w := NewWriter(f.Body(), []byte("\n"))
+	w.Attr("a", hclwrite.Tokens{{Type: hclsyntax.TokenNumberLit, Bytes: []byte("1")}})
+	require.False(t, w.EndsWithBlankLine())
+	w.Newline()
+	require.True(t, w.EndsWithBlankLine())
+	w.Block(hclwrite.NewBlock("b", nil))
+	w.Finish(false)
+	require.Equal(t, "a = 1\n\nb {\n}\n", string(f.Bytes()))
+}
+
+func BenchmarkNewLayoutStress(b *testing.B) {
+	src, err := os.ReadFile(filepath.Join("..", "..", "tests", "cases", "stress", "in.tf"))
+	require.NoError(b, err)
+	f, diags := hclwrite.ParseConfig(src, "in.tf", hcl2.InitialPos)
+	require.False(b, diags.HasErrors())
+	body := f.Body().Blocks()[0].Body()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewLayout(body)
+	}
+}
@@ -0,0 +1,161 @@
+// internal/hcl/lists.go
+package hcl
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type listElem struct {
+	toks   hclwrite.Tokens
+	text   string
+	number *float64
+}
+
+func SortList(tokens hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	n := len(tokens)
+	if n < 2 || tokens[0].Type != hclsyntax.TokenOBrack || tokens[n-1].Type != hclsyntax.TokenCBrack {
+		return tokens, false
+	}
+	inner := tokens[1 : n-1]
+	for _, tok := range inner {
+		if tok.Type == hclsyntax.TokenComment {
+			return tokens, false
+		}
+	}
+
+	prefix := 0
+	for prefix < len(inner) && inner[prefix].Type == hclsyntax.TokenNewline {
+		prefix++
+	}
+	var elems []listElem
+	var seps []hclwrite.Tokens
+	for i := prefix; i < len(inner); {
+		j := i
+		depth := 0
+		for ; j < len(inner); j++ {
+			switch inner[j].Type {
+			case hclsyntax.TokenOBrack, hclsyntax.TokenOQuote:
+				depth++
+			case hclsyntax.TokenCBrack, hclsyntax.TokenCQuote:
+				depth--
+			}
+			if depth == 0 && (inner[j].Type == hclsyntax.TokenComma || inner[j].Type == hclsyntax.TokenNewline) {
+				break
+			}
+		}
+		elem, ok := plainElem(inner[i:j])
+		if !ok {
+			return tokens, false
+		}
+		elems = append(elems, elem)
+		k := j
+		if k < len(inner) && inner[k].Type == hclsyntax.TokenComma {
+			k++
+		}
+		for k < len(inner) && inner[k].Type == hclsyntax.TokenNewline {
+			k++
+		}
+		if k == j && k < len(inner) {
+			return tokens, false
+		}
+		seps = append(seps, inner[j:k])
+		i = k
+	}
+	if len(elems) < 2 {
+		return tokens, false
+	}
+
+	sorted := append([]listElem(nil), elems...)
+	sort.SliceStable(sorted, func(a, b int) bool { return elemLess(sorted[a], sorted[b]) })
+	unique := sorted[:1]
+	for _, e := range sorted[1:] {
+		if e.text != unique[len(unique)-1].text {
+			unique = append(unique, e)
+		}
+	}
+	if len(unique) == len(elems) {
+		same := true
+		for i := range elems {
+			if elems[i].text != unique[i].text {
+				same = false
+				break
+			}
+		}
+		if same {
+			return tokens, false
+		}
+	}
+
+	out := append(hclwrite.Tokens{tokens[0]}, inner[:prefix]...)
+	for i, e := range unique {
+		out = append(out, e.toks...)
+		if i == len(unique)-1 {
+			out = append(out, seps[len(seps)-1]...)
+		} else {
+			out = append(out, seps[i]...)
+		}
+	}
+	return append(out, tokens[n-1]), true
+}
+
+func elemLess(a, b listElem) bool {
+	if a.number != nil && b.number != nil && *a.number != *b.number {
+		return *a.number < *b.number
+	}
+	return a.text < b.text
+}
+
+func plainElem(toks hclwrite.Tokens) (listElem, bool) {
+	e := listElem{toks: toks}
+	for _, tok := range toks {
+		e.text += string(tok.Bytes)
+	}
+	switch {
+	case len(toks) == 0:
+		return e, false
+	case len(toks) == 1 && toks[0].Type == hclsyntax.TokenNumberLit,
+		len(toks) == 2 && toks[0].Type == hclsyntax.TokenMinus && toks[1].Type == hclsyntax.TokenNumberLit:
+		if v, err := strconv.ParseFloat(e.text, 64); err == nil {
+			e.number = &v
+		}
+		return e, true
+	case toks[0].Type == hclsyntax.TokenOQuote:
+		return e, quotedLiteral(toks)
+	case toks[0].Type == hclsyntax.TokenIdent:
+		return e, traversal(toks[1:])
+	}
+	return e, false
+}
+
+func quotedLiteral(toks hclwrite.Tokens) bool {
+	switch len(toks) {
+	case 2:
+		return toks[1].Type == hclsyntax.TokenCQuote
+	case 3:
+		return toks[1].Type == hclsyntax.TokenQuotedLit && toks[2].Type == hclsyntax.TokenCQuote
+	}
+	return false
+}
+
+func traversal(toks hclwrite.Tokens) bool {
+	for i := 0; i < len(toks); {
+		switch {
+		case toks[i].Type == hclsyntax.TokenDot && i+1 < len(toks) &&
+			(toks[i+1].Type == hclsyntax.TokenIdent || toks[i+1].Type == hclsyntax.TokenNumberLit):
+			i += 2
+		case toks[i].Type == hclsyntax.TokenOBrack && i+2 < len(toks) &&
+			toks[i+1].Type == hclsyntax.TokenNumberLit && toks[i+2].Type == hclsyntax.TokenCBrack:
+			i += 3
+		case toks[i].Type == hclsyntax.TokenOBrack && i+4 < len(toks) && quotedLiteral(toks[i+1:i+4]) &&
+			toks[i+4].Type == hclsyntax.TokenCBrack:
+			i += 5
+		default:
+			return false
+		}
+	}
+	return true
+}
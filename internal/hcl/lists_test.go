@@ -0,0 +1,88 @@
+// internal/hcl/lists_test.go
+package hcl
+
+import (
+	"testing"
+
+	hcl2 "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortList(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		changed bool
+	}{
+		{
+			name:    "single line references",
+			in:      "v = [aws_subnet.b.id, aws_subnet.a.id, aws_subnet.b.id]\n",
+			want:    "v = [aws_subnet.a.id, aws_subnet.b.id]\n",
+			changed: true,
+		},
+		{
+			name:    "multi-line with trailing comma",
+			in:      "v = [\n  \"10.0.2.0/24\",\n  \"10.0.1.0/24\",\n  \"10.0.2.0/24\",\n]\n",
+			want:    "v = [\n  \"10.0.1.0/24\",\n  \"10.0.2.0/24\",\n]\n",
+			changed: true,
+		},
+		{
+			name:    "multi-line without trailing comma",
+			in:      "v = [\n  tags,\n  ami\n]\n",
+			want:    "v = [\n  ami,\n  tags\n]\n",
+			changed: true,
+		},
+		{
+			name:    "numbers and indexes",
+			in:      "v = [443, 80, -1, module.m[1].id, module.m[0].id, local.x[\"b\"], local.x[\"a\"]]\n",
+			want:    "v = [-1, 80, 443, local.x[\"a\"], local.x[\"b\"], module.m[0].id, module.m[1].id]\n",
+			changed: true,
+		},
+		{
+			name: "already sorted",
+			in:   "v = [a, b]\n",
+			want: "v = [a, b]\n",
+		},
+		{
+			name: "function call",
+			in:   "v = [b, lower(a)]\n",
+			want: "v = [b, lower(a)]\n",
+		},
+		{
+			name: "template",
+			in:   "v = [\"${b}\", \"a\"]\n",
+			want: "v = [\"${b}\", \"a\"]\n",
+		},
+		{
+			name: "splat and dynamic index",
+			in:   "v = [b[*].id, a[count.index]]\n",
+			want: "v = [b[*].id, a[count.index]]\n",
+		},
+		{
+			name: "comments",
+			in:   "v = [\n  b, # keep first\n  a,\n]\n",
+			want: "v = [\n  b, # keep first\n  a,\n]\n",
+		},
+		{
+			name: "not a list",
+			in:   "v = concat([b], [a])\n",
+			want: "v = concat([b], [a])\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tc.in), "in.tf", hcl2.InitialPos)
+			require.False(t, diags.HasErrors())
+			toks, changed := SortList(file.Body().GetAttribute("v").Expr().BuildTokens(nil))
+			require.Equal(t, tc.changed, changed)
+			file.Body().SetAttributeRaw("v", toks)
+			require.Equal(t, tc.want, string(hclwrite.Format(file.Bytes())))
+
+			again, changed := SortList(toks)
+			require.False(t, changed)
+			require.Equal(t, toks.Bytes(), again.Bytes())
+		})
+	}
+}
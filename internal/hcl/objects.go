@@ -0,0 +1,251 @@
+// internal/hcl/objects.go
+package hcl
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type objectItem struct {
+	lead    hclwrite.Tokens
+	key     string
+	body    hclwrite.Tokens
+	comma   *hclwrite.Token
+	trail   hclwrite.Tokens
+	newline *hclwrite.Token
+}
+
+func (it *objectItem) endsLine() bool {
+	return it.newline != nil || endsWithNewline(it.trail)
+}
+
+type objectEntry struct {
+	item *objectItem
+	toks hclwrite.Tokens
+}
+
+func SortObjectKeys(tokens hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	out := make(hclwrite.Tokens, 0, len(tokens))
+	changed := false
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].Type != hclsyntax.TokenOBrace {
+			out = append(out, tokens[i])
+			continue
+		}
+		end := closingBrace(tokens, i)
+		if end < 0 {
+			return tokens, false
+		}
+		inner, ch := SortObjectKeys(tokens[i+1 : end])
+		sorted, ok := sortObject(inner)
+		out = append(out, tokens[i])
+		out = append(out, sorted...)
+		out = append(out, tokens[end])
+		changed = changed || ch || ok
+		i = end
+	}
+	return out, changed
+}
+
+func closingBrace(tokens hclwrite.Tokens, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case hclsyntax.TokenOBrace:
+			depth++
+		case hclsyntax.TokenCBrace:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func sortObject(tokens hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	entries, ok := objectEntries(tokens)
+	if !ok {
+		return tokens, false
+	}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.item == nil {
+			continue
+		}
+		if seen[e.item.key] {
+			return tokens, false
+		}
+		seen[e.item.key] = true
+	}
+
+	changed := false
+	for start := 0; start < len(entries); {
+		if entries[start].item == nil {
+			start++
+			continue
+		}
+		end := start
+		for end < len(entries) && entries[end].item != nil {
+			end++
+		}
+		run := make([]*objectItem, end-start)
+		for i := range run {
+			run[i] = entries[start+i].item
+		}
+		sorted := append([]*objectItem(nil), run...)
+		sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].key < sorted[b].key })
+		for i := range run {
+			if sorted[i] != run[i] {
+				changed = true
+			}
+		}
+		if changed {
+			for i, it := range sorted {
+				entries[start+i].toks = it.place(run[i])
+			}
+		}
+		start = end
+	}
+	if !changed {
+		return tokens, false
+	}
+
+	out := make(hclwrite.Tokens, 0, len(tokens)+1)
+	for _, e := range entries {
+		if e.toks == nil && e.item != nil {
+			e.toks = e.item.place(e.item)
+		}
+		out = append(out, e.toks...)
+	}
+	return out, true
+}
+
+func (it *objectItem) place(slot *objectItem) hclwrite.Tokens {
+	out := append(hclwrite.Tokens{}, it.lead...)
+	out = append(out, it.body...)
+	if slot.comma != nil {
+		out = append(out, slot.comma)
+	}
+	out = append(out, it.trail...)
+	if slot.endsLine() && !endsWithNewline(it.trail) {
+		newline := slot.newline
+		if newline == nil {
+			newline = &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte{'\n'}}
+		}
+		out = append(out, newline)
+	}
+	return out
+}
+
+func objectEntries(tokens hclwrite.Tokens) ([]objectEntry, bool) {
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenNewline || tok.Type == hclsyntax.TokenComment {
+			continue
+		}
+		if tok.Type == hclsyntax.TokenIdent && string(tok.Bytes) == "for" {
+			return nil, false
+		}
+		break
+	}
+
+	var entries []objectEntry
+	var lead hclwrite.Tokens
+	lineStart := true
+	for i := 0; i < len(tokens); {
+		tok := tokens[i]
+		switch {
+		case tok.Type == hclsyntax.TokenNewline:
+			if len(lead) > 0 && !endsWithNewline(lead) {
+				lead = append(lead, tok)
+			} else {
+				if len(lead) > 0 {
+					entries = append(entries, objectEntry{toks: lead})
+					lead = nil
+				}
+				entries = append(entries, objectEntry{toks: hclwrite.Tokens{tok}})
+			}
+			lineStart = true
+			i++
+			continue
+		case tok.Type == hclsyntax.TokenComment && lineStart:
+			lead = append(lead, tok)
+			lineStart = endsWithNewline(lead)
+			i++
+			continue
+		}
+
+		it := &objectItem{lead: lead}
+		lead = nil
+		sep := -1
+		depth := 0
+		j := i
+	scan:
+		for ; j < len(tokens); j++ {
+			switch tokens[j].Type {
+			case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen,
+				hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl,
+				hclsyntax.TokenOQuote, hclsyntax.TokenOHeredoc:
+				depth++
+			case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen,
+				hclsyntax.TokenTemplateSeqEnd, hclsyntax.TokenCQuote, hclsyntax.TokenCHeredoc:
+				depth--
+			case hclsyntax.TokenEqual, hclsyntax.TokenColon:
+				if depth == 0 && sep < 0 {
+					sep = j
+				}
+			case hclsyntax.TokenComma, hclsyntax.TokenNewline, hclsyntax.TokenComment:
+				if depth == 0 {
+					break scan
+				}
+			}
+		}
+		if sep < 0 {
+			return nil, false
+		}
+		key, ok := objectKey(tokens[i:sep])
+		if !ok {
+			return nil, false
+		}
+		it.key = key
+		it.body = tokens[i:j]
+		if j < len(tokens) && tokens[j].Type == hclsyntax.TokenComma {
+			it.comma = tokens[j]
+			j++
+		}
+		for j < len(tokens) && tokens[j].Type == hclsyntax.TokenComment && !endsWithNewline(it.trail) {
+			it.trail = append(it.trail, tokens[j])
+			j++
+		}
+		if j < len(tokens) && tokens[j].Type == hclsyntax.TokenNewline && !endsWithNewline(it.trail) {
+			it.newline = tokens[j]
+			j++
+		}
+		entries = append(entries, objectEntry{item: it})
+		lineStart = it.endsLine()
+		i = j
+	}
+	if len(lead) > 0 {
+		entries = append(entries, objectEntry{toks: lead})
+	}
+	return entries, true
+}
+
+func objectKey(tokens hclwrite.Tokens) (string, bool) {
+	switch {
+	case len(tokens) == 1 && tokens[0].Type == hclsyntax.TokenIdent:
+		return string(tokens[0].Bytes), true
+	case len(tokens) == 2 && tokens[0].Type == hclsyntax.TokenOQuote && tokens[1].Type == hclsyntax.TokenCQuote:
+		return "", true
+	case len(tokens) == 3 && tokens[0].Type == hclsyntax.TokenOQuote && tokens[1].Type == hclsyntax.TokenQuotedLit && tokens[2].Type == hclsyntax.TokenCQuote:
+		return string(tokens[1].Bytes), true
+	}
+	return "", false
+}
+
+func endsWithNewline(tokens hclwrite.Tokens) bool {
+	return len(tokens) > 0 && bytes.HasSuffix(tokens[len(tokens)-1].Bytes, []byte("\n"))
+}
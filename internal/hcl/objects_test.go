@@ -0,0 +1,90 @@
+// internal/hcl/objects_test.go
+package hcl
+
+import (
+	"testing"
+
+	hcl2 "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortObjectKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		changed bool
+	}{
+		{
+			name:    "multi-line with comments",
+			in:      "tags = {\n  # owning team\n  team = \"x\"\n  \"Name\" = \"n\" # shown in console\n  env = \"prod\"\n}\n",
+			want:    "tags = {\n  \"Name\" = \"n\" # shown in console\n  env    = \"prod\"\n  # owning team\n  team = \"x\"\n}\n",
+			changed: true,
+		},
+		{
+			name:    "blank lines separate groups",
+			in:      "tags = {\n  b = 1\n  a = 2\n\n  d = 3\n  c = 4\n}\n",
+			want:    "tags = {\n  a = 2\n  b = 1\n\n  c = 4\n  d = 3\n}\n",
+			changed: true,
+		},
+		{
+			name:    "single line keeps commas in place",
+			in:      "tags = { b = 1, a = 2 }\n",
+			want:    "tags = { a = 2, b = 1 }\n",
+			changed: true,
+		},
+		{
+			name:    "nested and inside calls",
+			in:      "tags = merge(var.tags, { z = { y = 1, x = 2 }, \"a\" = 3 })\n",
+			want:    "tags = merge(var.tags, { \"a\" = 3, z = { x = 2, y = 1 } })\n",
+			changed: true,
+		},
+		{
+			name:    "colon separators and complex values",
+			in:      "tags = {\n  b: <<EOT\nx\nEOT\n  a: \"${var.x}-${var.y}\"\n}\n",
+			want:    "tags = {\n  a : \"${var.x}-${var.y}\"\n  b : <<EOT\nx\nEOT\n}\n",
+			changed: true,
+		},
+		{
+			name: "already sorted",
+			in:   "tags = {\n  a = 1\n  b = 2\n}\n",
+			want: "tags = {\n  a = 1\n  b = 2\n}\n",
+		},
+		{
+			name: "computed key",
+			in:   "tags = { b = 1, (var.k) = 2 }\n",
+			want: "tags = { b = 1, (var.k) = 2 }\n",
+		},
+		{
+			name: "template key",
+			in:   "tags = { b = 1, \"${var.k}\" = 2 }\n",
+			want: "tags = { b = 1, \"${var.k}\" = 2 }\n",
+		},
+		{
+			name: "duplicate key",
+			in:   "tags = { b = 1, \"b\" = 2, a = 3 }\n",
+			want: "tags = { b = 1, \"b\" = 2, a = 3 }\n",
+		},
+		{
+			name: "for expression",
+			in:   "tags = { for k, v in var.tags : k => v }\n",
+			want: "tags = { for k, v in var.tags : k => v }\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tc.in), "in.tf", hcl2.InitialPos)
+			require.False(t, diags.HasErrors())
+			attr := file.Body().GetAttribute("tags")
+			toks, changed := SortObjectKeys(attr.Expr().BuildTokens(nil))
+			require.Equal(t, tc.changed, changed)
+			file.Body().SetAttributeRaw("tags", toks)
+			require.Equal(t, tc.want, string(file.Bytes()))
+
+			again, changed := SortObjectKeys(toks)
+			require.False(t, changed)
+			require.Equal(t, toks.Bytes(), again.Bytes())
+		})
+	}
+}
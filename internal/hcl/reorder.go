@@ -0,0 +1,205 @@
+// internal/hcl/reorder.go
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	hcl2 "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+type reorderer struct {
+	src          []byte
+	lineStarts   []int
+	commentLines map[int]bool
+	out          bytes.Buffer
+}
+
+func ReorderSpans(original, aligned []byte) ([]byte, error) {
+	trimmed := len(original) > 0 && original[len(original)-1] != '\n'
+	if trimmed {
+		original = append(append([]byte(nil), original...), '\n')
+	}
+	origFile, diags := hclsyntax.ParseConfig(original, "", hcl2.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse original: %w", diags)
+	}
+	alignedFile, diags := hclsyntax.ParseConfig(aligned, "", hcl2.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse aligned: %w", diags)
+	}
+	origBody, ok := origFile.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", origFile.Body)
+	}
+	alignedBody, ok := alignedFile.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", alignedFile.Body)
+	}
+	r := &reorderer{src: original, lineStarts: []int{0, 0}, commentLines: commentOnlyLines(original)}
+	for i, c := range original {
+		if c == '\n' {
+			r.lineStarts = append(r.lineStarts, i+1)
+		}
+	}
+	r.body(origBody, alignedBody, 0, len(original))
+	out := r.out.Bytes()
+	if trimmed {
+		out = out[:len(out)-1]
+	}
+	return out, nil
+}
+
+func (r *reorderer) body(orig, aligned *hclsyntax.Body, start, end int) {
+	spans, ok := r.spans(orig, start, end)
+	if !ok {
+		r.out.Write(r.src[start:end])
+		return
+	}
+	order := make([]string, len(spans))
+	for i, s := range spans {
+		order[i] = s.key
+	}
+	targets := map[string]*hclsyntax.Block{}
+	if aligned != nil {
+		items := bodyItems(aligned)
+		if sameKeys(order, items) {
+			for i, item := range items {
+				order[i] = item.key
+				targets[item.key] = item.block
+			}
+		}
+	}
+	byKey := make(map[string]bodyItem, len(spans))
+	for _, s := range spans {
+		byKey[s.key] = s
+	}
+	cursor := start
+	for i, s := range spans {
+		r.out.Write(r.src[cursor:s.start])
+		r.item(byKey[order[i]], targets[order[i]])
+		cursor = s.end
+	}
+	r.out.Write(r.src[cursor:end])
+}
+
+func (r *reorderer) item(s bodyItem, aligned *hclsyntax.Block) {
+	if s.block == nil {
+		r.out.Write(r.src[s.start:s.end])
+		return
+	}
+	var alignedBody *hclsyntax.Body
+	if aligned != nil {
+		alignedBody = aligned.Body
+	}
+	open, closing := s.block.OpenBraceRange.End.Byte, s.block.CloseBraceRange.Start.Byte
+	r.out.Write(r.src[s.start:open])
+	r.body(s.block.Body, alignedBody, open, closing)
+	r.out.Write(r.src[closing:s.end])
+}
+
+func (r *reorderer) spans(body *hclsyntax.Body, start, end int) ([]bodyItem, bool) {
+	items := bodyItems(body)
+	spans := make([]bodyItem, 0, len(items))
+	floor := start
+	for _, item := range items {
+		line := r.lineOf(item.start)
+		if line < 0 || r.lineStarts[line] < floor || !blank(r.src[r.lineStarts[line]:item.start]) {
+			return nil, false
+		}
+		for line-1 > 0 && r.commentLines[line-1] && r.lineStarts[line-1] >= floor {
+			line--
+		}
+		stop := bytes.IndexByte(r.src[item.end:end], '\n')
+		if stop < 0 {
+			return nil, false
+		}
+		s := bodyItem{key: item.key, start: r.lineStarts[line], end: item.end + stop + 1, block: item.block}
+		spans = append(spans, s)
+		floor = s.end
+	}
+	return spans, true
+}
+
+func (r *reorderer) lineOf(offset int) int {
+	i := sort.Search(len(r.lineStarts), func(i int) bool { return r.lineStarts[i] > offset })
+	return i - 1
+}
+
+type bodyItem struct {
+	key        string
+	start, end int
+	block      *hclsyntax.Block
+}
+
+func bodyItems(body *hclsyntax.Body) []bodyItem {
+	items := make([]bodyItem, 0, len(body.Attributes)+len(body.Blocks))
+	for name, attr := range body.Attributes {
+		items = append(items, bodyItem{key: name, start: attr.SrcRange.Start.Byte, end: attr.SrcRange.End.Byte})
+	}
+	seen := map[string]int{}
+	for _, block := range body.Blocks {
+		key := block.Type
+		for _, label := range block.Labels {
+			key += " " + strconv.Quote(label)
+		}
+		n := seen[key]
+		seen[key]++
+		items = append(items, bodyItem{
+			key:   key + " #" + strconv.Itoa(n),
+			start: block.TypeRange.Start.Byte,
+			end:   block.CloseBraceRange.End.Byte,
+			block: block,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].start < items[j].start })
+	return items
+}
+
+func sameKeys(keys []string, items []bodyItem) bool {
+	if len(keys) != len(items) {
+		return false
+	}
+	want := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		want[k] = true
+	}
+	for _, item := range items {
+		if !want[item.key] {
+			return false
+		}
+	}
+	return true
+}
+
+func blank(b []byte) bool {
+	return len(bytes.TrimLeft(b, " \t")) == 0
+}
+
+func commentOnlyLines(src []byte) map[int]bool {
+	lines := map[int]bool{}
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl2.InitialPos)
+	for i, tok := range tokens {
+		if tok.Type != hclsyntax.TokenComment {
+			continue
+		}
+		first := i == 0 || tokens[i-1].Type == hclsyntax.TokenNewline ||
+			(tokens[i-1].Type == hclsyntax.TokenComment && bytes.HasSuffix(tokens[i-1].Bytes, []byte("\n")))
+		if !first {
+			continue
+		}
+		last := tok.Range.End.Line
+		if bytes.HasSuffix(tok.Bytes, []byte("\n")) {
+			last--
+		} else if i+1 < len(tokens) && tokens[i+1].Type != hclsyntax.TokenNewline && tokens[i+1].Type != hclsyntax.TokenEOF {
+			continue
+		}
+		for line := tok.Range.Start.Line; line <= last; line++ {
+			lines[line] = true
+		}
+	}
+	return lines
+}
@@ -0,0 +1,68 @@
+// internal/hcl/reorder_test.go
+package hcl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorderSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		aligned  string
+		want     string
+	}{
+		{
+			name:     "attributes keep spacing and comments",
+			original: "v {\n  b    = 2 # two\n\n  # lead a\n  a = 1\n}\n",
+			aligned:  "v {\n  a = 1\n  b = 2\n}\n",
+			want:     "v {\n  # lead a\n  a = 1\n\n  b    = 2 # two\n}\n",
+		},
+		{
+			name:     "nested blocks reorder recursively",
+			original: "r {\n  inner {\n    y=2\n    x=1\n  }\n  z = 0\n}\n",
+			aligned:  "r {\n  z = 0\n  inner {\n    x = 1\n    y = 2\n  }\n}\n",
+			want:     "r {\n  z = 0\n  inner {\n    x=1\n    y=2\n  }\n}\n",
+		},
+		{
+			name:     "duplicate blocks keep identity",
+			original: "v {\n  c {\n    n = 1\n  }\n  a = 1\n  c {\n    n = 2\n  }\n}\n",
+			aligned:  "v {\n  a = 1\n  c {\n    n = 1\n  }\n  c {\n    n = 2\n  }\n}\n",
+			want:     "v {\n  a = 1\n  c {\n    n = 1\n  }\n  c {\n    n = 2\n  }\n}\n",
+		},
+		{
+			name:     "missing final newline",
+			original: "v {\n  b = 2\n  a = 1\n}",
+			aligned:  "v {\n  a = 1\n  b = 2\n}\n",
+			want:     "v {\n  a = 1\n  b = 2\n}",
+		},
+		{
+			name:     "single line bodies are left alone",
+			original: "v { b = 2 }\nw {\n  b = 2\n  a = 1\n}\n",
+			aligned:  "v {\n  b = 2\n}\nw {\n  a = 1\n  b = 2\n}\n",
+			want:     "v { b = 2 }\nw {\n  a = 1\n  b = 2\n}\n",
+		},
+		{
+			name:     "changed item set keeps original order",
+			original: "v {\n  b = 2\n  a = 1\n}\n",
+			aligned:  "v {\n  a = 1\n}\n",
+			want:     "v {\n  b = 2\n  a = 1\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReorderSpans([]byte(tt.original), []byte(tt.aligned))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestReorderSpansParseError(t *testing.T) {
+	_, err := ReorderSpans([]byte("v {\n"), []byte("v {}\n"))
+	require.ErrorContains(t, err, "parse original")
+	_, err = ReorderSpans([]byte("v {}\n"), []byte("v {\n"))
+	require.ErrorContains(t, err, "parse aligned")
+}
@@ -0,0 +1,200 @@
+// internal/hcl/spacing.go
+package hcl
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type Spacing struct {
+	MaxBlankLines  int
+	SeparateBlocks bool
+	TrimBraces     bool
+}
+
+func (s Spacing) Enabled() bool {
+	return s.MaxBlankLines > 0 || s.SeparateBlocks || s.TrimBraces
+}
+
+type spacingLine struct {
+	tokens      hclwrite.Tokens
+	depthBefore int
+	depthAfter  int
+	header      bool
+	closesBlock bool
+}
+
+func (l *spacingLine) blank() bool {
+	return len(l.tokens) == 1 && l.tokens[0].Type == hclsyntax.TokenNewline
+}
+
+func (l *spacingLine) end() bool {
+	for _, tok := range l.tokens {
+		if tok.Type != hclsyntax.TokenEOF {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *spacingLine) comment() bool {
+	return len(l.tokens) > 0 && l.tokens[0].Type == hclsyntax.TokenComment
+}
+
+func (l *spacingLine) opens() bool {
+	last := l.significant(len(l.tokens) - 1)
+	return last >= 0 && l.tokens[last].Type == hclsyntax.TokenOBrace
+}
+
+func (l *spacingLine) closes() bool {
+	return len(l.tokens) > 0 && l.tokens[0].Type == hclsyntax.TokenCBrace
+}
+
+func (l *spacingLine) significant(from int) int {
+	for i := from; i >= 0; i-- {
+		switch l.tokens[i].Type {
+		case hclsyntax.TokenNewline, hclsyntax.TokenComment, hclsyntax.TokenEOF:
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+func ApplySpacing(tokens hclwrite.Tokens, s Spacing) hclwrite.Tokens {
+	if !s.Enabled() {
+		return tokens
+	}
+	lines := spacingLines(tokens)
+	newline := DetectLineEnding(tokens)
+	out := make(hclwrite.Tokens, 0, len(tokens))
+	var prev *spacingLine
+	for i := 0; i < len(lines); {
+		if !lines[i].blank() {
+			out = append(out, lines[i].tokens...)
+			prev = lines[i]
+			i++
+			continue
+		}
+		j := i
+		for j < len(lines) && lines[j].blank() {
+			j++
+		}
+		var next *spacingLine
+		if j < len(lines) && !lines[j].end() {
+			next = lines[j]
+		}
+		n := blankRun(s, prev, next, lines[j:], j-i)
+		for k := 0; k < n; k++ {
+			if i+k < j {
+				out = append(out, lines[i+k].tokens...)
+			} else {
+				out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline})
+			}
+		}
+		i = j
+	}
+	return insertSeparators(out, s, newline)
+}
+
+func blankRun(s Spacing, prev, next *spacingLine, rest []*spacingLine, n int) int {
+	if s.TrimBraces && ((prev != nil && prev.opens()) || (next != nil && next.closes())) {
+		return 0
+	}
+	if s.MaxBlankLines > 0 && n > s.MaxBlankLines {
+		n = s.MaxBlankLines
+	}
+	if s.SeparateBlocks && prev != nil && next != nil && next.depthBefore == 0 && n > 1 && touchesBlock(prev, rest) {
+		n = 1
+	}
+	return n
+}
+
+func insertSeparators(tokens hclwrite.Tokens, s Spacing, newline []byte) hclwrite.Tokens {
+	if !s.SeparateBlocks {
+		return tokens
+	}
+	lines := spacingLines(tokens)
+	out := make(hclwrite.Tokens, 0, len(tokens)+len(lines))
+	for i, line := range lines {
+		if i > 0 && !line.blank() && !line.end() && line.depthBefore == 0 {
+			prev := lines[i-1]
+			if !prev.blank() && (prev.closesBlock || (!prev.comment() && startsBlock(lines[i:]))) {
+				out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: newline})
+			}
+		}
+		out = append(out, line.tokens...)
+	}
+	return out
+}
+
+func touchesBlock(prev *spacingLine, rest []*spacingLine) bool {
+	return prev.closesBlock || startsBlock(rest)
+}
+
+func startsBlock(lines []*spacingLine) bool {
+	for _, line := range lines {
+		switch {
+		case line.comment():
+			continue
+		case line.header:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func spacingLines(tokens hclwrite.Tokens) []*spacingLine {
+	var lines []*spacingLine
+	current := &spacingLine{}
+	depth := 0
+	opener := false
+	for _, tok := range tokens {
+		if len(current.tokens) == 0 {
+			current.depthBefore = depth
+			current.header = depth == 0 && tok.Type == hclsyntax.TokenIdent
+		}
+		current.tokens = append(current.tokens, tok)
+		switch tok.Type {
+		case hclsyntax.TokenEqual:
+			if depth == current.depthBefore {
+				current.header = false
+			}
+		case hclsyntax.TokenOBrace:
+			if depth == 0 && current.header {
+				opener = true
+			}
+			depth++
+		case hclsyntax.TokenCBrace:
+			depth--
+			if depth == 0 && opener {
+				current.closesBlock = true
+				opener = false
+			}
+		}
+		if tok.Type == hclsyntax.TokenNewline || bytes.HasSuffix(tok.Bytes, []byte("\n")) {
+			current.depthAfter = depth
+			current.header = current.header && hasBrace(current.tokens)
+			lines = append(lines, current)
+			current = &spacingLine{}
+		}
+	}
+	if len(current.tokens) > 0 {
+		current.depthAfter = depth
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+func hasBrace(tokens hclwrite.Tokens) bool {
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenOBrace {
+			return true
+		}
+	}
+	return false
+}
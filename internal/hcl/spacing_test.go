@@ -0,0 +1,79 @@
+// internal/hcl/spacing_test.go
+package hcl
+
+import (
+	"testing"
+
+	hcl2 "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySpacing(t *testing.T) {
+	tests := []struct {
+		name    string
+		spacing Spacing
+		in      string
+		want    string
+	}{
+		{
+			name: "disabled",
+			in:   "a {\n\n  x = 1\n\n\n  y = 2\n\n}\nb {}\n",
+			want: "a {\n\n  x = 1\n\n\n  y = 2\n\n}\nb {}\n",
+		},
+		{
+			name:    "max blank lines",
+			spacing: Spacing{MaxBlankLines: 1},
+			in:      "a {\n  x = 1\n\n\n\n  y = 2\n}\n\n\n\nb {}\n",
+			want:    "a {\n  x = 1\n\n  y = 2\n}\n\nb {}\n",
+		},
+		{
+			name:    "trim braces",
+			spacing: Spacing{TrimBraces: true},
+			in:      "a {\n\n  # lead\n  x = {\n\n    k = 1\n\n  }\n\n}\n",
+			want:    "a {\n  # lead\n  x = {\n    k = 1\n  }\n}\n",
+		},
+		{
+			name:    "separate blocks",
+			spacing: Spacing{SeparateBlocks: true},
+			in:      "a {\n  x = 1\n\n\n  y = 2\n}\nb {}\nc {} # c\n\n\nd {}\n",
+			want:    "a {\n  x = 1\n\n\n  y = 2\n}\n\nb {}\n\nc {} # c\n\nd {}\n",
+		},
+		{
+			name:    "attached comment moves with block",
+			spacing: Spacing{SeparateBlocks: true},
+			in:      "a {}\n# about b\n# more\nb {}\nx = 1\nc {}\n",
+			want:    "a {}\n\n# about b\n# more\nb {}\n\nx = 1\n\nc {}\n",
+		},
+		{
+			name:    "section comment keeps separation",
+			spacing: Spacing{MaxBlankLines: 2, SeparateBlocks: true},
+			in:      "a {}\n\n\n\n# section\n\n\nb {}\n",
+			want:    "a {}\n\n# section\n\nb {}\n",
+		},
+		{
+			name:    "top-level attributes",
+			spacing: Spacing{SeparateBlocks: true},
+			in:      "x = {\n  k = 1\n}\ny = 2\n\n\nz = 3\n",
+			want:    "x = {\n  k = 1\n}\ny = 2\n\n\nz = 3\n",
+		},
+		{
+			name:    "heredoc content untouched",
+			spacing: Spacing{MaxBlankLines: 1, TrimBraces: true},
+			in:      "a {\n  x = <<EOT\n{\n\n\n}\nEOT\n}\n",
+			want:    "a {\n  x = <<EOT\n{\n\n\n}\nEOT\n}\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, diags := hclwrite.ParseConfig([]byte(tc.in), "test.tf", hcl2.InitialPos)
+			require.False(t, diags.HasErrors())
+			got := ApplySpacing(f.BuildTokens(nil), tc.spacing).Bytes()
+			require.Equal(t, tc.want, string(got))
+
+			f, diags = hclwrite.ParseConfig(got, "test.tf", hcl2.InitialPos)
+			require.False(t, diags.HasErrors())
+			require.Equal(t, string(got), string(ApplySpacing(f.BuildTokens(nil), tc.spacing).Bytes()))
+		})
+	}
+}
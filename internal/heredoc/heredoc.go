@@ -0,0 +1,323 @@
+// internal/heredoc/heredoc.go
+package heredoc
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	indentUnit  = "  "
+	inlineWidth = 80
+)
+
+var markerPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+type Options struct {
+	Indent bool
+	Marker string
+	Inline bool
+}
+
+func (o Options) Enabled() bool {
+	return o.Indent || o.Marker != "" || o.Inline
+}
+
+func ValidateMarker(marker string) error {
+	if marker == "" || markerPattern.MatchString(marker) {
+		return nil
+	}
+	return fmt.Errorf("invalid heredoc marker %q: must be an identifier", marker)
+}
+
+type heredoc struct {
+	start, end int
+	flush      bool
+	marker     string
+	content    []byte
+	simple     bool
+}
+
+func Normalize(src []byte, opts Options) ([]byte, error) {
+	if !opts.Enabled() {
+		return src, nil
+	}
+	tokens, diags := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var out bytes.Buffer
+	cursor := 0
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].Type != hclsyntax.TokenOHeredoc {
+			continue
+		}
+		h, next, ok := scan(src, tokens, i)
+		if !ok {
+			return src, nil
+		}
+		i = next
+		replacement := rewrite(src, h, opts)
+		if replacement == nil {
+			continue
+		}
+		out.Write(src[cursor:h.start])
+		out.Write(replacement)
+		cursor = h.end
+	}
+	if cursor == 0 {
+		return src, nil
+	}
+	out.Write(src[cursor:])
+	return out.Bytes(), nil
+}
+
+func scan(src []byte, tokens hclsyntax.Tokens, open int) (heredoc, int, bool) {
+	depth := 0
+	simple := true
+	for i := open; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok.Type {
+		case hclsyntax.TokenOHeredoc:
+			depth++
+			if i != open {
+				simple = false
+			}
+			continue
+		case hclsyntax.TokenCHeredoc:
+			depth--
+			if depth > 0 {
+				continue
+			}
+		case hclsyntax.TokenNewline, hclsyntax.TokenComment:
+			simple = false
+			continue
+		default:
+			continue
+		}
+		head := tokens[open].Bytes
+		flush := bytes.HasPrefix(head, []byte("<<-"))
+		marker := strings.TrimSpace(strings.TrimLeft(string(head), "<-"))
+		return heredoc{
+			start:   tokens[open].Range.Start.Byte,
+			end:     tok.Range.End.Byte,
+			flush:   flush,
+			marker:  marker,
+			content: src[tokens[open].Range.End.Byte:tok.Range.Start.Byte],
+			simple:  simple,
+		}, i, true
+	}
+	return heredoc{}, 0, false
+}
+
+func rewrite(src []byte, h heredoc, opts Options) []byte {
+	original := src[h.start:h.end]
+	current := original
+	if opts.Indent && h.simple {
+		if candidate := reindent(h, lineIndent(src, h.start)); equivalent(original, candidate) {
+			current = candidate
+		}
+	}
+	if opts.Marker != "" && opts.Marker != h.marker {
+		if candidate := remark(current, h.marker, opts.Marker); candidate != nil && equivalent(original, candidate) {
+			current = candidate
+		}
+	}
+	if opts.Inline {
+		if candidate := inline(original); candidate != nil && equivalent(original, candidate) {
+			current = candidate
+		}
+	}
+	if bytes.Equal(current, original) {
+		return nil
+	}
+	return current
+}
+
+func reindent(h heredoc, base string) []byte {
+	lines := strings.SplitAfter(string(h.content), "\n")
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	strip := 0
+	if h.flush {
+		strip = -1
+		for _, line := range lines {
+			if blankLine(line) {
+				continue
+			}
+			if n := leadingSpaces(line); strip < 0 || n < strip {
+				strip = n
+			}
+		}
+		if strip < 0 {
+			strip = 0
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<<-%s\n", h.marker)
+	for _, line := range lines {
+		if blankLine(line) {
+			b.WriteString(line)
+			continue
+		}
+		b.WriteString(base + indentUnit)
+		b.WriteString(string([]rune(line)[strip:]))
+	}
+	b.WriteString(base + h.marker)
+	return []byte(b.String())
+}
+
+func remark(src []byte, from, to string) []byte {
+	head := bytes.IndexByte(src, '\n')
+	tail := bytes.LastIndexByte(src, '\n')
+	if head < 0 || tail < head {
+		return nil
+	}
+	for _, line := range strings.Split(string(src[head+1:tail+1]), "\n") {
+		if strings.TrimSpace(line) == to {
+			return nil
+		}
+	}
+	closing := src[tail+1:]
+	var b bytes.Buffer
+	b.Write(bytes.Replace(src[:head+1], []byte(from), []byte(to), 1))
+	b.Write(src[head+1 : tail+1])
+	b.Write(bytes.Replace(closing, []byte(from), []byte(to), 1))
+	return b.Bytes()
+}
+
+func inline(src []byte) []byte {
+	expr, ok := parse(src)
+	if !ok {
+		return nil
+	}
+	tmpl, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok {
+		return nil
+	}
+	var value strings.Builder
+	for _, part := range tmpl.Parts {
+		lit, ok := part.(*hclsyntax.LiteralValueExpr)
+		if !ok || lit.Val.Type() != cty.String {
+			return nil
+		}
+		value.WriteString(lit.Val.AsString())
+	}
+	text := value.String()
+	if strings.Count(text, "\n") != 1 || !strings.HasSuffix(text, "\n") {
+		return nil
+	}
+	quoted := quote(text)
+	if len(quoted) > inlineWidth {
+		return nil
+	}
+	return []byte(quoted)
+}
+
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == '"':
+			b.WriteString(`\"`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case (c == '$' || c == '%') && i+1 < len(s) && s[i+1] == '{':
+			b.WriteByte(c)
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func equivalent(a, b []byte) bool {
+	want, ok := evaluate(a)
+	if !ok {
+		return false
+	}
+	got, ok := evaluate(b)
+	if !ok {
+		return false
+	}
+	return slices.Equal(want, got)
+}
+
+func evaluate(src []byte) ([]string, bool) {
+	expr, ok := parse(src)
+	if !ok {
+		return nil, false
+	}
+	if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() && val.Type() == cty.String {
+		return []string{val.AsString()}, true
+	}
+	var parts []hclsyntax.Expression
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateExpr:
+		parts = e.Parts
+	case *hclsyntax.TemplateWrapExpr:
+		parts = []hclsyntax.Expression{e.Wrapped}
+	default:
+		return nil, false
+	}
+	var out []string
+	var lit strings.Builder
+	for _, part := range parts {
+		if l, ok := part.(*hclsyntax.LiteralValueExpr); ok && l.Val.Type() == cty.String {
+			lit.WriteString(l.Val.AsString())
+			continue
+		}
+		rng := part.Range()
+		out = append(out, "L"+lit.String(), "E"+string(src[rng.Start.Byte:rng.End.Byte]))
+		lit.Reset()
+	}
+	return append(out, "L"+lit.String()), true
+}
+
+func parse(src []byte) (hclsyntax.Expression, bool) {
+	buf := append(append([]byte(nil), src...), '\n')
+	expr, diags := hclsyntax.ParseExpression(buf, "", hcl.InitialPos)
+	return expr, !diags.HasErrors()
+}
+
+func lineIndent(src []byte, offset int) string {
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	end := start
+	for end < offset && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
+
+func blankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func leadingSpaces(line string) int {
+	n := 0
+	for _, r := range line {
+		if !unicode.IsSpace(r) {
+			break
+		}
+		n++
+	}
+	return n
+}
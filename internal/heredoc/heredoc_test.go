@@ -0,0 +1,103 @@
+// internal/heredoc/heredoc_test.go
+package heredoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		in   string
+		want string
+	}{
+		{
+			name: "disabled",
+			in:   "a = <<EOT\nx\nEOT\n",
+			want: "a = <<EOT\nx\nEOT\n",
+		},
+		{
+			name: "indent plain",
+			opts: Options{Indent: true},
+			in:   "v {\n  a = <<EOT\nx\n  y\nEOT\n}\n",
+			want: "v {\n  a = <<-EOT\n    x\n      y\n  EOT\n}\n",
+		},
+		{
+			name: "indent flush",
+			opts: Options{Indent: true},
+			in:   "v {\n  a = <<-EOT\n        x\n          y\n\n        z\n    EOT\n}\n",
+			want: "v {\n  a = <<-EOT\n    x\n      y\n\n    z\n  EOT\n}\n",
+		},
+		{
+			name: "indent keeps common prefix",
+			opts: Options{Indent: true},
+			in:   "a = <<EOT\n  x\n  y\nEOT\n",
+			want: "a = <<EOT\n  x\n  y\nEOT\n",
+		},
+		{
+			name: "indent interpolation",
+			opts: Options{Indent: true},
+			in:   "a = <<EOT\n${var.x} and ${var.y}\nEOT\n",
+			want: "a = <<-EOT\n  ${var.x} and ${var.y}\nEOT\n",
+		},
+		{
+			name: "indent skips multi-line interpolation",
+			opts: Options{Indent: true},
+			in:   "a = <<EOT\n${\nvar.x}\nEOT\n",
+			want: "a = <<EOT\n${\nvar.x}\nEOT\n",
+		},
+		{
+			name: "marker",
+			opts: Options{Marker: "EOT"},
+			in:   "a = <<EOF\nx\nEOF\nb = <<-END\n  y\n  END\n",
+			want: "a = <<EOT\nx\nEOT\nb = <<-EOT\n  y\n  EOT\n",
+		},
+		{
+			name: "marker clash",
+			opts: Options{Marker: "EOT"},
+			in:   "a = <<EOF\nEOT\nEOF\n",
+			want: "a = <<EOF\nEOT\nEOF\n",
+		},
+		{
+			name: "indent and marker",
+			opts: Options{Indent: true, Marker: "EOT"},
+			in:   "a = <<EOF\nx\nEOF\n",
+			want: "a = <<-EOT\n  x\nEOT\n",
+		},
+		{
+			name: "inline",
+			opts: Options{Inline: true},
+			in:   "a = <<-EOT\n  say \"hi\" $${x} \\o/\n  EOT\nb = <<EOT\nx\ny\nEOT\nc = <<EOT\n${var.x}\nEOT\n",
+			want: "a = \"say \\\"hi\\\" $${x} \\\\o/\\n\"\nb = <<EOT\nx\ny\nEOT\nc = <<EOT\n${var.x}\nEOT\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Normalize([]byte(tc.in), tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(got))
+
+			again, err := Normalize(got, tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, string(got), string(again))
+		})
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	require.True(t, equivalent([]byte("<<EOT\nx\nEOT"), []byte("<<-EOT\n    x\n  EOT")))
+	require.True(t, equivalent([]byte("<<EOT\n${a}\nEOT"), []byte("\"${a}\\n\"")))
+	require.False(t, equivalent([]byte("<<EOT\n  x\nEOT"), []byte("<<-EOT\n  x\nEOT")))
+	require.False(t, equivalent([]byte("<<EOT\n${a}\nEOT"), []byte("<<EOT\n${b}\nEOT")))
+}
+
+func TestValidateMarker(t *testing.T) {
+	require.NoError(t, ValidateMarker(""))
+	require.NoError(t, ValidateMarker("EOT"))
+	require.NoError(t, ValidateMarker("END_POLICY"))
+	require.Error(t, ValidateMarker("1EOT"))
+	require.Error(t, ValidateMarker("E OT"))
+}
@@ -0,0 +1,338 @@
+// internal/restructure/restructure.go
+package restructure
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ihcl "github.com/oferchen/hclalign/internal/hcl"
+)
+
+var DefaultRules = []string{"variable=variables.tf", "output=outputs.tf", "terraform=versions.tf"}
+
+type Rules struct {
+	targets map[string]string
+}
+
+func NewRules(specs []string) (*Rules, error) {
+	if len(specs) == 0 {
+		specs = DefaultRules
+	}
+	r := &Rules{targets: make(map[string]string, len(specs))}
+	for _, spec := range specs {
+		kind, name, ok := strings.Cut(spec, "=")
+		if !ok || kind == "" || name == "" {
+			return nil, fmt.Errorf("invalid restructure rule %q: want kind=file", spec)
+		}
+		if strings.ContainsAny(name, `/\`) || !strings.HasSuffix(name, ".tf") || name == ".tf" {
+			return nil, fmt.Errorf("invalid restructure rule %q: file must be a .tf file name without directories", spec)
+		}
+		if _, dup := r.targets[kind]; dup {
+			return nil, fmt.Errorf("duplicate restructure rule for %q", kind)
+		}
+		r.targets[kind] = name
+	}
+	return r, nil
+}
+
+func (r *Rules) Targets() []string {
+	seen := map[string]struct{}{}
+	var names []string
+	for _, name := range r.targets {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type File struct {
+	Name   string
+	Data   []byte
+	Remove bool
+}
+
+type moved struct {
+	before hclwrite.Tokens
+	block  *hclwrite.Block
+}
+
+func Apply(files []File, rules *Rules) ([]File, error) {
+	parsed := make([]*hclwrite.File, len(files))
+	index := make(map[string]int, len(files))
+	for i, f := range files {
+		file, diags := hclwrite.ParseConfig(f.Data, f.Name, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("parsing %s: %w", f.Name, diags)
+		}
+		parsed[i] = file
+		index[f.Name] = i
+	}
+
+	incoming := map[string][]moved{}
+	emptied := make([]bool, len(files))
+	for i, file := range parsed {
+		body := file.Body()
+		if len(body.Attributes()) > 0 {
+			continue
+		}
+		layout := ihcl.NewLayout(body)
+		var keep []ihcl.Item
+		for _, it := range layout.Items {
+			target, ok := rules.targets[it.Block.Type()]
+			if !ok || target == files[i].Name {
+				keep = append(keep, it)
+				continue
+			}
+			before := it.Before
+			if len(keep) == 0 && it.Block == layout.Items[0].Block {
+				before = nil
+			}
+			incoming[target] = append(incoming[target], moved{before: trimLeadingNewlines(before), block: it.Block})
+		}
+		if len(keep) == len(layout.Items) {
+			continue
+		}
+		rewrite(body, layout, keep)
+		emptied[i] = len(keep) == 0 && len(bytes.TrimSpace(file.Bytes())) == 0
+	}
+
+	var created []string
+	for target := range incoming {
+		if _, ok := index[target]; !ok {
+			created = append(created, target)
+		}
+	}
+	sort.Strings(created)
+	for _, name := range created {
+		index[name] = len(files)
+		files = append(files, File{Name: name})
+		parsed = append(parsed, hclwrite.NewEmptyFile())
+		emptied = append(emptied, false)
+	}
+
+	out := make([]File, len(files))
+	for i, f := range files {
+		body := parsed[i].Body()
+		if emptied[i] {
+			body.Clear()
+		}
+		for _, m := range incoming[f.Name] {
+			appendBlock(body, m)
+		}
+		if err := mergeTerraform(body); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if emptied[i] && len(incoming[f.Name]) == 0 {
+			out[i] = File{Name: f.Name, Remove: true}
+			continue
+		}
+		out[i] = File{Name: f.Name, Data: parsed[i].Bytes()}
+	}
+	return out, nil
+}
+
+func rewrite(body *hclwrite.Body, layout *ihcl.Layout, keep []ihcl.Item) {
+	header := layout.Items[0].Before
+	first := layout.Items[0].Block
+	for _, it := range layout.Items {
+		body.RemoveBlock(it.Block)
+	}
+	w := ihcl.NewWriter(body, layout.Newline)
+	w.Tokens(header)
+	for i, it := range keep {
+		before := it.Before
+		switch {
+		case it.Block == first:
+			before = nil
+		case i == 0:
+			before = trimLeadingNewlines(before)
+		}
+		w.Tokens(before)
+		w.Block(it.Block)
+	}
+	w.Tokens(layout.Tail)
+}
+
+func appendBlock(body *hclwrite.Body, m moved) {
+	if len(bytes.TrimSpace(body.BuildTokens(nil).Bytes())) > 0 {
+		toks := body.BuildTokens(nil)
+		if n := len(toks); n > 0 && toks[n-1].Type != hclsyntax.TokenNewline {
+			body.AppendNewline()
+		}
+		body.AppendNewline()
+	}
+	body.AppendUnstructuredTokens(m.before)
+	body.AppendBlock(m.block)
+}
+
+func mergeTerraform(body *hclwrite.Body) error {
+	var blocks []*hclwrite.Block
+	for _, b := range body.Blocks() {
+		if b.Type() == "terraform" && len(b.Labels()) == 0 {
+			blocks = append(blocks, b)
+		}
+	}
+	if len(blocks) < 2 {
+		return nil
+	}
+	layout := ihcl.NewLayout(body)
+	merged := map[*hclwrite.Block]bool{}
+	for _, b := range blocks[1:] {
+		merged[b] = true
+	}
+	var keep []ihcl.Item
+	var lead hclwrite.Tokens
+	for _, it := range layout.Items {
+		if !merged[it.Block] {
+			keep = append(keep, it)
+			continue
+		}
+		lead = append(lead, comments(it.Before)...)
+		lead = append(lead, leadComments(it.Block)...)
+		if err := mergeBody(blocks[0].Body(), it.Block.Body(), nil); err != nil {
+			return err
+		}
+	}
+	for i := range keep {
+		if keep[i].Block == blocks[0] {
+			keep[i].Before = append(append(hclwrite.Tokens{}, keep[i].Before...), lead...)
+		}
+	}
+	if layout.Items[0].Block == blocks[0] {
+		layout.Items[0].Before = append(append(hclwrite.Tokens{}, layout.Items[0].Before...), lead...)
+	}
+	rewrite(body, layout, keep)
+	return nil
+}
+
+func mergeBody(dst, src *hclwrite.Body, lead hclwrite.Tokens) error {
+	lead = append(hclwrite.Tokens{}, lead...)
+	var extra []ihcl.Item
+	for _, it := range ihcl.NewLayout(src).Items {
+		if it.Kind == ihcl.ItemAttribute {
+			existing := dst.GetAttribute(it.Name)
+			if existing == nil {
+				extra = append(extra, it)
+				continue
+			}
+			if !sameTokens(existing.Expr().BuildTokens(nil), it.Attr.Expr().BuildTokens(nil)) {
+				return fmt.Errorf("conflicting values for %q in merged terraform blocks", it.Name)
+			}
+			lead = append(append(lead, comments(it.Before)...), comments(it.Lead)...)
+			continue
+		}
+		if match := findBlock(dst, it.Block); match != nil {
+			dup := append(comments(it.Before), leadComments(it.Block)...)
+			if it.Block.Type() == "required_providers" {
+				if err := mergeBody(match.Body(), it.Block.Body(), dup); err != nil {
+					return err
+				}
+				continue
+			}
+			if !sameTokens(match.Body().BuildTokens(nil), it.Block.Body().BuildTokens(nil)) {
+				return fmt.Errorf("conflicting values for %q in merged terraform blocks", blockName(it.Block))
+			}
+			lead = append(lead, dup...)
+			continue
+		}
+		if other := findState(dst); other != nil && isState(it.Block) {
+			return fmt.Errorf("conflicting values for %q and %q in merged terraform blocks", blockName(other), blockName(it.Block))
+		}
+		extra = append(extra, it)
+	}
+	if len(extra) == 0 {
+		dst.AppendUnstructuredTokens(lead)
+		return nil
+	}
+
+	layout := ihcl.NewLayout(dst)
+	for _, it := range layout.Items {
+		if it.Kind == ihcl.ItemAttribute {
+			dst.RemoveAttribute(it.Name)
+		} else {
+			dst.RemoveBlock(it.Block)
+		}
+	}
+	w := ihcl.NewWriter(dst, layout.Newline)
+	items := layout.Items
+	for i, it := range append(items, extra...) {
+		before := it.Before
+		if i == len(items) {
+			before = append(append(hclwrite.Tokens{}, lead...), trimLeadingNewlines(before)...)
+		}
+		w.Tokens(before)
+		if it.Kind == ihcl.ItemBlock {
+			w.Block(it.Block)
+			continue
+		}
+		w.Tokens(it.Lead)
+		w.Attr(it.Name, it.Expr)
+	}
+	w.Tokens(layout.Tail)
+	w.Finish(layout.TrailingComma)
+	return nil
+}
+
+func findBlock(body *hclwrite.Body, block *hclwrite.Block) *hclwrite.Block {
+	for _, b := range body.Blocks() {
+		if b.Type() == block.Type() && strings.Join(b.Labels(), "\x00") == strings.Join(block.Labels(), "\x00") {
+			return b
+		}
+	}
+	return nil
+}
+
+func findState(body *hclwrite.Body) *hclwrite.Block {
+	for _, b := range body.Blocks() {
+		if isState(b) {
+			return b
+		}
+	}
+	return nil
+}
+
+func isState(block *hclwrite.Block) bool {
+	return block.Type() == "backend" || block.Type() == "cloud"
+}
+
+func blockName(block *hclwrite.Block) string {
+	return strings.Join(append([]string{block.Type()}, block.Labels()...), ".")
+}
+
+func leadComments(block *hclwrite.Block) hclwrite.Tokens {
+	toks := block.BuildTokens(nil)
+	n := 0
+	for n < len(toks) && toks[n].Type == hclsyntax.TokenComment {
+		n++
+	}
+	return toks[:n]
+}
+
+func comments(toks hclwrite.Tokens) hclwrite.Tokens {
+	var out hclwrite.Tokens
+	for _, tok := range toks {
+		if tok.Type == hclsyntax.TokenComment {
+			out = append(out, tok)
+		}
+	}
+	return out
+}
+
+func sameTokens(a, b hclwrite.Tokens) bool {
+	return bytes.Equal(bytes.TrimSpace(a.Bytes()), bytes.TrimSpace(b.Bytes()))
+}
+
+func trimLeadingNewlines(tokens hclwrite.Tokens) hclwrite.Tokens {
+	for len(tokens) > 0 && tokens[0].Type == hclsyntax.TokenNewline {
+		tokens = tokens[1:]
+	}
+	return tokens
+}
@@ -0,0 +1,145 @@
+// internal/restructure/restructure_test.go
+package restructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRules(t *testing.T) {
+	r, err := NewRules(nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"outputs.tf", "variables.tf", "versions.tf"}, r.Targets())
+
+	tests := []struct {
+		spec []string
+		msg  string
+	}{
+		{spec: []string{"variable"}, msg: "want kind=file"},
+		{spec: []string{"=variables.tf"}, msg: "want kind=file"},
+		{spec: []string{"variable=mod/variables.tf"}, msg: "without directories"},
+		{spec: []string{"variable=variables.hcl"}, msg: "without directories"},
+		{spec: []string{"variable=a.tf", "variable=b.tf"}, msg: `duplicate restructure rule for "variable"`},
+	}
+	for _, tc := range tests {
+		_, err := NewRules(tc.spec)
+		require.ErrorContains(t, err, tc.msg)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+		in    []File
+		want  []File
+	}{
+		{
+			name: "moves blocks with their comments",
+			in: []File{
+				{Name: "main.tf", Data: []byte("# Header.\n\n# About a.\nvariable \"a\" {}\n\nresource \"r\" \"x\" {}\n\n# Result.\noutput \"o\" {}\n")},
+			},
+			want: []File{
+				{Name: "main.tf", Data: []byte("# Header.\n\nresource \"r\" \"x\" {}\n")},
+				{Name: "outputs.tf", Data: []byte("# Result.\noutput \"o\" {}\n")},
+				{Name: "variables.tf", Data: []byte("# About a.\nvariable \"a\" {}\n")},
+			},
+		},
+		{
+			name: "appends to existing targets and removes emptied files",
+			in: []File{
+				{Name: "inputs.tf", Data: []byte("variable \"b\" {}\n")},
+				{Name: "variables.tf", Data: []byte("variable \"a\" {}\n")},
+			},
+			want: []File{
+				{Name: "inputs.tf", Remove: true},
+				{Name: "variables.tf", Data: []byte("variable \"a\" {}\n\nvariable \"b\" {}\n")},
+			},
+		},
+		{
+			name: "merges terraform blocks",
+			in: []File{
+				{Name: "main.tf", Data: []byte("# Pin.\nterraform {\n  required_version = \">= 1.5\"\n  required_providers {\n    a = {}\n  }\n}\n")},
+				{Name: "versions.tf", Data: []byte("terraform {\n  required_providers {\n    a = {}\n    b = {}\n  }\n}\n")},
+			},
+			want: []File{
+				{Name: "main.tf", Remove: true},
+				{Name: "versions.tf", Data: []byte("# Pin.\nterraform {\n  required_providers {\n    a = {}\n    b = {}\n  }\n  required_version = \">= 1.5\"\n}\n")},
+			},
+		},
+		{
+			name: "keeps comments of merged duplicates",
+			in: []File{
+				{Name: "main.tf", Data: []byte("terraform {\n  # Minimum supported.\n  required_version = \">= 1.5\"\n  # Pinned.\n  required_providers {\n    a = {}\n  }\n}\n")},
+				{Name: "versions.tf", Data: []byte("terraform {\n  required_version = \">= 1.5\"\n  required_providers {\n    a = {}\n  }\n}\n")},
+			},
+			want: []File{
+				{Name: "main.tf", Remove: true},
+				{Name: "versions.tf", Data: []byte("terraform {\n  required_version = \">= 1.5\"\n  required_providers {\n    a = {}\n    # Pinned.\n  }\n  # Minimum supported.\n}\n")},
+			},
+		},
+		{
+			name:  "custom rules",
+			rules: []string{"locals=locals.tf"},
+			in: []File{
+				{Name: "main.tf", Data: []byte("locals {}\nvariable \"a\" {}\n")},
+			},
+			want: []File{
+				{Name: "main.tf", Data: []byte("variable \"a\" {}\n")},
+				{Name: "locals.tf", Data: []byte("locals {}\n")},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := NewRules(tc.rules)
+			require.NoError(t, err)
+			got, err := Apply(tc.in, rules)
+			require.NoError(t, err)
+			require.Len(t, got, len(tc.want))
+			for i := range tc.want {
+				require.Equal(t, tc.want[i].Name, got[i].Name)
+				require.Equal(t, tc.want[i].Remove, got[i].Remove, got[i].Name)
+				require.Equal(t, string(tc.want[i].Data), string(got[i].Data), got[i].Name)
+			}
+		})
+	}
+}
+
+func TestApplyConflict(t *testing.T) {
+	rules, err := NewRules(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		main string
+		msg  string
+	}{
+		{
+			name: "attribute",
+			main: "terraform {\n  required_version = \">= 1.5\"\n}\n",
+			msg:  `conflicting values for "required_version"`,
+		},
+		{
+			name: "nested block",
+			main: "terraform {\n  backend \"s3\" {\n    bucket = \"a\"\n  }\n}\n",
+			msg:  `conflicting values for "backend.s3"`,
+		},
+		{
+			name: "backend and cloud",
+			main: "terraform {\n  cloud {\n    organization = \"a\"\n  }\n}\n",
+			msg:  `conflicting values for "backend.s3" and "cloud"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := Apply([]File{
+				{Name: "main.tf", Data: []byte(tc.main)},
+				{Name: "versions.tf", Data: []byte("terraform {\n  required_version = \">= 1.6\"\n  backend \"s3\" {\n    bucket = \"b\"\n  }\n}\n")},
+			}, rules)
+			require.ErrorContains(t, err, tc.msg)
+			require.Nil(t, out)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// internal/tool/tool.go
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type Kind string
+
+const (
+	Auto      Kind = "auto"
+	Terraform Kind = "terraform"
+	Tofu      Kind = "tofu"
+)
+
+type Binary struct {
+	Kind Kind
+	Path string
+}
+
+var lookPath = exec.LookPath
+
+func Validate(kind string) error {
+	switch Kind(kind) {
+	case Auto, Terraform, Tofu, "":
+		return nil
+	default:
+		return fmt.Errorf("unknown tool %q: must be terraform, tofu or auto", kind)
+	}
+}
+
+func Resolve(kind, bin string) (Binary, error) {
+	if err := Validate(kind); err != nil {
+		return Binary{}, err
+	}
+	k := Kind(kind)
+	if bin != "" {
+		if k == Auto || k == "" {
+			k = detect(bin)
+		}
+		path, err := lookPath(bin)
+		if err != nil {
+			return Binary{}, fmt.Errorf("%s binary %q: %w", Binary{Kind: k}.Name(), bin, err)
+		}
+		return Binary{Kind: k, Path: path}, nil
+	}
+	candidates := []Kind{Terraform, Tofu}
+	if k == Terraform || k == Tofu {
+		candidates = []Kind{k}
+	}
+	for _, c := range candidates {
+		if path, err := lookPath(string(c)); err == nil {
+			return Binary{Kind: c, Path: path}, nil
+		}
+	}
+	return Binary{Kind: candidates[0]}, nil
+}
+
+func detect(path string) Kind {
+	if strings.Contains(strings.ToLower(filepath.Base(path)), "tofu") {
+		return Tofu
+	}
+	return Terraform
+}
+
+func (b Binary) Available() bool { return b.Path != "" }
+
+func (b Binary) Name() string {
+	if b.Kind == Tofu {
+		return string(Tofu)
+	}
+	return string(Terraform)
+}
+
+type Version struct {
+	Tool               Kind
+	Version            string
+	ProviderSelections map[string]string
+}
+
+func ParseVersion(data []byte) (Version, error) {
+	var raw struct {
+		TerraformVersion   string                     `json:"terraform_version"`
+		TofuVersion        string                     `json:"tofu_version"`
+		ProviderSelections map[string]json.RawMessage `json:"provider_selections"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Version{}, err
+	}
+	v := Version{Tool: Terraform, Version: raw.TerraformVersion, ProviderSelections: map[string]string{}}
+	if raw.TofuVersion != "" {
+		v.Tool, v.Version = Tofu, raw.TofuVersion
+	}
+	for name, sel := range raw.ProviderSelections {
+		var version string
+		if err := json.Unmarshal(sel, &version); err != nil {
+			var obj struct {
+				Version string `json:"version"`
+			}
+			if err := json.Unmarshal(sel, &obj); err != nil {
+				return Version{}, fmt.Errorf("provider selection %s: %w", name, err)
+			}
+			version = obj.Version
+		}
+		v.ProviderSelections[name] = version
+	}
+	return v, nil
+}
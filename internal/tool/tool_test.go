@@ -0,0 +1,91 @@
+// internal/tool/tool_test.go
+package tool
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stubLookPath(t *testing.T, found map[string]string) {
+	t.Helper()
+	orig := lookPath
+	lookPath = func(name string) (string, error) {
+		if p, ok := found[name]; ok {
+			return p, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = orig })
+}
+
+func TestResolve(t *testing.T) {
+	stubLookPath(t, map[string]string{
+		"terraform":           "/usr/bin/terraform",
+		"tofu":                "/usr/bin/tofu",
+		"/opt/tofu-1.8/tofu":  "/opt/tofu-1.8/tofu",
+		"/opt/bin/tf-wrapper": "/opt/bin/tf-wrapper",
+	})
+	tests := []struct {
+		name, kind, bin string
+		want            Binary
+	}{
+		{name: "auto prefers terraform", kind: "auto", want: Binary{Kind: Terraform, Path: "/usr/bin/terraform"}},
+		{name: "empty kind is auto", want: Binary{Kind: Terraform, Path: "/usr/bin/terraform"}},
+		{name: "tofu", kind: "tofu", want: Binary{Kind: Tofu, Path: "/usr/bin/tofu"}},
+		{name: "explicit binary detects tofu", kind: "auto", bin: "/opt/tofu-1.8/tofu", want: Binary{Kind: Tofu, Path: "/opt/tofu-1.8/tofu"}},
+		{name: "explicit binary defaults to terraform", bin: "/opt/bin/tf-wrapper", want: Binary{Kind: Terraform, Path: "/opt/bin/tf-wrapper"}},
+		{name: "explicit kind wins", kind: "tofu", bin: "/opt/bin/tf-wrapper", want: Binary{Kind: Tofu, Path: "/opt/bin/tf-wrapper"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.kind, tt.bin)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveFallbacks(t *testing.T) {
+	stubLookPath(t, map[string]string{"tofu": "/usr/bin/tofu"})
+	got, err := Resolve("auto", "")
+	require.NoError(t, err)
+	require.Equal(t, Binary{Kind: Tofu, Path: "/usr/bin/tofu"}, got)
+
+	got, err = Resolve("terraform", "")
+	require.NoError(t, err)
+	require.False(t, got.Available())
+	require.Equal(t, "terraform", got.Name())
+
+	_, err = Resolve("auto", "missing-bin")
+	require.True(t, errors.Is(err, exec.ErrNotFound))
+	require.ErrorContains(t, err, `terraform binary "missing-bin"`)
+
+	_, err = Resolve("tofu", "missing-bin")
+	require.ErrorContains(t, err, `tofu binary "missing-bin"`)
+
+	_, err = Resolve("auto", "/opt/bin/tofu-missing")
+	require.ErrorContains(t, err, `tofu binary "/opt/bin/tofu-missing"`)
+
+	_, err = Resolve("opentofu", "")
+	require.ErrorContains(t, err, `unknown tool "opentofu"`)
+}
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion([]byte(`{"terraform_version":"1.9.0","provider_selections":{"registry.terraform.io/hashicorp/aws":"5.0.0"}}`))
+	require.NoError(t, err)
+	require.Equal(t, Version{Tool: Terraform, Version: "1.9.0", ProviderSelections: map[string]string{"registry.terraform.io/hashicorp/aws": "5.0.0"}}, v)
+
+	v, err = ParseVersion([]byte(`{"terraform_version":"1.0.0","provider_selections":{"registry.terraform.io/hashicorp/test":{"version":"1.0.0"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", v.ProviderSelections["registry.terraform.io/hashicorp/test"])
+
+	v, err = ParseVersion([]byte(`{"tofu_version":"1.8.2","platform":"linux_amd64","provider_selections":{"registry.opentofu.org/hashicorp/aws":"5.1.0"}}`))
+	require.NoError(t, err)
+	require.Equal(t, Version{Tool: Tofu, Version: "1.8.2", ProviderSelections: map[string]string{"registry.opentofu.org/hashicorp/aws": "5.1.0"}}, v)
+
+	_, err = ParseVersion([]byte(`{"provider_selections":{"x":[1]}}`))
+	require.ErrorContains(t, err, "provider selection x")
+}
@@ -0,0 +1,132 @@
+// internal/trace/trace.go
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const category = "hclalign"
+
+type Event struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat,omitempty"`
+	Ph   string            `json:"ph"`
+	Ts   int64             `json:"ts"`
+	Dur  int64             `json:"dur,omitempty"`
+	Pid  int               `json:"pid"`
+	Tid  int               `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+type Tracer struct {
+	mu      sync.Mutex
+	start   time.Time
+	events  []Event
+	threads map[int]string
+}
+
+func New() *Tracer {
+	return &Tracer{start: time.Now(), threads: map[int]string{0: "main"}}
+}
+
+func (t *Tracer) Span(tid int, name, file string) func() {
+	if t == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		ev := Event{
+			Name: name,
+			Cat:  category,
+			Ph:   "X",
+			Ts:   begin.Sub(t.start).Microseconds(),
+			Dur:  time.Since(begin).Microseconds(),
+			Tid:  tid,
+		}
+		if file != "" {
+			ev.Args = map[string]string{"file": file}
+		}
+		t.mu.Lock()
+		t.events = append(t.events, ev)
+		t.mu.Unlock()
+	}
+}
+
+func (t *Tracer) NameThread(tid int, name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.threads[tid] = name
+	t.mu.Unlock()
+}
+
+func (t *Tracer) Events() []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := make([]Event, 0, len(t.threads)+len(t.events))
+	for tid := 0; len(events) < len(t.threads); tid++ {
+		if name, ok := t.threads[tid]; ok {
+			events = append(events, Event{Name: "thread_name", Ph: "M", Tid: tid, Args: map[string]string{"name": name}})
+		}
+	}
+	return append(events, t.events...)
+}
+
+func (t *Tracer) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		TraceEvents     []Event `json:"traceEvents"`
+		DisplayTimeUnit string  `json:"displayTimeUnit"`
+	}{TraceEvents: t.Events(), DisplayTimeUnit: "ms"})
+}
+
+func (t *Tracer) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create trace file: %w", err)
+	}
+	bw := bufio.NewWriter(f)
+	if err := t.Write(bw); err != nil {
+		f.Close()
+		return fmt.Errorf("write trace file: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("write trace file: %w", err)
+	}
+	return f.Close()
+}
+
+type tracerKey struct{}
+
+type threadKey struct{}
+
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+func FromContext(ctx context.Context) *Tracer {
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	return t
+}
+
+func WithThread(ctx context.Context, tid int, name string) context.Context {
+	FromContext(ctx).NameThread(tid, name)
+	return context.WithValue(ctx, threadKey{}, tid)
+}
+
+func Start(ctx context.Context, name, file string) func() {
+	t := FromContext(ctx)
+	if t == nil {
+		return func() {}
+	}
+	tid, _ := ctx.Value(threadKey{}).(int)
+	return t.Span(tid, name, file)
+}
@@ -0,0 +1,62 @@
+// internal/trace/trace_test.go
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartWithoutTracer(t *testing.T) {
+	done := Start(context.Background(), "parse", "a.tf")
+	done()
+	require.Nil(t, FromContext(context.Background()))
+	var tr *Tracer
+	tr.Span(1, "x", "")()
+	tr.NameThread(1, "worker 1")
+}
+
+func TestTracerEvents(t *testing.T) {
+	tr := New()
+	ctx := WithTracer(context.Background(), tr)
+	Start(ctx, "scan", "dir")()
+	wctx := WithThread(ctx, 1, "worker 1")
+	Start(wctx, "parse", "a.tf")()
+
+	events := tr.Events()
+	require.Len(t, events, 4)
+	require.Equal(t, Event{Name: "thread_name", Ph: "M", Tid: 0, Args: map[string]string{"name": "main"}}, events[0])
+	require.Equal(t, Event{Name: "thread_name", Ph: "M", Tid: 1, Args: map[string]string{"name": "worker 1"}}, events[1])
+	require.Equal(t, "scan", events[2].Name)
+	require.Equal(t, 0, events[2].Tid)
+	require.Equal(t, "X", events[2].Ph)
+	require.Equal(t, map[string]string{"file": "dir"}, events[2].Args)
+	require.Equal(t, "parse", events[3].Name)
+	require.Equal(t, 1, events[3].Tid)
+	require.GreaterOrEqual(t, events[3].Ts, events[2].Ts)
+}
+
+func TestTracerWriteFile(t *testing.T) {
+	tr := New()
+	tr.Span(0, "scan", "")()
+	path := filepath.Join(t.TempDir(), "trace.json")
+	require.NoError(t, tr.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var doc struct {
+		TraceEvents     []Event `json:"traceEvents"`
+		DisplayTimeUnit string  `json:"displayTimeUnit"`
+	}
+	require.NoError(t, json.NewDecoder(bytes.NewReader(data)).Decode(&doc))
+	require.Equal(t, "ms", doc.DisplayTimeUnit)
+	require.Len(t, doc.TraceEvents, 2)
+	require.Equal(t, "scan", doc.TraceEvents[1].Name)
+
+	require.Error(t, tr.WriteFile(filepath.Join(t.TempDir(), "missing", "trace.json")))
+}
@@ -0,0 +1,223 @@
+// internal/verify/comments.go
+package verify
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+const (
+	PositionBefore = "before"
+	PositionAfter  = "after"
+	PositionInside = "inside"
+	PositionEnd    = "end"
+	PositionHeader = "header"
+)
+
+type CommentAnchor struct {
+	Block    string
+	Position string
+	Target   string
+}
+
+func (a CommentAnchor) String() string {
+	owner := "top level"
+	if a.Block != "" {
+		owner = "block " + a.Block
+	}
+	if a.Position == PositionEnd {
+		return "at end of " + owner
+	}
+	if a.Position == PositionHeader {
+		return "at start of " + owner
+	}
+	return fmt.Sprintf("%s %s in %s", a.Position, a.Target, owner)
+}
+
+type CommentFingerprint struct {
+	Text  string
+	Range hcl.Range
+	CommentAnchor
+}
+
+type CommentFinding struct {
+	Comment string
+	Range   hcl.Range
+	From    CommentAnchor
+	To      CommentAnchor
+	Dropped bool
+}
+
+func (f CommentFinding) String() string {
+	if f.Dropped {
+		return fmt.Sprintf("comment %q (line %d) dropped, was %s", f.Comment, f.Range.Start.Line, f.From)
+	}
+	return fmt.Sprintf("comment %q (line %d) moved from %s to %s", f.Comment, f.Range.Start.Line, f.From, f.To)
+}
+
+type CommentError struct {
+	Findings []CommentFinding
+}
+
+func (e *CommentError) Error() string {
+	msgs := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		msgs[i] = f.String()
+	}
+	return "comment audit failed: " + strings.Join(msgs, "; ")
+}
+
+func AuditComments(original, aligned []byte) ([]CommentFinding, error) {
+	want, err := Fingerprints(original)
+	if err != nil {
+		return nil, fmt.Errorf("parse original: %w", err)
+	}
+	got, err := Fingerprints(aligned)
+	if err != nil {
+		return nil, fmt.Errorf("parse output: %w", err)
+	}
+
+	remaining := make(map[string][]CommentAnchor, len(got))
+	for _, fp := range got {
+		remaining[fp.Text] = append(remaining[fp.Text], fp.CommentAnchor)
+	}
+	var unmatched []CommentFingerprint
+	for _, fp := range want {
+		anchors := remaining[fp.Text]
+		idx := -1
+		for i, a := range anchors {
+			if a == fp.CommentAnchor {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			unmatched = append(unmatched, fp)
+			continue
+		}
+		remaining[fp.Text] = append(anchors[:idx], anchors[idx+1:]...)
+	}
+
+	findings := make([]CommentFinding, 0, len(unmatched))
+	for _, fp := range unmatched {
+		f := CommentFinding{Comment: fp.Text, Range: fp.Range, From: fp.CommentAnchor}
+		if anchors := remaining[fp.Text]; len(anchors) > 0 {
+			f.To = anchors[0]
+			remaining[fp.Text] = anchors[1:]
+		} else {
+			f.Dropped = true
+		}
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+func Fingerprints(src []byte) ([]CommentFingerprint, error) {
+	file, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", file.Body)
+	}
+	tokens, diags := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var fps []CommentFingerprint
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenComment {
+			continue
+		}
+		text := strings.TrimRight(string(tok.Bytes), " \t\r\n")
+		rng := tok.Range
+		if len(text) < len(tok.Bytes) && !strings.Contains(text, "\n") {
+			rng.End = hcl.Pos{Line: rng.Start.Line, Column: rng.Start.Column + utf8.RuneCountInString(text), Byte: rng.Start.Byte + len(text)}
+		}
+		fps = append(fps, CommentFingerprint{
+			Text:          text,
+			Range:         rng,
+			CommentAnchor: anchorFor(src, body, "", tok.Range),
+		})
+	}
+	return fps, nil
+}
+
+type bodyItem struct {
+	name  string
+	rng   hcl.Range
+	attr  bool
+	block *hclsyntax.Block
+}
+
+func bodyItems(body *hclsyntax.Body) []bodyItem {
+	items := make([]bodyItem, 0, len(body.Attributes)+len(body.Blocks))
+	for name, attr := range body.Attributes {
+		items = append(items, bodyItem{name: "attribute " + strconv.Quote(name), rng: attr.SrcRange, attr: true})
+	}
+	for _, block := range body.Blocks {
+		items = append(items, bodyItem{name: "block " + blockKey(block), rng: block.Range(), block: block})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].rng.Start.Byte < items[j].rng.Start.Byte })
+	return items
+}
+
+func blockKey(block *hclsyntax.Block) string {
+	key := block.Type
+	for _, label := range block.Labels {
+		key += " " + strconv.Quote(label)
+	}
+	return key
+}
+
+func anchorFor(src []byte, body *hclsyntax.Body, path string, rng hcl.Range) CommentAnchor {
+	items := bodyItems(body)
+	for _, it := range items {
+		if it.block != nil && rng.Start.Byte >= it.block.OpenBraceRange.End.Byte && rng.End.Byte <= it.block.CloseBraceRange.Start.Byte {
+			child := blockKey(it.block)
+			if path != "" {
+				child = path + "." + child
+			}
+			return anchorFor(src, it.block.Body, child, rng)
+		}
+		if it.attr && rng.Start.Byte >= it.rng.Start.Byte && rng.Start.Byte < it.rng.End.Byte {
+			return CommentAnchor{Block: path, Position: PositionInside, Target: it.name}
+		}
+	}
+	if len(items) > 0 && isHeader(src, rng, items[0].rng) {
+		return CommentAnchor{Block: path, Position: PositionHeader}
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		it := items[i]
+		if it.rng.End.Byte <= rng.Start.Byte {
+			if it.rng.End.Line == rng.Start.Line {
+				return CommentAnchor{Block: path, Position: PositionAfter, Target: it.name}
+			}
+			break
+		}
+	}
+	for _, it := range items {
+		if it.rng.Start.Byte >= rng.End.Byte {
+			return CommentAnchor{Block: path, Position: PositionBefore, Target: it.name}
+		}
+	}
+	return CommentAnchor{Block: path, Position: PositionEnd}
+}
+
+func isHeader(src []byte, comment, first hcl.Range) bool {
+	if comment.End.Byte > first.Start.Byte {
+		return false
+	}
+	end := comment.End.Byte
+	for end > comment.Start.Byte && (src[end-1] == '\n' || src[end-1] == '\r') {
+		end--
+	}
+	return strings.Count(string(src[end:first.Start.Byte]), "\n") >= 2
+}
@@ -0,0 +1,136 @@
+// internal/verify/comments_test.go
+package verify
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprints(t *testing.T) {
+	src := `# header
+variable "a" {
+  # networking
+
+  type = string # trailing
+  # about description
+  description = "d"
+  default = {
+    # inside
+    k = 1
+  }
+  validation {
+    condition = true
+    # closing
+  }
+}
+`
+	fps, err := Fingerprints([]byte(src))
+	require.NoError(t, err)
+	got := make(map[string]CommentAnchor, len(fps))
+	for _, fp := range fps {
+		got[fp.Text] = fp.CommentAnchor
+	}
+	require.Equal(t, map[string]CommentAnchor{
+		"# header":            {Position: PositionBefore, Target: `block variable "a"`},
+		"# networking":        {Block: `variable "a"`, Position: PositionHeader},
+		"# trailing":          {Block: `variable "a"`, Position: PositionAfter, Target: `attribute "type"`},
+		"# about description": {Block: `variable "a"`, Position: PositionBefore, Target: `attribute "description"`},
+		"# inside":            {Block: `variable "a"`, Position: PositionInside, Target: `attribute "default"`},
+		"# closing":           {Block: `variable "a".validation`, Position: PositionEnd},
+	}, got)
+}
+
+func TestAuditComments(t *testing.T) {
+	original := `variable "a" {
+  # networking
+  type        = string # trailing
+  description = "d"
+}
+`
+	cases := []struct {
+		name     string
+		aligned  string
+		findings []string
+	}{
+		{name: "unchanged", aligned: original},
+		{name: "moved with attribute", aligned: `variable "a" {
+  description = "d"
+  # networking
+  type = string # trailing
+}
+`},
+		{name: "lead changed owner", aligned: `variable "a" {
+  # networking
+  description = "d"
+  type        = string # trailing
+}
+`, findings: []string{`comment "# networking" (line 2) moved from before attribute "type" in block variable "a" to before attribute "description" in block variable "a"`}},
+		{name: "trailing moved", aligned: `variable "a" {
+  # networking
+  type        = string
+  description = "d" # trailing
+}
+`, findings: []string{`comment "# trailing" (line 3) moved from after attribute "type" in block variable "a" to after attribute "description" in block variable "a"`}},
+		{name: "dropped", aligned: `variable "a" {
+  # networking
+  type        = string
+  description = "d"
+}
+`, findings: []string{`comment "# trailing" (line 3) dropped, was after attribute "type" in block variable "a"`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings, err := AuditComments([]byte(original), []byte(tc.aligned))
+			require.NoError(t, err)
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			require.Equal(t, tc.findings, got)
+		})
+	}
+}
+
+func TestAuditCommentsHeader(t *testing.T) {
+	original := `# file header
+
+variable "z" {}
+
+# about a
+variable "a" {}
+`
+	findings, err := AuditComments([]byte(original), []byte(`# file header
+
+# about a
+variable "a" {}
+
+variable "z" {}
+`))
+	require.NoError(t, err)
+	require.Empty(t, findings)
+
+	findings, err = AuditComments([]byte(original), []byte(`# file header
+
+variable "z" {}
+
+variable "a" {}
+# about a
+`))
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, `comment "# about a" (line 5) moved from before block variable "a" in top level to at end of top level`, findings[0].String())
+}
+
+func TestCommentError(t *testing.T) {
+	err := &CommentError{Findings: []CommentFinding{{Comment: "# a", Range: hcl.Range{Start: hcl.Pos{Line: 1}}, Dropped: true, From: CommentAnchor{Position: PositionEnd}}}}
+	require.EqualError(t, err, `comment audit failed: comment "# a" (line 1) dropped, was at end of top level`)
+}
+
+func TestAuditCommentsInvalid(t *testing.T) {
+	_, err := AuditComments([]byte("a = {"), []byte("a = 1\n"))
+	require.Error(t, err)
+	_, err = AuditComments([]byte("a = 1\n"), []byte("a = {"))
+	require.Error(t, err)
+}
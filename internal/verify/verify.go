@@ -0,0 +1,315 @@
+// internal/verify/verify.go
+package verify
+
+import (
+	"bytes"
+	"fmt"
+	pathpkg "path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "aligned output is not equivalent to input: " + strings.Join(e.Problems, "; ")
+}
+
+type Options struct {
+	ObjectKeys     bool
+	ObjectKeyAttrs []string
+	Lists          bool
+	ListAttrs      []string
+}
+
+type summary struct {
+	blocks   map[string]int
+	attrs    map[string]int
+	comments map[string]int
+}
+
+func Equivalent(original, aligned []byte, opts Options) error {
+	want, err := summarize(original, opts)
+	if err != nil {
+		return fmt.Errorf("parse original: %w", err)
+	}
+	got, err := summarize(aligned, opts)
+	if err != nil {
+		return &Error{Problems: []string{fmt.Sprintf("output does not parse: %v", err)}}
+	}
+	return want.compare(got)
+}
+
+func EquivalentFiles(originals, outputs [][]byte, opts Options) error {
+	want, err := summarizeFiles(originals, opts)
+	if err != nil {
+		return fmt.Errorf("parse original: %w", err)
+	}
+	got, err := summarizeFiles(outputs, opts)
+	if err != nil {
+		return &Error{Problems: []string{fmt.Sprintf("output does not parse: %v", err)}}
+	}
+	return want.compare(got)
+}
+
+func summarizeFiles(files [][]byte, opts Options) (*summary, error) {
+	total := &summary{blocks: map[string]int{}, attrs: map[string]int{}, comments: map[string]int{}}
+	for _, src := range files {
+		s, err := summarize(src, opts)
+		if err != nil {
+			return nil, err
+		}
+		for k, n := range s.blocks {
+			total.blocks[k] += n
+		}
+		for k, n := range s.attrs {
+			total.attrs[k] += n
+		}
+		for k, n := range s.comments {
+			total.comments[k] += n
+		}
+	}
+	for _, counts := range []map[string]int{total.blocks, total.attrs} {
+		for k := range counts {
+			if k == "terraform" || strings.HasPrefix(k, "terraform.") {
+				counts[k] = 1
+			}
+		}
+	}
+	return total, nil
+}
+
+func (s *summary) compare(got *summary) error {
+	var problems []string
+	problems = append(problems, compare("block", s.blocks, got.blocks)...)
+	problems = append(problems, compare("attribute", s.attrs, got.attrs)...)
+	problems = append(problems, compare("comment", s.comments, got.comments)...)
+	if len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
+
+func summarize(src []byte, opts Options) (*summary, error) {
+	file, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	s := &summary{blocks: map[string]int{}, attrs: map[string]int{}, comments: map[string]int{}}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", file.Body)
+	}
+	s.walk(src, body, "", false, opts)
+	tokens, diags := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenComment {
+			s.comments[strings.TrimRight(string(tok.Bytes), " \t\r\n")]++
+		}
+	}
+	return s, nil
+}
+
+var legacyTypes = map[string]string{
+	`"string"`: "string",
+	`"list"`:   "list(string)",
+	`"map"`:    "map(string)",
+	"list":     "list(any)",
+	"map":      "map(any)",
+}
+
+func (s *summary) walk(src []byte, body *hclsyntax.Body, path string, variable bool, opts Options) {
+	for name, attr := range body.Attributes {
+		c := canon{src: src, keys: opts.ObjectKeys && matches(opts.ObjectKeyAttrs, name)}
+		c.nodes = c.collect(attr.Expr)
+		expr := unwrap(attr.Expr)
+		value := c.expr(expr)
+		if tuple, ok := expr.(*hclsyntax.TupleConsExpr); ok && opts.Lists && matches(opts.ListAttrs, name) {
+			value = c.set(tuple)
+		}
+		if modern, ok := legacyTypes[value]; ok && variable && name == "type" {
+			value = modern
+		}
+		s.attrs[path+name+" = "+value]++
+	}
+	for _, block := range body.Blocks {
+		key := path + blockKey(block)
+		s.blocks[key]++
+		s.walk(src, block.Body, key+".", path == "" && block.Type == "variable", opts)
+	}
+}
+
+func unwrap(expr hclsyntax.Expression) hclsyntax.Expression {
+	for {
+		switch e := expr.(type) {
+		case *hclsyntax.TemplateWrapExpr:
+			expr = e.Wrapped
+		case *hclsyntax.ParenthesesExpr:
+			expr = e.Expression
+		default:
+			return expr
+		}
+	}
+}
+
+func matches(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := pathpkg.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type canon struct {
+	src   []byte
+	keys  bool
+	nodes map[int]hclsyntax.Expression
+}
+
+func (c canon) collect(expr hclsyntax.Expression) map[int]hclsyntax.Expression {
+	nodes := map[int]hclsyntax.Expression{}
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch e := node.(type) {
+		case *hclsyntax.TemplateExpr:
+			nodes[e.SrcRange.Start.Byte] = e
+		case *hclsyntax.ObjectConsExpr:
+			if c.keys {
+				nodes[e.SrcRange.Start.Byte] = e
+			}
+		}
+		return nil
+	})
+	return nodes
+}
+
+func (c canon) expr(expr hclsyntax.Expression) string {
+	return c.source(expr.Range())
+}
+
+func (c canon) set(tuple *hclsyntax.TupleConsExpr) string {
+	seen := map[string]bool{}
+	var elems []string
+	for _, e := range tuple.Exprs {
+		text := c.expr(e)
+		if !seen[text] {
+			seen[text] = true
+			elems = append(elems, text)
+		}
+	}
+	sort.Strings(elems)
+	return "[" + strings.Join(elems, ",") + "]"
+}
+
+func (c canon) object(obj *hclsyntax.ObjectConsExpr) (string, bool) {
+	keys := map[string]bool{}
+	items := make([]string, len(obj.Items))
+	for i, item := range obj.Items {
+		key, diags := item.KeyExpr.Value(nil)
+		if diags.HasErrors() || !key.IsKnown() || key.IsNull() || key.Type() != cty.String || keys[key.AsString()] {
+			return "", false
+		}
+		keys[key.AsString()] = true
+		items[i] = c.expr(item.KeyExpr) + "=" + c.expr(item.ValueExpr)
+	}
+	sort.Strings(items)
+	return "{" + strings.Join(items, ",") + "}", true
+}
+
+func (c canon) template(tmpl *hclsyntax.TemplateExpr) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, part := range tmpl.Parts {
+		if lit, ok := part.(*hclsyntax.LiteralValueExpr); ok && lit.Val.Type() == cty.String && lit.Val.IsKnown() && !lit.Val.IsNull() {
+			quoted := strconv.Quote(lit.Val.AsString())
+			b.WriteString(strings.ReplaceAll(quoted[1:len(quoted)-1], "${", "$${"))
+			continue
+		}
+		b.WriteString("${" + c.expr(part) + "}")
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func (c canon) node(tok hclsyntax.Token) (string, hcl.Range, bool) {
+	switch e := c.nodes[tok.Range.Start.Byte].(type) {
+	case *hclsyntax.ObjectConsExpr:
+		if tok.Type == hclsyntax.TokenOBrace {
+			text, ok := c.object(e)
+			return text, e.SrcRange, ok
+		}
+	case *hclsyntax.TemplateExpr:
+		if tok.Type == hclsyntax.TokenOQuote || tok.Type == hclsyntax.TokenOHeredoc {
+			return c.template(e), e.SrcRange, true
+		}
+	}
+	return "", hcl.Range{}, false
+}
+
+func (c canon) source(rng hcl.Range) string {
+	raw := c.src[rng.Start.Byte:rng.End.Byte]
+	tokens, diags := hclsyntax.LexExpression(raw, "", rng.Start)
+	if diags.HasErrors() {
+		return string(bytes.TrimSpace(raw))
+	}
+	var b strings.Builder
+	prevWord := false
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok.Type {
+		case hclsyntax.TokenNewline, hclsyntax.TokenComment, hclsyntax.TokenEOF:
+			continue
+		}
+		if text, span, ok := c.node(tok); ok {
+			b.WriteString(text)
+			for i+1 < len(tokens) && tokens[i+1].Range.Start.Byte < span.End.Byte {
+				i++
+			}
+			prevWord = false
+			continue
+		}
+		word := tok.Type == hclsyntax.TokenIdent || tok.Type == hclsyntax.TokenNumberLit
+		if word && prevWord {
+			b.WriteByte(' ')
+		}
+		b.Write(tok.Bytes)
+		prevWord = word
+	}
+	return b.String()
+}
+
+func compare(kind string, want, got map[string]int) []string {
+	keys := make([]string, 0, len(want)+len(got))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var problems []string
+	for _, k := range keys {
+		switch w, g := want[k], got[k]; {
+		case g < w:
+			problems = append(problems, fmt.Sprintf("%s %q dropped", kind, k))
+		case g > w:
+			problems = append(problems, fmt.Sprintf("%s %q duplicated or added", kind, k))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,199 @@
+// internal/verify/verify_test.go
+package verify
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEquivalent(t *testing.T) {
+	original := `# header
+variable "a" {
+  type        = string # trailing
+  description = "d"
+  validation {
+    condition     = length(var.a) > 0
+    error_message = "empty"
+  }
+}
+`
+	cases := []struct {
+		name    string
+		aligned string
+		problem string
+	}{
+		{name: "reordered", aligned: `# header
+variable "a" {
+  description = "d"
+  type        = string # trailing
+
+  validation {
+    condition = length(var.a) > 0
+    error_message = "empty"
+  }
+}
+`},
+		{name: "dropped attribute", aligned: `# header
+variable "a" {
+  type = string # trailing
+  validation {
+    condition     = length(var.a) > 0
+    error_message = "empty"
+  }
+}
+`, problem: `attribute "variable \"a\".description = \"d\"" dropped`},
+		{name: "duplicated attribute", aligned: `# header
+variable "a" {
+  type        = string # trailing
+  description = "d"
+  validation {
+    condition     = length(var.a) > 0
+    error_message = "empty"
+  }
+}
+variable "a" {
+  description = "d"
+}
+`, problem: `block "variable \"a\"" duplicated or added`},
+		{name: "changed expression", aligned: `# header
+variable "a" {
+  type        = number # trailing
+  description = "d"
+  validation {
+    condition     = length(var.a) > 0
+    error_message = "empty"
+  }
+}
+`, problem: `attribute "variable \"a\".type = string" dropped`},
+		{name: "dropped comment", aligned: `variable "a" {
+  type        = string # trailing
+  description = "d"
+  validation {
+    condition     = length(var.a) > 0
+    error_message = "empty"
+  }
+}
+`, problem: `comment "# header" dropped`},
+		{name: "dropped block", aligned: `# header
+variable "a" {
+  type        = string # trailing
+  description = "d"
+}
+`, problem: `block "variable \"a\".validation" dropped`},
+		{name: "unparseable", aligned: "variable \"a\" {\n", problem: "output does not parse"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Equivalent([]byte(original), []byte(tc.aligned), Options{})
+			if tc.problem == "" {
+				require.NoError(t, err)
+				return
+			}
+			var verr *Error
+			require.ErrorAs(t, err, &verr)
+			require.Contains(t, err.Error(), tc.problem)
+		})
+	}
+}
+
+func TestEquivalentInvalidOriginal(t *testing.T) {
+	err := Equivalent([]byte("a = {"), []byte("a = 1\n"), Options{})
+	require.Error(t, err)
+	var verr *Error
+	require.False(t, errors.As(err, &verr))
+}
+
+func TestEquivalentFiles(t *testing.T) {
+	originals := [][]byte{
+		[]byte("# Pin.\nterraform {\n  required_version = \">= 1.5\"\n}\n\nvariable \"a\" {}\n"),
+		[]byte("terraform {\n  required_version = \">= 1.5\"\n}\n"),
+	}
+	merged := [][]byte{
+		[]byte("# Pin.\nterraform {\n  required_version = \">= 1.5\"\n}\n"),
+		[]byte("variable \"a\" {}\n"),
+	}
+	require.NoError(t, EquivalentFiles(originals, merged, Options{}))
+	require.ErrorContains(t, EquivalentFiles(originals, merged[:1], Options{}), `block "variable \"a\"" dropped`)
+	require.ErrorContains(t, EquivalentFiles(originals, [][]byte{merged[1], []byte("terraform {\n  required_version = \">= 1.5\"\n}\n")}, Options{}), `comment "# Pin." dropped`)
+	require.ErrorContains(t, EquivalentFiles(originals, [][]byte{merged[1], []byte("variable \"a\" {}\n")}, Options{}), "duplicated or added")
+}
+
+func TestEquivalentObjectKeyOrder(t *testing.T) {
+	original := "tags = {\n  b = 1 # keep\n  a = { d = 2, c = 3 }\n}\n"
+	sorted := "tags = {\n  a = { c = 3, d = 2 }\n  b = 1 # keep\n}\n"
+	keys := Options{ObjectKeys: true}
+	require.NoError(t, Equivalent([]byte(original), []byte(sorted), keys))
+	require.Error(t, Equivalent([]byte(original), []byte(sorted), Options{}))
+	require.Error(t, Equivalent([]byte(original), []byte(sorted), Options{ObjectKeys: true, ObjectKeyAttrs: []string{"labels"}}))
+	require.NoError(t, Equivalent([]byte(original), []byte(sorted), Options{ObjectKeys: true, ObjectKeyAttrs: []string{"t*"}}))
+	require.Error(t, Equivalent([]byte(original), []byte("tags = {\n  a = { c = 2, d = 3 }\n  b = 1 # keep\n}\n"), keys))
+	require.Error(t, Equivalent([]byte("tags = { a = 1, (k) = 2 }\n"), []byte("tags = { (k) = 2, a = 1 }\n"), keys))
+	require.Error(t, Equivalent([]byte("tags = { a = 1, b = 2, a = 3 }\n"), []byte("tags = { a = 3, a = 1, b = 2 }\n"), keys))
+}
+
+func TestEquivalentLists(t *testing.T) {
+	original := "r {\n  depends_on = [b.x, a, b.x]\n  zones      = [\"b\", \"a\"]\n}\n"
+	lists := Options{Lists: true, ListAttrs: []string{"depends_on"}}
+	cases := []struct {
+		name    string
+		aligned string
+		opts    Options
+		problem string
+	}{
+		{name: "sorted and de-duplicated", aligned: "r {\n  depends_on = [a, b.x]\n  zones      = [\"b\", \"a\"]\n}\n", opts: lists},
+		{name: "disabled", aligned: "r {\n  depends_on = [a, b.x]\n  zones      = [\"b\", \"a\"]\n}\n", problem: "dropped"},
+		{name: "other attribute", aligned: "r {\n  depends_on = [b.x, a, b.x]\n  zones      = [\"a\", \"b\"]\n}\n", opts: lists, problem: `zones = [\"b\",\"a\"]`},
+		{name: "dropped element", aligned: "r {\n  depends_on = [a]\n  zones      = [\"b\", \"a\"]\n}\n", opts: lists, problem: "depends_on = [a,b.x]"},
+		{name: "changed element", aligned: "r {\n  depends_on = [a, b.y]\n  zones      = [\"b\", \"a\"]\n}\n", opts: lists, problem: "dropped"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Equivalent([]byte(original), []byte(tc.aligned), tc.opts)
+			if tc.problem == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.problem)
+		})
+	}
+}
+
+func TestEquivalentFormatterRewrites(t *testing.T) {
+	pairs := [][2]string{
+		{"../fmt/testdata/parity/interpolation/in.tf", "../fmt/testdata/parity/interpolation/out.tf"},
+		{"../fmt/testdata/parity/heredoc/in.tf", "../fmt/testdata/parity/heredoc/out.tf"},
+		{"../fmt/testdata/parity/types/in.tf", "../fmt/testdata/parity/types/out.tf"},
+		{"../fmt/testdata/parity/labels/in.tf", "../fmt/testdata/parity/labels/out.tf"},
+		{"../fmt/testdata/parity/ellipsis/in.tf", "../fmt/testdata/parity/ellipsis/out.tf"},
+		{"../../tests/cases/heredocs/in.tf", "../../tests/cases/heredocs/normalized.tf"},
+	}
+	for _, pair := range pairs {
+		t.Run(pair[0], func(t *testing.T) {
+			original, err := os.ReadFile(pair[0])
+			require.NoError(t, err)
+			formatted, err := os.ReadFile(pair[1])
+			require.NoError(t, err)
+			require.NoError(t, Equivalent(original, formatted, Options{}))
+		})
+	}
+
+	cases := []struct {
+		name      string
+		original  string
+		formatted string
+	}{
+		{name: "heredoc text", original: "a = <<EOT\nx\nEOT\n", formatted: "a = <<-EOT\n  y\n  EOT\n"},
+		{name: "interpolation", original: "a = \"${var.x}\"\n", formatted: "a = var.y\n"},
+		{name: "nested interpolation", original: "a = f(\"${var.x}\")\n", formatted: "a = f(var.x)\n"},
+		{name: "legacy type", original: "variable \"v\" {\n  type = \"list\"\n}\n", formatted: "variable \"v\" {\n  type = list(any)\n}\n"},
+		{name: "type outside variable", original: "locals {\n  type = \"string\"\n}\n", formatted: "locals {\n  type = string\n}\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorContains(t, Equivalent([]byte(tc.original), []byte(tc.formatted), Options{}), "dropped")
+		})
+	}
+}
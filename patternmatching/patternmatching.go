@@ -78,10 +78,8 @@ func (m *Matcher) Matches(path string) bool {
 		return false
 	}
 
-	for _, ex := range m.exclude {
-		if ok, _ := doublestar.PathMatch(ex, relPath); ok {
-			return false
-		}
+	if m.excluded(relPath) {
+		return false
 	}
 	info, err := os.Stat(path)
 	if err != nil {
@@ -89,7 +87,13 @@ func (m *Matcher) Matches(path string) bool {
 			return false
 		}
 	}
-	isDir := err == nil && info.IsDir()
+	return m.MatchesRel(relPath, err == nil && info.IsDir())
+}
+
+func (m *Matcher) MatchesRel(relPath string, isDir bool) bool {
+	if m.excluded(relPath) {
+		return false
+	}
 	if isDir {
 		return true
 	}
@@ -101,4 +105,13 @@ func (m *Matcher) Matches(path string) bool {
 	return false
 }
 
+func (m *Matcher) excluded(relPath string) bool {
+	for _, ex := range m.exclude {
+		if ok, _ := doublestar.PathMatch(ex, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func ValidatePatterns(patterns []string) error { return validatePatterns(patterns) }
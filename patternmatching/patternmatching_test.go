@@ -177,3 +177,17 @@ func TestMatcherMatchesNonExistentPath(t *testing.T) {
 	missing := filepath.Join(wd, "missing.tf")
 	assert.False(t, m.Matches(missing))
 }
+
+func TestMatcherMatchesRel(t *testing.T) {
+	m, err := patternmatching.NewMatcher([]string{"**/*.tf"}, []string{"**/vendor/**", "nested/excluded/**"}, "")
+	require.NoError(t, err)
+
+	assert.True(t, m.MatchesRel("main.tf", false))
+	assert.True(t, m.MatchesRel("nested/included", true))
+	assert.True(t, m.MatchesRel("nested/included/in.tf", false))
+	assert.False(t, m.MatchesRel("note.txt", false))
+	assert.False(t, m.MatchesRel("vendor", true))
+	assert.False(t, m.MatchesRel("vendor/v.tf", false))
+	assert.False(t, m.MatchesRel("nested/excluded", true))
+	assert.False(t, m.MatchesRel("nested/excluded/out.tf", false))
+}
@@ -0,0 +1,178 @@
+// strategy/strategy.go
+package strategy
+
+import (
+	"errors"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/oferchen/hclalign/internal/align"
+)
+
+type Strategy interface {
+	Name() string
+
+	Align(block *hclwrite.Block, opts *Options) error
+}
+
+type Options struct {
+	Path   string
+	Schema *Schema
+}
+
+type Schema struct {
+	Required map[string]struct{}
+	Optional map[string]struct{}
+	Computed map[string]struct{}
+	Meta     map[string]struct{}
+
+	RequiredOrder []string
+	OptionalOrder []string
+	ComputedOrder []string
+
+	Blocks      map[string]*Schema
+	BlocksOrder []string
+}
+
+type AddressFilter struct {
+	filter *align.AddressFilter
+}
+
+type ApplyOptions struct {
+	Path   string
+	Types  []string
+	Filter *AddressFilter
+}
+
+func Register(s Strategy) error {
+	if s == nil {
+		return errors.New("strategy cannot be nil")
+	}
+	if s.Name() == "" {
+		return errors.New("strategy name cannot be empty")
+	}
+	if b, ok := s.(builtin); ok {
+		align.Register(b.strategy)
+		return nil
+	}
+	align.Register(external{strategy: s})
+	return nil
+}
+
+func Lookup(name string) (Strategy, bool) {
+	s, ok := align.Lookup(name)
+	if !ok {
+		return nil, false
+	}
+	if e, ok := s.(external); ok {
+		return e.strategy, true
+	}
+	return builtin{strategy: s}, true
+}
+
+func Registered() []string { return align.Registered() }
+
+func NewAddressFilter(only, skip []string) (*AddressFilter, error) {
+	filter, err := align.NewAddressFilter(only, skip)
+	if err != nil {
+		return nil, err
+	}
+	return &AddressFilter{filter: filter}, nil
+}
+
+func (f *AddressFilter) Counts() (matched, unmatched int64) {
+	if f == nil {
+		return 0, 0
+	}
+	return f.filter.Counts()
+}
+
+func Apply(file *hclwrite.File, opts ApplyOptions) error {
+	internal := &align.Options{Path: opts.Path}
+	if opts.Filter != nil {
+		internal.Filter = opts.Filter.filter
+	}
+	if opts.Types != nil {
+		internal.Types = make(map[string]struct{}, len(opts.Types))
+		for _, t := range opts.Types {
+			internal.Types[t] = struct{}{}
+		}
+	}
+	return align.Apply(file, internal)
+}
+
+type external struct {
+	strategy Strategy
+}
+
+func (e external) Name() string { return e.strategy.Name() }
+
+func (e external) Align(block *hclwrite.Block, opts *align.Options) error {
+	public := &Options{}
+	if opts != nil {
+		public.Path = opts.Path
+		public.Schema = exportSchema(opts.Schema)
+	}
+	return e.strategy.Align(block, public)
+}
+
+type builtin struct {
+	strategy align.Strategy
+}
+
+func (b builtin) Name() string { return b.strategy.Name() }
+
+func (b builtin) Align(block *hclwrite.Block, opts *Options) error {
+	internal := &align.Options{}
+	if opts != nil {
+		internal.Path = opts.Path
+		internal.Schema = importSchema(opts.Schema)
+	}
+	return b.strategy.Align(block, internal)
+}
+
+func exportSchema(s *align.Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	out := &Schema{
+		Required:      s.Required,
+		Optional:      s.Optional,
+		Computed:      s.Computed,
+		Meta:          s.Meta,
+		RequiredOrder: s.RequiredOrder,
+		OptionalOrder: s.OptionalOrder,
+		ComputedOrder: s.ComputedOrder,
+		BlocksOrder:   s.BlocksOrder,
+	}
+	if s.Blocks != nil {
+		out.Blocks = make(map[string]*Schema, len(s.Blocks))
+		for name, b := range s.Blocks {
+			out.Blocks[name] = exportSchema(b)
+		}
+	}
+	return out
+}
+
+func importSchema(s *Schema) *align.Schema {
+	if s == nil {
+		return nil
+	}
+	out := &align.Schema{
+		Required:      s.Required,
+		Optional:      s.Optional,
+		Computed:      s.Computed,
+		Meta:          s.Meta,
+		RequiredOrder: s.RequiredOrder,
+		OptionalOrder: s.OptionalOrder,
+		ComputedOrder: s.ComputedOrder,
+		BlocksOrder:   s.BlocksOrder,
+	}
+	if s.Blocks != nil {
+		out.Blocks = make(map[string]*align.Schema, len(s.Blocks))
+		for name, b := range s.Blocks {
+			out.Blocks[name] = importSchema(b)
+		}
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+// strategy/strategy_test.go
+package strategy_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oferchen/hclalign/config"
+	"github.com/oferchen/hclalign/internal/align"
+	"github.com/oferchen/hclalign/strategy"
+)
+
+type sortStrategy struct {
+	name  string
+	paths *[]string
+}
+
+func (s sortStrategy) Name() string { return s.name }
+
+func (s sortStrategy) Align(block *hclwrite.Block, opts *strategy.Options) error {
+	*s.paths = append(*s.paths, opts.Path)
+	body := block.Body()
+	names := make([]string, 0, len(body.Attributes()))
+	for name := range body.Attributes() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	exprs := make(map[string]hclwrite.Tokens, len(names))
+	for _, name := range names {
+		exprs[name] = body.GetAttribute(name).Expr().BuildTokens(nil)
+		body.RemoveAttribute(name)
+	}
+	for _, name := range names {
+		body.SetAttributeRaw(name, exprs[name])
+	}
+	return nil
+}
+
+func TestRegisterCustomStrategy(t *testing.T) {
+	var paths []string
+	require.NoError(t, strategy.Register(sortStrategy{name: "custom_widget", paths: &paths}))
+	require.Contains(t, strategy.Registered(), "custom_widget")
+	_, ok := strategy.Lookup("custom_widget")
+	require.True(t, ok)
+
+	src := []byte("custom_widget \"w\" {\n  b = 2\n  a = 1\n}\n")
+	file, diags := hclwrite.ParseConfig(src, "w.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, align.Apply(file, &align.Options{Path: "w.tf"}))
+	require.Equal(t, "custom_widget \"w\" {\n  a = 1\n  b = 2\n}\n", string(file.Bytes()))
+	require.Equal(t, []string{"w.tf"}, paths)
+
+	cfg := config.Config{Concurrency: 1, Include: config.DefaultInclude, Exclude: config.DefaultExclude, Types: []string{"custom_widget"}}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestRegisterOverridesBuiltin(t *testing.T) {
+	orig, ok := strategy.Lookup("locals")
+	require.True(t, ok)
+	t.Cleanup(func() { require.NoError(t, strategy.Register(orig)) })
+
+	var paths []string
+	require.NoError(t, strategy.Register(sortStrategy{name: "locals", paths: &paths}))
+
+	src := []byte("locals {\n  b = 2\n  a = 1\n}\n")
+	file, diags := hclwrite.ParseConfig(src, "l.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, align.Apply(file, &align.Options{Path: "l.tf"}))
+	require.Equal(t, "locals {\n  a = 1\n  b = 2\n}\n", string(file.Bytes()))
+}
+
+func TestAddressFilter(t *testing.T) {
+	var paths []string
+	require.NoError(t, strategy.Register(sortStrategy{name: "filtered_widget", paths: &paths}))
+
+	filter, err := strategy.NewAddressFilter([]string{"filtered_widget.keep"}, nil)
+	require.NoError(t, err)
+	src := []byte("filtered_widget \"keep\" {\n  b = 2\n  a = 1\n}\n\nfiltered_widget \"other\" {\n  b = 2\n  a = 1\n}\n")
+	file, diags := hclwrite.ParseConfig(src, "w.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, strategy.Apply(file, strategy.ApplyOptions{Path: "w.tf", Filter: filter}))
+	require.Equal(t, "filtered_widget \"keep\" {\n  a = 1\n  b = 2\n}\n\nfiltered_widget \"other\" {\n  b = 2\n  a = 1\n}\n", string(file.Bytes()))
+	matched, unmatched := filter.Counts()
+	require.Equal(t, int64(1), matched)
+	require.Equal(t, int64(1), unmatched)
+
+	_, err = strategy.NewAddressFilter([]string{""}, nil)
+	require.Error(t, err)
+}
+
+type schemaStrategy struct {
+	got **strategy.Schema
+}
+
+func (schemaStrategy) Name() string { return "schema_widget" }
+
+func (s schemaStrategy) Align(_ *hclwrite.Block, opts *strategy.Options) error {
+	*s.got = opts.Schema
+	return nil
+}
+
+func TestStrategyReceivesSchema(t *testing.T) {
+	var got *strategy.Schema
+	require.NoError(t, strategy.Register(schemaStrategy{got: &got}))
+
+	nested := &align.Schema{RequiredOrder: []string{"inner"}}
+	schemas := map[string]*align.Schema{"w": {
+		RequiredOrder: []string{"a", "b"},
+		Blocks:        map[string]*align.Schema{"nested": nested},
+	}}
+	file, diags := hclwrite.ParseConfig([]byte("schema_widget \"w\" {\n  a = 1\n}\n"), "w.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, align.Apply(file, &align.Options{Path: "w.tf", Schemas: schemas}))
+	require.NotNil(t, got)
+	require.Equal(t, []string{"a", "b"}, got.RequiredOrder)
+	require.Equal(t, []string{"inner"}, got.Blocks["nested"].RequiredOrder)
+}
+
+func TestLookupBuiltinAligns(t *testing.T) {
+	builtin, ok := strategy.Lookup("variable")
+	require.True(t, ok)
+	require.Equal(t, "variable", builtin.Name())
+
+	file, diags := hclwrite.ParseConfig([]byte("variable \"v\" {\n  type = string\n  description = \"d\"\n}\n"), "v.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	require.NoError(t, builtin.Align(file.Body().Blocks()[0], &strategy.Options{Path: "v.tf"}))
+	require.Equal(t, "variable \"v\" {\n  description = \"d\"\n  type        = string\n}\n", string(file.Bytes()))
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	require.Error(t, strategy.Register(nil))
+	require.Error(t, strategy.Register(sortStrategy{}))
+}
+
+func TestRegisteredSorted(t *testing.T) {
+	names := strategy.Registered()
+	require.True(t, sort.StringsAreSorted(names))
+	require.Contains(t, names, "variable")
+	require.Contains(t, names, "resource")
+}
@@ -8,4 +8,29 @@ EOT
 line1
 line2
 EOF
+}
+
+variable "single" {
+  default = <<EOF
+single line
+EOF
+  description = <<-EOT
+    Used as "${path.module}".
+    EOT
+}
+
+locals {
+  greeting = <<EOT
+Hello, ${var.name}!
+EOT
+  script = <<-SCRIPT
+        #!/bin/sh
+          echo "$${HOME}"
+
+        exit 0
+    SCRIPT
+  spaced = <<END
+  keep
+  this
+END
 }
\ No newline at end of file
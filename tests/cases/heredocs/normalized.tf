@@ -0,0 +1,34 @@
+variable "heredoc" {
+  description = <<-EOT
+    indented
+    text
+  EOT
+  type        = string
+  default     = <<-EOT
+    line1
+    line2
+  EOT
+}
+
+variable "single" {
+  description = <<-EOT
+    Used as "${path.module}".
+  EOT
+  default     = "single line\n"
+}
+
+locals {
+  greeting = <<-EOT
+    Hello, ${var.name}!
+  EOT
+  script   = <<-EOT
+    #!/bin/sh
+      echo "$${HOME}"
+
+    exit 0
+  EOT
+  spaced   = <<EOT
+  keep
+  this
+EOT
+}
\ No newline at end of file
@@ -8,4 +8,29 @@ EOT
 line1
 line2
 EOF
+}
+
+variable "single" {
+  description = <<-EOT
+    Used as "${path.module}".
+    EOT
+  default     = <<EOF
+single line
+EOF
+}
+
+locals {
+  greeting = <<EOT
+Hello, ${var.name}!
+EOT
+  script   = <<-SCRIPT
+        #!/bin/sh
+          echo "$${HOME}"
+
+        exit 0
+    SCRIPT
+  spaced   = <<END
+  keep
+  this
+END
 }
\ No newline at end of file
@@ -3,7 +3,7 @@ output "example" {
   // doc2
   /* doc3 */
   description = "desc" // desc trailing
-  value = 1
+  value       = 1
 
 
   /* before sensitive */
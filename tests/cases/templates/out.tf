@@ -1,6 +1,6 @@
 variable "interpolation" {
   type    = string
-  default = "${var.foo}"
+  default = var.foo
 }
 
 variable "directive" {
@@ -3,6 +3,7 @@ package cli_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,6 +13,18 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+const commentMovingTerraform = `#!/bin/sh
+move() { awk '/^  # networking$/ { held = $0; next } { print } held != "" { print held; held = "" }'; }
+for a; do last=$a; done
+if [ "$last" = "-" ]; then
+  move
+  exit 0
+fi
+for f in "$last"/*.tf; do
+  move < "$f" > "$f.tmp" && mv "$f.tmp" "$f"
+done
+`
+
 func buildBinary(t *testing.T) string {
 	t.Helper()
 	dir := t.TempDir()
@@ -23,6 +36,21 @@ func buildBinary(t *testing.T) string {
 	return bin
 }
 
+func withoutFallbackWarning(stderr string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(stderr, "\n") {
+		if !strings.HasSuffix(strings.TrimSuffix(line, "\n"), "binary not found; using Go formatter") {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+func requireQuiet(t *testing.T, stderr string) {
+	t.Helper()
+	require.Empty(t, withoutFallbackWarning(stderr))
+}
+
 func TestCLI(t *testing.T) {
 	bin := buildBinary(t)
 
@@ -41,7 +69,7 @@ func TestCLI(t *testing.T) {
 		err := cmd.Run()
 		require.NoError(t, err)
 		require.Empty(t, stdout.String())
-		require.Empty(t, stderr.String())
+		requireQuiet(t, stderr.String())
 
 		data, err := os.ReadFile(file)
 		require.NoError(t, err)
@@ -61,7 +89,7 @@ func TestCLI(t *testing.T) {
 		err := cmd.Run()
 		require.NoError(t, err)
 		require.Empty(t, stdout.String())
-		require.Empty(t, stderr.String())
+		requireQuiet(t, stderr.String())
 	})
 
 	t.Run("diff_failure", func(t *testing.T) {
@@ -102,7 +130,7 @@ func TestCLI(t *testing.T) {
 		err := cmd.Run()
 		require.NoError(t, err)
 		require.Empty(t, stdout.String())
-		require.Empty(t, stderr.String())
+		requireQuiet(t, stderr.String())
 
 		data, err := os.ReadFile(includedFile)
 		require.NoError(t, err)
@@ -135,7 +163,7 @@ func TestCLI(t *testing.T) {
 		err := cmd.Run()
 		require.NoError(t, err)
 		require.Empty(t, stdout.String())
-		require.Empty(t, stderr.String())
+		requireQuiet(t, stderr.String())
 
 		data, err := os.ReadFile(rootFile)
 		require.NoError(t, err)
@@ -150,6 +178,178 @@ func TestCLI(t *testing.T) {
 		require.Equal(t, unformatted, string(data))
 	})
 
+	t.Run("verify_idempotent", func(t *testing.T) {
+		unformatted := "variable \"a\" {\n  type = string\n  description = \"d\"\n}\n"
+		want := "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n"
+
+		dir := t.TempDir()
+		file := filepath.Join(dir, "test.tf")
+		require.NoError(t, os.WriteFile(file, []byte(unformatted), 0o644))
+
+		cmd := exec.Command(bin, file, "--verify-idempotent")
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		require.NoError(t, err)
+		require.Empty(t, stdout.String())
+		requireQuiet(t, stderr.String())
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, want, string(data))
+	})
+
+	t.Run("strict_comments", func(t *testing.T) {
+		src := "variable \"a\" {\n  # networking\n  type        = string\n  description = \"d\"\n}\n"
+
+		dir := t.TempDir()
+		file := filepath.Join(dir, "test.tf")
+		require.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+		terraform := filepath.Join(t.TempDir(), "terraform")
+		require.NoError(t, os.WriteFile(terraform, []byte(commentMovingTerraform), 0o755))
+		fmtArgs := []string{"--fmt-strategy", "binary", "--terraform-bin", terraform}
+
+		cmd := exec.Command(bin, append([]string{file, "--check"}, fmtArgs...)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		require.Equal(t, 1, exitErr.ExitCode())
+		require.Contains(t, stderr.String(), "warning: "+file+": comment \"# networking\" (line 3) moved")
+
+		cmd = exec.Command(bin, append([]string{file, "--strict-comments"}, fmtArgs...)...)
+		stderr.Reset()
+		cmd.Stderr = &stderr
+		err = cmd.Run()
+		require.ErrorAs(t, err, &exitErr)
+		require.Equal(t, 3, exitErr.ExitCode())
+		require.Contains(t, stderr.String(), "comment audit failed")
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, src, string(data))
+	})
+
+	t.Run("trace_and_profiles", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "test.tf")
+		require.NoError(t, os.WriteFile(file, []byte("variable \"a\" {\n  type = string\n  description = \"d\"\n}\n"), 0o644))
+		tracePath := filepath.Join(dir, "trace.json")
+		cpuPath := filepath.Join(dir, "cpu.pprof")
+		memPath := filepath.Join(dir, "mem.pprof")
+
+		cmd := exec.Command(bin, file, "--trace", tracePath, "--cpuprofile", cpuPath, "--memprofile", memPath)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+
+		data, err := os.ReadFile(tracePath)
+		require.NoError(t, err)
+		var doc struct {
+			TraceEvents []struct {
+				Name string `json:"name"`
+				Ph   string `json:"ph"`
+			} `json:"traceEvents"`
+		}
+		require.NoError(t, json.Unmarshal(data, &doc))
+		seen := map[string]bool{}
+		for _, ev := range doc.TraceEvents {
+			seen[ev.Name] = true
+		}
+		for _, phase := range []string{"scan", "read", "pre-fmt", "parse", "align", "align:variable", "post-fmt", "write"} {
+			require.True(t, seen[phase], phase)
+		}
+		for _, p := range []string{cpuPath, memPath} {
+			info, err := os.Stat(p)
+			require.NoError(t, err)
+			require.NotZero(t, info.Size())
+		}
+	})
+
+	t.Run("address_filters", func(t *testing.T) {
+		src := "module \"legacy_vpc\" {\n  version = \"1.0.0\"\n  source  = \"./vpc\"\n}\n\nmodule \"network\" {\n  version = \"1.0.0\"\n  source  = \"./net\"\n}\n"
+		want := "module \"legacy_vpc\" {\n  version = \"1.0.0\"\n  source  = \"./vpc\"\n}\n\nmodule \"network\" {\n  source  = \"./net\"\n  version = \"1.0.0\"\n}\n"
+
+		dir := t.TempDir()
+		file := filepath.Join(dir, "test.tf")
+		require.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+
+		cmd := exec.Command(bin, file, "--all", "--skip", "module.legacy_*")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+		require.Equal(t, "summary: 1 blocks matched address filters, 1 unmatched\n", withoutFallbackWarning(stderr.String()))
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, want, string(data))
+	})
+
+	t.Run("config_file", func(t *testing.T) {
+		src := "module \"legacy_vpc\" {\n  version = \"1.0.0\"\n  source  = \"./vpc\"\n}\n\nmodule \"network\" {\n  version = \"1.0.0\"\n  source  = \"./net\"\n}\n"
+		want := "module \"legacy_vpc\" {\n  version = \"1.0.0\"\n  source  = \"./vpc\"\n}\n\nmodule \"network\" {\n  source  = \"./net\"\n  version = \"1.0.0\"\n}\n"
+
+		dir := t.TempDir()
+		nested := filepath.Join(dir, "network")
+		require.NoError(t, os.Mkdir(nested, 0o755))
+		file := filepath.Join(nested, "test.tf")
+		require.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".hclalign.hcl"), []byte("all  = true\nskip = [\"module.legacy_*\"]\n"), 0o644))
+
+		cmd := exec.Command(bin, nested, "--check")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		require.Equal(t, 1, exitErr.ExitCode())
+		require.Contains(t, stderr.String(), "summary: 1 blocks matched address filters, 1 unmatched\n")
+
+		cmd = exec.Command(bin, nested)
+		stderr.Reset()
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, want, string(data))
+
+		cmd = exec.Command(bin, nested, "--skip", "module.network")
+		stderr.Reset()
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+		data, err = os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, strings.Replace(want, "  version = \"1.0.0\"\n  source  = \"./vpc\"\n", "  source  = \"./vpc\"\n  version = \"1.0.0\"\n", 1), string(data))
+	})
+
+	t.Run("baseline", func(t *testing.T) {
+		dir := t.TempDir()
+		legacy := filepath.Join(dir, "legacy.tf")
+		require.NoError(t, os.WriteFile(legacy, []byte("variable \"a\" {\n  type        = string\n  description = \"d\"\n}\n"), 0o644))
+		basePath := filepath.Join(dir, "baseline.json")
+
+		cmd := exec.Command(bin, "baseline", "generate", dir, "--baseline", basePath)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		cmd = exec.Command(bin, dir, "--check", "--baseline", basePath)
+		out, err = cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		fresh := filepath.Join(dir, "new.tf")
+		require.NoError(t, os.WriteFile(fresh, []byte("variable \"b\" {\n  type        = string\n  description = \"d\"\n}\n"), 0o644))
+		cmd = exec.Command(bin, dir, "--check", "--baseline", basePath)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		err = cmd.Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		require.Equal(t, 1, exitErr.ExitCode())
+		require.Contains(t, stderr.String(), "new violation not in baseline: new.tf: variable.b")
+	})
+
 	t.Run("stdin_stdout", func(t *testing.T) {
 		unformatted := "variable \"a\" {\n  type = string\n  description = \"d\"\n}\n"
 		want := "variable \"a\" {\n  description = \"d\"\n  type        = string\n}\n"
@@ -162,7 +362,7 @@ func TestCLI(t *testing.T) {
 		err := cmd.Run()
 		require.NoError(t, err)
 		require.Equal(t, want, stdout.String())
-		require.Empty(t, stderr.String())
+		requireQuiet(t, stderr.String())
 	})
 
 	t.Run("types_and_all", func(t *testing.T) {
@@ -198,7 +398,7 @@ func TestCLI(t *testing.T) {
 		err := cmd.Run()
 		require.NoError(t, err)
 		require.Empty(t, stdout.String())
-		require.Empty(t, stderr.String())
+		requireQuiet(t, stderr.String())
 
 		data, err := os.ReadFile(file)
 		require.NoError(t, err)
@@ -234,6 +434,7 @@ func TestCLI(t *testing.T) {
 		require.True(t, ok)
 		require.Equal(t, 3, exitErr.ExitCode())
 		require.Empty(t, stdout.String())
-		require.NotEmpty(t, stderr.String())
+		require.Contains(t, stderr.String(), file+":1:14:")
+		require.Equal(t, 1, strings.Count(stderr.String(), file))
 	})
 }